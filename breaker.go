@@ -0,0 +1,173 @@
+package valkey
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBreakerWindow      = 10 * time.Second
+	defaultBreakerOpenTimeout = 5 * time.Second
+	defaultBreakerMinRequests = 20
+	defaultBreakerErrorRate   = 0.5
+)
+
+type breaker struct {
+	probe   func(ctx context.Context) error
+	onState func(addr string, from, to CircuitState)
+	addr    string
+	window  int64
+	timeout int64
+	minReqs int64
+	rate    float64
+	state   atomic.Int32
+	start   atomic.Int64 // the start of the current window in unix nano
+	opened  atomic.Int64 // the time of the last open in unix nano, or 0 if the breaker is not open yet
+	reqs    atomic.Int64
+	fails   atomic.Int64
+}
+
+func newBreaker(addr string, opt *CircuitBreakerOption, probe func(ctx context.Context) error) *breaker {
+	b := &breaker{
+		probe:   probe,
+		onState: opt.OnStateChange,
+		addr:    addr,
+		window:  int64(opt.Window),
+		timeout: int64(opt.OpenTimeout),
+		minReqs: int64(opt.MinRequests),
+		rate:    opt.ErrorRate,
+	}
+	if b.window <= 0 {
+		b.window = int64(defaultBreakerWindow)
+	}
+	if b.timeout <= 0 {
+		b.timeout = int64(defaultBreakerOpenTimeout)
+	}
+	if b.minReqs <= 0 {
+		b.minReqs = defaultBreakerMinRequests
+	}
+	if b.rate <= 0 {
+		b.rate = defaultBreakerErrorRate
+	}
+	b.start.Store(time.Now().UnixNano())
+	return b
+}
+
+func (b *breaker) State() CircuitState {
+	return CircuitState(b.state.Load())
+}
+
+// allow reports whether a command can be sent to the node.
+// The first call after the OpenTimeout moves the breaker to half-open and starts probing the node.
+func (b *breaker) allow() bool {
+	switch CircuitState(b.state.Load()) {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if opened := b.opened.Load(); opened != 0 && time.Now().UnixNano()-opened >= b.timeout && b.transit(CircuitOpen, CircuitHalfOpen) {
+			go b.probing()
+		}
+	}
+	return false
+}
+
+// record counts the result of a command sent to the node and opens the breaker if the error rate is reached.
+// The counters are reset by the first record after the window elapses, so the window is fixed rather than sliding.
+// The ctx is the one of the command, used to tell the deadline of the caller from the timeout of the node.
+func (b *breaker) record(ctx context.Context, err error) {
+	if CircuitState(b.state.Load()) != CircuitClosed {
+		return
+	}
+	if now, start := time.Now().UnixNano(), b.start.Load(); now-start >= b.window && b.start.CompareAndSwap(start, now) {
+		b.reqs.Store(0)
+		b.fails.Store(0)
+	}
+	reqs := b.reqs.Add(1)
+	if !isBreakerFailure(ctx, err) {
+		return
+	}
+	if fails := b.fails.Add(1); reqs >= b.minReqs && float64(fails) >= float64(reqs)*b.rate {
+		b.transit(CircuitClosed, CircuitOpen)
+	}
+}
+
+func (b *breaker) recordMulti(ctx context.Context, resps []ValkeyResult) {
+	for _, r := range resps {
+		if err := r.NonValkeyError(); isBreakerFailure(ctx, err) {
+			b.record(ctx, err)
+			return
+		}
+	}
+	b.record(ctx, nil)
+}
+
+func (b *breaker) probing() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(b.timeout))
+	defer cancel()
+	if err := b.probe(ctx); err == nil {
+		b.transit(CircuitHalfOpen, CircuitClosed)
+	} else {
+		b.transit(CircuitHalfOpen, CircuitOpen)
+	}
+}
+
+func (b *breaker) transit(from, to CircuitState) bool {
+	if !b.state.CompareAndSwap(int32(from), int32(to)) {
+		return false
+	}
+	switch to {
+	case CircuitOpen:
+		b.opened.Store(time.Now().UnixNano())
+	case CircuitHalfOpen:
+		b.opened.Store(0) // so that the allow does not take the last open before the next one is stored.
+	}
+	if to == CircuitClosed {
+		b.start.Store(time.Now().UnixNano())
+		b.reqs.Store(0)
+		b.fails.Store(0)
+	}
+	if b.onState != nil {
+		b.onState(b.addr, from, to)
+	}
+	return true
+}
+
+// isBreakerFailure reports whether the err indicates an unhealthy node.
+// Valkey errors are responses from a healthy node, and the cancellations and the expired deadlines of the ctx are caused by callers.
+func isBreakerFailure(ctx context.Context, err error) bool {
+	if err == nil || err == ErrClosing || err == ErrCircuitOpen || err == ErrOverloaded || err == ErrDoCacheAborted || err == errConnExpired {
+		return false
+	}
+	if _, ok := err.(*ValkeyError); ok {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil {
+		return false
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// pickClosed returns the index of nodes selected by the selector among nodes whose circuit breaker is closed.
+// It returns -1 if there is no such node.
+func pickClosed(slot uint16, nodes []NodeInfo, selector ReadNodeSelectorFunc) int {
+	closed := make([]NodeInfo, 0, len(nodes))
+	index := make([]int, 0, len(nodes))
+	for i, node := range nodes {
+		if node.conn != nil && node.conn.Circuit() == CircuitClosed {
+			closed = append(closed, node)
+			index = append(index, i)
+		}
+	}
+	if len(closed) == 0 {
+		return -1
+	}
+	i := 0
+	if selector != nil {
+		if i = selector(slot, closed); i < 0 || i >= len(closed) {
+			i = 0
+		}
+	}
+	return index[i]
+}
@@ -0,0 +1,105 @@
+package valkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBreakerFailure(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	for _, c := range []struct {
+		ctx  context.Context
+		err  error
+		fail bool
+	}{
+		{err: nil, fail: false},
+		{err: Nil, fail: false},
+		{err: &ValkeyError{typ: '-'}, fail: false},
+		{err: ErrClosing, fail: false},
+		{err: ErrCircuitOpen, fail: false},
+		{err: ErrOverloaded, fail: false},
+		{err: ErrDoCacheAborted, fail: false},
+		{err: context.Canceled, fail: false},
+		{err: fmt.Errorf("wrapped %w", context.Canceled), fail: false},
+		{err: context.DeadlineExceeded, fail: true},
+		{ctx: expired, err: context.DeadlineExceeded, fail: false},
+		{ctx: expired, err: fmt.Errorf("wrapped %w", context.DeadlineExceeded), fail: false},
+		{ctx: expired, err: errors.New("any"), fail: true},
+		{err: errors.New("any"), fail: true},
+	} {
+		if c.ctx == nil {
+			c.ctx = context.Background()
+		}
+		if isBreakerFailure(c.ctx, c.err) != c.fail {
+			t.Fatalf("unexpected result for %v", c.err)
+		}
+	}
+}
+
+func TestBreakerWindow(t *testing.T) {
+	b := newBreaker("", &CircuitBreakerOption{Window: 10 * time.Millisecond, MinRequests: 2, ErrorRate: 1}, nil)
+	b.record(context.Background(), errors.New("any"))
+	time.Sleep(20 * time.Millisecond)
+	b.record(context.Background(), errors.New("any")) // the previous failure is out of the window
+	if b.State() != CircuitClosed {
+		t.Fatalf("unexpected state %v", b.State())
+	}
+	b.record(context.Background(), errors.New("any"))
+	if b.State() != CircuitOpen {
+		t.Fatalf("unexpected state %v", b.State())
+	}
+	if b.allow() {
+		t.Fatalf("unexpected allow")
+	}
+}
+
+func TestBreakerTransit(t *testing.T) {
+	b := newBreaker("", &CircuitBreakerOption{OpenTimeout: time.Hour}, nil)
+	if b.transit(CircuitHalfOpen, CircuitOpen) || b.opened.Load() != 0 {
+		t.Fatalf("the opened should not be stored if the transition fails")
+	}
+	if !b.transit(CircuitClosed, CircuitOpen) || b.opened.Load() == 0 {
+		t.Fatalf("the opened should be stored after the transition")
+	}
+	if b.transit(CircuitClosed, CircuitOpen) {
+		t.Fatalf("unexpected transition")
+	}
+	if !b.transit(CircuitOpen, CircuitHalfOpen) || b.opened.Load() != 0 {
+		t.Fatalf("the opened should be reset after leaving the open state")
+	}
+}
+
+func TestBreakerDefaults(t *testing.T) {
+	b := newBreaker("", &CircuitBreakerOption{}, nil)
+	if b.window != int64(defaultBreakerWindow) || b.timeout != int64(defaultBreakerOpenTimeout) || b.minReqs != defaultBreakerMinRequests || b.rate != defaultBreakerErrorRate {
+		t.Fatalf("unexpected defaults %v", b)
+	}
+	for _, s := range []CircuitState{CircuitClosed, CircuitOpen, CircuitHalfOpen} {
+		if s.String() == "" {
+			t.Fatalf("unexpected empty string")
+		}
+	}
+}
+
+func TestPickClosed(t *testing.T) {
+	open := &mockConn{CircuitFn: func() CircuitState { return CircuitOpen }}
+	closed := &mockConn{}
+	nodes := []NodeInfo{{conn: open, Addr: "0"}, {conn: closed, Addr: "1"}, {conn: closed, Addr: "2"}}
+
+	if i := pickClosed(0, nodes, nil); i != 1 {
+		t.Fatalf("unexpected index %v", i)
+	}
+	if i := pickClosed(0, nodes, func(slot uint16, nodes []NodeInfo) int { return 1 }); i != 2 {
+		t.Fatalf("unexpected index %v", i)
+	}
+	if i := pickClosed(0, nodes, func(slot uint16, nodes []NodeInfo) int { return 5 }); i != 1 {
+		t.Fatalf("unexpected index %v", i)
+	}
+	if i := pickClosed(0, nodes[:1], nil); i != -1 {
+		t.Fatalf("unexpected index %v", i)
+	}
+}
@@ -0,0 +1,251 @@
+package valkey
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/valkey-io/valkey-go/internal/cmds"
+)
+
+// BroadcastTracking is the set of key prefixes tracked by the broadcasting mode (BCAST) of the client-side caching.
+// Once it is assigned to the ClientOption.Broadcast, every connection of the client issues the
+// CLIENT TRACKING ON BCAST PREFIX ... command with the prefixes when it is established, including reconnections
+// and connections to the nodes discovered by cluster redirects.
+// In the broadcasting mode, Client.DoCache and Client.DoMultiCache cache the reads of keys under the prefixes
+// without opting in each read, and the reads of other keys are sent without caching.
+// The prefixes can be changed at runtime with the Add and Remove methods.
+type BroadcastTracking struct {
+	prefixes atomic.Pointer[[]string]
+	pipes    map[*pipe]struct{}
+	mu       sync.Mutex
+}
+
+// NewBroadcastTracking creates a BroadcastTracking with the prefixes.
+// It returns ErrBroadcastPrefixOverlap if any prefix is a prefix of another one, which is rejected by valkey.
+func NewBroadcastTracking(prefixes ...string) (*BroadcastTracking, error) {
+	b := &BroadcastTracking{pipes: make(map[*pipe]struct{})}
+	added, err := addPrefixes(nil, prefixes)
+	if err != nil {
+		return nil, err
+	}
+	b.prefixes.Store(&added)
+	return b, nil
+}
+
+// Prefixes returns the currently tracked prefixes.
+func (b *BroadcastTracking) Prefixes() []string {
+	return slices.Clone(b.load())
+}
+
+// Add tracks the prefixes on all connections. Reads under the prefixes are cached after they are tracked by all connections.
+// Prefixes already tracked are ignored, and it returns ErrBroadcastPrefixOverlap if any of them overlaps with another one.
+// Connections failed to track the prefixes are closed and will be reconnected with the updated prefixes.
+// The first error of them is returned.
+func (b *BroadcastTracking) Add(ctx context.Context, prefixes ...string) (err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current := b.load()
+	var added []string
+	for _, prefix := range prefixes {
+		if !slices.Contains(current, prefix) && !slices.Contains(added, prefix) {
+			added = append(added, prefix)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	updated, err := addPrefixes(current, added)
+	if err != nil {
+		return err
+	}
+	err = b.apply(ctx, added, false)
+	b.prefixes.Store(&updated)
+	return err
+}
+
+// Remove stops tracking the prefixes. Reads under the prefixes are not cached once it is called.
+// Since valkey can't remove prefixes from a connection, the tracking of each connection is turned off and on again
+// with the remaining prefixes, and the cached entries of the connection are flushed.
+// Connections failed to do so are closed and will be reconnected with the updated prefixes.
+// The first error of them is returned.
+func (b *BroadcastTracking) Remove(ctx context.Context, prefixes ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current := b.load()
+	remaining := make([]string, 0, len(current))
+	for _, prefix := range current {
+		if !slices.Contains(prefixes, prefix) {
+			remaining = append(remaining, prefix)
+		}
+	}
+	if len(remaining) == len(current) {
+		return nil
+	}
+	b.prefixes.Store(&remaining)
+	return b.apply(ctx, remaining, true)
+}
+
+func (b *BroadcastTracking) load() []string {
+	return *b.prefixes.Load()
+}
+
+// covers reports whether the key is under any tracked prefix.
+func (b *BroadcastTracking) covers(key string) bool {
+	for _, prefix := range b.load() {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// register adds the pipe established with the prefixes to the set receiving prefix changes.
+// The pipe is retracked if the prefixes were changed during its establishment.
+func (b *BroadcastTracking) register(ctx context.Context, p *pipe, prefixes []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for q := range b.pipes {
+		if q.Error() != nil {
+			delete(b.pipes, q)
+		}
+	}
+	if current := b.load(); !slices.Equal(prefixes, current) {
+		if err := p.retrack(ctx, current, true); err != nil {
+			return err
+		}
+	}
+	b.pipes[p] = struct{}{}
+	return nil
+}
+
+// apply sends the prefixes to all registered pipes. The tracking is turned off first and the cache is flushed if reset is true.
+func (b *BroadcastTracking) apply(ctx context.Context, prefixes []string, reset bool) (err error) {
+	for p := range b.pipes {
+		if p.Error() != nil {
+			delete(b.pipes, p)
+			continue
+		}
+		if e := p.retrack(ctx, prefixes, reset); e != nil {
+			delete(b.pipes, p)
+			p.Close()
+			if err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// trackingCmd returns the CLIENT TRACKING ON BCAST command with the prefixes, redirecting invalidations to the redirect if it is not 0.
+// It returns nil if there is no prefix, because tracking without any prefix broadcasts invalidations of all keys.
+func (b *BroadcastTracking) trackingCmd(prefixes []string, redirect int64) []string {
+	if len(prefixes) == 0 {
+		return nil
+	}
+	tracking := make([]string, 0, 6+2*len(prefixes))
+	tracking = append(tracking, "CLIENT", "TRACKING", "ON")
+	if redirect != 0 {
+		tracking = append(tracking, "REDIRECT", strconv.FormatInt(redirect, 10))
+	}
+	tracking = append(tracking, "BCAST")
+	for _, prefix := range prefixes {
+		tracking = append(tracking, "PREFIX", prefix)
+	}
+	return tracking
+}
+
+// retrack tracks the prefixes on the pipe. If reset is true, the tracking is turned off first and the cache is flushed after,
+// because invalidations are lost while the tracking is off.
+func (p *pipe) retrack(ctx context.Context, prefixes []string, reset bool) error {
+	multi := make([]Completed, 0, 2)
+	if reset {
+		multi = append(multi, cmds.ClientTrackingOffCmd)
+	}
+	if tracking := p.bcast.trackingCmd(prefixes, p.r2id); tracking != nil {
+		multi = append(multi, cmds.NewCompleted(tracking))
+	}
+	if len(multi) == 0 {
+		return nil
+	}
+	resp := p.DoMulti(ctx, multi...)
+	defer resultsp.Put(resp)
+	for _, r := range resp.s {
+		if err := r.Error(); err != nil {
+			return err
+		}
+	}
+	if reset {
+		p.cache.Delete(nil)
+	}
+	return nil
+}
+
+func addPrefixes(current, added []string) ([]string, error) {
+	updated := slices.Clone(current)
+	for _, prefix := range added {
+		for _, other := range updated {
+			if strings.HasPrefix(prefix, other) || strings.HasPrefix(other, prefix) {
+				return nil, ErrBroadcastPrefixOverlap
+			}
+		}
+		updated = append(updated, prefix)
+	}
+	return updated, nil
+}
+
+// uncached reports whether the cmd should be sent without caching, because its keys are not under the broadcast prefixes.
+func (p *pipe) uncached(cmd Cacheable) bool {
+	if p.bcast == nil {
+		return false
+	}
+	if cmd.IsMGet() {
+		keys := cmd.Commands()[1:]
+		if cmds.MGetCacheCmd(cmd)[0] == 'J' {
+			keys = keys[:len(keys)-1] // the last one of JSON.MGET is a path, not a key
+		}
+		for _, key := range keys {
+			if !p.bcast.covers(key) {
+				return true
+			}
+		}
+		return false
+	}
+	ck, _ := cmds.CacheKey(cmd)
+	return !p.bcast.covers(ck)
+}
+
+// doMultiCacheBroadcast caches the commands of keys under the broadcast prefixes only and sends the others without caching.
+func (p *pipe) doMultiCacheBroadcast(ctx context.Context, multi []CacheableTTL) *valkeyresults {
+	uncached := make([]bool, len(multi))
+	cached := make([]CacheableTTL, 0, len(multi))
+	plain := make([]Completed, 0, len(multi))
+	for i, ct := range multi {
+		if uncached[i] = p.uncached(ct.Cmd); uncached[i] {
+			plain = append(plain, Completed(ct.Cmd))
+		} else {
+			cached = append(cached, ct)
+		}
+	}
+	pr := p.DoMulti(ctx, plain...)
+	defer resultsp.Put(pr)
+	cr := &valkeyresults{}
+	if len(cached) != 0 {
+		cr = p.DoMultiCache(ctx, cached...)
+		defer resultsp.Put(cr)
+	}
+	results := resultsp.Get(0, len(multi))
+	for i, j, k := 0, 0, 0; i < len(multi); i++ {
+		if uncached[i] {
+			results.s = append(results.s, pr.s[j])
+			j++
+		} else {
+			results.s = append(results.s, cr.s[k])
+			k++
+		}
+	}
+	return results
+}
@@ -11,9 +11,31 @@ type NewCacheStoreFn func(CacheStoreOption) CacheStore
 
 // CacheStoreOption will be passed to NewCacheStoreFn
 type CacheStoreOption struct {
+	// CachePolicy is created by the ClientOption.NewCachePolicyFn for each CacheStore. It is nil if the NewCachePolicyFn is not provided.
+	CachePolicy CachePolicy
 	// CacheSizeEachConn is valkey client side cache size that bind to each TCP connection to a single valkey instance.
 	// The default is DefaultCacheBytes.
 	CacheSizeEachConn int
+	// node is the remote address and the selected database of the connection, which scopes the NewSharedCacheStoreFn.
+	node string
+}
+
+// NewCachePolicyFn can be provided in ClientOption for using a CachePolicy in the default CacheStore
+type NewCachePolicyFn func() CachePolicy
+
+// CachePolicy is the admission policy of the default CacheStore. Entries are identified by the hashes of their keys and commands.
+// Without a CachePolicy, the default CacheStore evicts the least recently used entries when it is full.
+// With a CachePolicy, new entries are put into a window sized by the Window, and the least recently used entry of the window
+// competes with the least recently used entry of the rest of the cache by the Admit once the window is over its share.
+// If the Window is 0, every new entry competes with the least recently used entry when the cache is full.
+// See NewTinyLFUPolicyFn for the built-in implementation.
+type CachePolicy interface {
+	// Record is called on every lookup of an entry, including hits and misses. It must be safe for concurrent use.
+	Record(hash uint64)
+	// Admit reports whether the candidate should be kept by evicting the victim. Otherwise, the candidate is evicted.
+	Admit(candidate, victim uint64) bool
+	// Window is the fraction of the number of entries reserved for new entries, between 0 and 1.
+	Window() float64
 }
 
 // CacheStore is the store interface for the client side caching
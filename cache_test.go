@@ -178,6 +178,16 @@ func TestCacheStore(t *testing.T) {
 			return newLRU(CacheStoreOption{CacheSizeEachConn: DefaultCacheBytes})
 		})
 	})
+	t.Run("SharedLRUCacheStore", func(t *testing.T) {
+		test(t, func() CacheStore {
+			return NewSharedCacheStoreFn(DefaultCacheBytes)(CacheStoreOption{})
+		})
+	})
+	t.Run("TinyLFUCacheStore", func(t *testing.T) {
+		test(t, func() CacheStore {
+			return newLRU(CacheStoreOption{CacheSizeEachConn: DefaultCacheBytes, CachePolicy: NewTinyLFUPolicyFn(TinyLFUOption{Window: 0.01})()})
+		})
+	})
 	t.Run("SimpleCache", func(t *testing.T) {
 		test(t, func() CacheStore {
 			return NewSimpleCacheAdapter(&simple{store: map[string]ValkeyMessage{}})
@@ -49,17 +49,23 @@ func (c *singleClient) B() Builder {
 }
 
 func (c *singleClient) Do(ctx context.Context, cmd Completed) (resp ValkeyResult) {
+	return c.settle(ctx, cmd, c.conn.Do(ctx, cmd))
+}
+
+// settle resends the cmd until its resp is not retryable, and then recycles the cmd.
+func (c *singleClient) settle(ctx context.Context, cmd Completed, resp ValkeyResult) ValkeyResult {
 	attempts := 1
-retry:
-	resp = c.conn.Do(ctx, cmd)
+process:
 	if err := resp.Error(); err != nil {
 		if err == errConnExpired {
-			goto retry
+			resp = c.conn.Do(ctx, cmd)
+			goto process
 		}
 		if c.retry && cmd.IsRetryable() && c.isRetryable(err, ctx) {
 			if c.retryHandler.WaitOrSkipRetry(ctx, attempts, cmd, err) {
 				attempts++
-				goto retry
+				resp = c.conn.Do(ctx, cmd)
+				goto process
 			}
 		}
 	}
@@ -69,6 +75,56 @@ retry:
 	return resp
 }
 
+// settled reports whether the settle of the resp can be done without resending the cmd.
+func (c *singleClient) settled(ctx context.Context, cmd Completed, resp ValkeyResult) bool {
+	err := resp.Error()
+	return err == nil || (err != errConnExpired && !(c.retry && cmd.IsRetryable() && c.isRetryable(err, ctx)))
+}
+
+func (c *singleClient) DoAsync(ctx context.Context, cmd Completed) *ValkeyFuture {
+	f := newFuture()
+	c.doAsync(ctx, cmd, f.resolve)
+	return f
+}
+
+// doAsync passes the settled resp to the fn. The fn is called by the goroutine reading responses from the connection
+// unless the cmd needs to be resent, in which case, a new goroutine is used to wait for retries.
+func (c *singleClient) doAsync(ctx context.Context, cmd Completed, fn func(ValkeyResult)) {
+	c.conn.DoAsync(ctx, cmd, func(resp ValkeyResult) {
+		if c.settled(ctx, cmd, resp) {
+			fn(c.settle(ctx, cmd, resp))
+		} else {
+			go func() { fn(c.settle(ctx, cmd, resp)) }()
+		}
+	})
+}
+
+func (c *singleClient) DoMultiAsync(ctx context.Context, multi ...Completed) *ValkeyMultiFuture {
+	f := newMultiFuture(len(multi))
+	f.add(1)
+	if len(multi) == 0 {
+		f.resolve()
+		return f
+	}
+	c.doMultiAsync(ctx, multi, f.resps, f.resolve)
+	return f
+}
+
+// doMultiAsync fills the resps with the settled results and then calls the fn.
+func (c *singleClient) doMultiAsync(ctx context.Context, multi []Completed, resps []ValkeyResult, fn func()) {
+	c.conn.DoMultiAsync(ctx, resps, func() {
+		if c.settledMulti(ctx, multi, resps) {
+			c.settleMulti(ctx, multi, resps)
+			fn()
+		} else {
+			go func() {
+				copy(resps, c.settleMulti(ctx, multi, resps))
+				fn()
+			}()
+		}
+	}, multi...)
+}
+
 func (c *singleClient) DoStream(ctx context.Context, cmd Completed) ValkeyResultStream {
 	s := c.conn.DoStream(ctx, cmd)
 	cmds.PutCompleted(cmd)
@@ -90,9 +146,13 @@ func (c *singleClient) DoMulti(ctx context.Context, multi ...Completed) (resps [
 	if len(multi) == 0 {
 		return nil
 	}
+	return c.settleMulti(ctx, multi, c.conn.DoMulti(ctx, multi...).s)
+}
+
+// settleMulti resends the multi until its resps are not retryable, and then recycles the multi.
+func (c *singleClient) settleMulti(ctx context.Context, multi []Completed, resps []ValkeyResult) []ValkeyResult {
 	attempts := 1
-retry:
-	resps = c.conn.DoMulti(ctx, multi...).s
+process:
 	if c.hasLftm {
 		var ml []Completed
 	recover:
@@ -128,7 +188,8 @@ retry:
 				)
 				if shouldRetry {
 					attempts++
-					goto retry
+					resps = c.conn.DoMulti(ctx, multi...).s
+					goto process
 				}
 			}
 		}
@@ -141,6 +202,17 @@ retry:
 	return resps
 }
 
+// settledMulti reports whether the settleMulti of the resps can be done without resending any of the multi.
+func (c *singleClient) settledMulti(ctx context.Context, multi []Completed, resps []ValkeyResult) bool {
+	retry := c.retry && allRetryable(multi)
+	for _, resp := range resps {
+		if (c.hasLftm && resp.NonValkeyError() == errConnExpired) || (retry && c.isRetryable(resp.Error(), ctx)) {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *singleClient) DoMultiCache(ctx context.Context, multi ...CacheableTTL) (resps []ValkeyResult) {
 	if len(multi) == 0 {
 		return nil
@@ -246,6 +318,10 @@ func (c *singleClient) Nodes() map[string]Client {
 	return map[string]Client{c.conn.Addr(): c}
 }
 
+func (c *singleClient) Stats() map[string]NodeStats {
+	return map[string]NodeStats{c.conn.Addr(): c.conn.Stats()}
+}
+
 func (c *singleClient) Mode() ClientMode {
 	return ClientModeStandalone
 }
@@ -255,6 +331,11 @@ func (c *singleClient) Close() {
 	c.conn.Close()
 }
 
+func (c *singleClient) CloseWithContext(ctx context.Context) error {
+	atomic.StoreUint32(&c.stop, 1)
+	return c.conn.CloseWithContext(ctx)
+}
+
 type dedicatedSingleClient struct {
 	conn         conn
 	wire         wire
@@ -370,6 +451,12 @@ func (c *dedicatedSingleClient) Close() {
 	c.release()
 }
 
+func (c *dedicatedSingleClient) CloseWithContext(ctx context.Context) error {
+	err := c.wire.CloseWithContext(ctx)
+	c.release()
+	return err
+}
+
 func (c *dedicatedSingleClient) check() error {
 	if atomic.LoadUint32(&c.mark) != 0 {
 		return ErrDedicatedClientRecycled
@@ -384,7 +471,7 @@ func (c *dedicatedSingleClient) release() {
 }
 
 func (c *singleClient) isRetryable(err error, ctx context.Context) bool {
-	if err == nil || err == Nil || err == ErrDoCacheAborted || atomic.LoadUint32(&c.stop) != 0 || ctx.Err() != nil {
+	if err == nil || err == Nil || err == ErrDoCacheAborted || err == ErrCircuitOpen || err == ErrOverloaded || atomic.LoadUint32(&c.stop) != 0 || ctx.Err() != nil {
 		return false
 	}
 	if err, ok := err.(*ValkeyError); ok {
@@ -412,6 +499,15 @@ func allRetryable(multi []Completed) bool {
 	return true
 }
 
+func allReadOnly(multi []Completed) bool {
+	for _, cmd := range multi {
+		if !cmd.IsReadOnly() {
+			return false
+		}
+	}
+	return true
+}
+
 func chooseSlot(multi []Completed) uint16 {
 	for i := range multi {
 		if multi[i].Slot() != cmds.InitSlot {
@@ -27,11 +27,15 @@ type mockConn struct {
 	AZFn            func() string
 	ErrorFn         func() error
 	CloseFn         func()
+	CloseCtxFn      func(ctx context.Context) error
 	DialFn          func() error
 	AcquireFn       func() wire
 	StoreFn         func(w wire)
 	OverrideFn      func(c conn)
 	AddrFn          func() string
+	StatsFn         func() NodeStats
+	CircuitFn       func() CircuitState
+	RTTFn           func() time.Duration
 
 	DoOverride      map[string]func(cmd Completed) ValkeyResult
 	DoCacheOverride map[string]func(cmd Cacheable, ttl time.Duration) ValkeyResult
@@ -74,6 +78,15 @@ func (m *mockConn) Do(ctx context.Context, cmd Completed) ValkeyResult {
 	return ValkeyResult{}
 }
 
+func (m *mockConn) DoAsync(ctx context.Context, cmd Completed, fn func(ValkeyResult)) {
+	fn(m.Do(ctx, cmd))
+}
+
+func (m *mockConn) DoMultiAsync(ctx context.Context, resps []ValkeyResult, fn func(), multi ...Completed) {
+	copy(resps, m.DoMulti(ctx, multi...).s)
+	fn()
+}
+
 func (m *mockConn) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) ValkeyResult {
 	if fn := m.DoCacheOverride[strings.Join(cmd.Commands(), " ")]; fn != nil {
 		return fn(cmd, ttl)
@@ -190,6 +203,14 @@ func (m *mockConn) Close() {
 	}
 }
 
+func (m *mockConn) CloseWithContext(ctx context.Context) error {
+	if m.CloseCtxFn != nil {
+		return m.CloseCtxFn(ctx)
+	}
+	m.Close()
+	return nil
+}
+
 func (m *mockConn) Addr() string {
 	if m.AddrFn != nil {
 		return m.AddrFn()
@@ -197,6 +218,27 @@ func (m *mockConn) Addr() string {
 	return ""
 }
 
+func (m *mockConn) Stats() NodeStats {
+	if m.StatsFn != nil {
+		return m.StatsFn()
+	}
+	return NodeStats{}
+}
+
+func (m *mockConn) Circuit() CircuitState {
+	if m.CircuitFn != nil {
+		return m.CircuitFn()
+	}
+	return CircuitClosed
+}
+
+func (m *mockConn) RTT() time.Duration {
+	if m.RTTFn != nil {
+		return m.RTTFn()
+	}
+	return 0
+}
+
 func (m *mockConn) OptInCmd() cmds.Completed {
 	return cmds.OptInCmd
 }
@@ -254,7 +296,8 @@ func TestNewSingleClientOverride(t *testing.T) {
 func TestSingleClient(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	m := &mockConn{
-		AddrFn: func() string { return "myaddr" },
+		AddrFn:  func() string { return "myaddr" },
+		StatsFn: func() NodeStats { return NodeStats{Reconnects: 1} },
 	}
 	client, err := newSingleClient(
 		&ClientOption{InitAddress: []string{""}},
@@ -272,6 +315,12 @@ func TestSingleClient(t *testing.T) {
 		}
 	})
 
+	t.Run("Stats", func(t *testing.T) {
+		if stats := client.Stats(); len(stats) != 1 || stats["myaddr"].Reconnects != 1 {
+			t.Fatalf("unexpected stats %v", stats)
+		}
+	})
+
 	t.Run("Mode", func(t *testing.T) {
 		if v := client.Mode(); v != ClientModeStandalone {
 			t.Fatalf("unexpected mode %v", v)
@@ -384,6 +433,15 @@ func TestSingleClient(t *testing.T) {
 		}
 	})
 
+	t.Run("Delegate CloseWithContext", func(t *testing.T) {
+		e := errors.New("ctx")
+		m.CloseCtxFn = func(ctx context.Context) error { return e }
+		if err := client.CloseWithContext(context.Background()); err != e {
+			t.Fatalf("CloseWithContext is not delegated")
+		}
+		m.CloseCtxFn = nil
+	})
+
 	t.Run("Delegate Close", func(t *testing.T) {
 		called := false
 		m.CloseFn = func() { called = true }
@@ -565,6 +623,23 @@ func TestSingleClient(t *testing.T) {
 		}
 	})
 
+	t.Run("Dedicate Delegate Release On CloseWithContext", func(t *testing.T) {
+		stored := 0
+		e := errors.New("ctx")
+		w := &mockWire{CloseCtxFn: func(ctx context.Context) error { return e }}
+		m.AcquireFn = func() wire { return w }
+		m.StoreFn = func(ww wire) { stored++ }
+		c, _ := client.Dedicate()
+
+		if err := c.CloseWithContext(context.Background()); err != e {
+			t.Fatalf("unexpected err %v", err)
+		}
+
+		if stored != 1 {
+			t.Fatalf("unexpected stored count %v", stored)
+		}
+	})
+
 	t.Run("Dedicate Delegate No Duplicate Release", func(t *testing.T) {
 		stored := 0
 		w := &mockWire{}
@@ -718,6 +793,22 @@ func SetupClientRetry(t *testing.T, fn func(mock *mockConn) Client) {
 		}
 	})
 
+	t.Run("Delegate Do ReadOnly NoRetry - circuit open", func(t *testing.T) {
+		c, m := setup()
+		m.DoFn = makeDoFn(newErrResult(ErrCircuitOpen))
+		if v, err := c.Do(context.Background(), c.B().Get().Key("Do").Build()).ToString(); err != ErrCircuitOpen {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("Delegate Do ReadOnly NoRetry - overloaded", func(t *testing.T) {
+		c, m := setup()
+		m.DoFn = makeDoFn(newErrResult(ErrOverloaded))
+		if v, err := c.Do(context.Background(), c.B().Get().Key("Do").Build()).ToString(); err != ErrOverloaded {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
 	t.Run("Delegate Do ReadOnly NoRetry - not retryable", func(t *testing.T) {
 		c, m := setup()
 		if cli, ok := c.(*sentinelClient); ok {
@@ -780,6 +871,46 @@ func SetupClientRetry(t *testing.T, fn func(mock *mockConn) Client) {
 		}
 	})
 
+	t.Run("Delegate DoAsync ReadOnly Retry", func(t *testing.T) {
+		c, m := setup()
+		m.DoFn = makeDoFn(
+			newErrResult(ErrClosing),
+			newResult(strmsg('+', "Do"), nil),
+		)
+		if v, err := c.DoAsync(context.Background(), c.B().Get().Key("Do").Build()).Wait(context.Background()).ToString(); err != nil || v != "Do" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("Delegate DoAsync ReadOnly NoRetry - closed", func(t *testing.T) {
+		c, m := setup()
+		m.DoFn = makeDoFn(newErrResult(ErrClosing))
+		c.Close()
+		if v, err := c.DoAsync(context.Background(), c.B().Get().Key("Do").Build()).Wait(context.Background()).ToString(); err != ErrClosing {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("Delegate DoMultiAsync ReadOnly Retry", func(t *testing.T) {
+		c, m := setup()
+		m.DoMultiFn = makeDoMultiFn(
+			[]ValkeyResult{newErrResult(ErrClosing)},
+			[]ValkeyResult{newResult(strmsg('+', "Do"), nil)},
+		)
+		if v, err := c.DoMultiAsync(context.Background(), c.B().Get().Key("Do").Build()).Wait(context.Background())[0].ToString(); err != nil || v != "Do" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("Delegate DoMultiAsync ReadOnly NoRetry - closed", func(t *testing.T) {
+		c, m := setup()
+		m.DoMultiFn = makeDoMultiFn([]ValkeyResult{newErrResult(ErrClosing)})
+		c.Close()
+		if v, err := c.DoMultiAsync(context.Background(), c.B().Get().Key("Do").Build()).Wait(context.Background())[0].ToString(); err != ErrClosing {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
 	t.Run("Delegate DoMulti ReadOnly NoRetry - closed", func(t *testing.T) {
 		c, m := setup()
 		m.DoMultiFn = makeDoMultiFn([]ValkeyResult{newErrResult(ErrClosing)})
@@ -888,6 +1019,22 @@ func SetupClientRetry(t *testing.T, fn func(mock *mockConn) Client) {
 		}
 	})
 
+	t.Run("Delegate DoCache ReadOnly NoRetry - circuit open", func(t *testing.T) {
+		c, m := setup()
+		m.DoCacheFn = makeDoCacheFn(newErrResult(ErrCircuitOpen))
+		if v, err := c.DoCache(context.Background(), c.B().Get().Key("Do").Cache(), 0).ToString(); err != ErrCircuitOpen {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("Delegate DoCache ReadOnly NoRetry - overloaded", func(t *testing.T) {
+		c, m := setup()
+		m.DoCacheFn = makeDoCacheFn(newErrResult(ErrOverloaded))
+		if v, err := c.DoCache(context.Background(), c.B().Get().Key("Do").Cache(), 0).ToString(); err != ErrOverloaded {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
 	t.Run("Delegate DoCache ReadOnly NoRetry - not retryable", func(t *testing.T) {
 		c, m := setup()
 		if cli, ok := c.(*sentinelClient); ok {
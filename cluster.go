@@ -3,10 +3,13 @@ package valkey
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"math/rand"
 	"net"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,6 +27,10 @@ var ErrReplicaOnlyConflictWithReadNodeSelector = errors.New("ReplicaOnly conflic
 var ErrReplicaSelectorConflictWithReadNodeSelector = errors.New("either set ReplicaSelector or ReadNodeSelector, not both")
 var ErrSendToReplicasNotSet = errors.New("SendToReplicas must be set when ReplicaSelector is set")
 
+var errBroadcastReplyLength = errors.New("the array replies of the broadcast command have different lengths")
+var errSplitReplyLength = errors.New("the array reply of the split command has an unexpected length")
+var errCrossSlot = errors.New("cross slot commands can only be split by Do, DoMulti, DoAsync, and DoMultiAsync")
+
 type clusterClient struct {
 	wslots       [16384]conn
 	retryHandler retryHandler
@@ -34,16 +41,23 @@ type clusterClient struct {
 	stopCh       chan struct{}
 	sc           call
 	rslots       [][]NodeInfo
+	pslots       []uint16 // the first slot of each shard
+	sampled      []nodes  // the nodes of each shard for sampling replication lags
+	topo         *topology
+	lags         *sampler
+	migrations   *migrations
 	mu           sync.RWMutex
 	stop         uint32
 	cmd          Builder
 	retry        bool
 	hasLftm      bool
+	rerouting    bool
 }
 
 // NOTE: connrole and conn must be initialized at the same time
 type connrole struct {
 	conn   conn
+	lag    *atomic.Int64 // the replication lag sampled with the ClientOption.ReplicaLag
 	hidden bool
 	//replica bool <- this field is removed because a server may have mixed roles at the same time in the future. https://github.com/valkey-io/valkey/issues/1372
 }
@@ -62,6 +76,12 @@ func newClusterClient(opt *ClientOption, connFn connFn, retryer retryHandler) (*
 		retryHandler: retryer,
 		stopCh:       make(chan struct{}),
 		hasLftm:      opt.ConnLifetime > 0,
+		rerouting:    opt.CircuitBreaker != nil && opt.CircuitBreaker.RerouteReadOnly,
+		migrations:   newMigrations(opt.ClusterOption.OnSlotMigration),
+	}
+
+	if opt.ClusterOption.SplitCrossSlot {
+		client.cmd = cmds.NewBuilder(cmds.SplitSlot)
 	}
 
 	if opt.ReplicaOnly && opt.SendToReplicas != nil {
@@ -111,6 +131,10 @@ func newClusterClient(opt *ClientOption, connFn connFn, retryer retryHandler) (*
 		return nil, ErrInvalidShardsRefreshInterval
 	}
 
+	if opt.ReplicaLag != nil && client.rOpt != nil {
+		client.lags = newLagSampler(opt.ReplicaLag, client.sampleLags)
+	}
+
 	return client, nil
 }
 
@@ -134,7 +158,7 @@ func (c *clusterClient) init() error {
 				c.mu.Unlock()
 				results <- nil
 			} else {
-				results <- err
+				results <- selectDBErr(err)
 			}
 		}(addr, cc)
 	}
@@ -149,6 +173,14 @@ func (c *clusterClient) init() error {
 	return es[0]
 }
 
+// selectDBErr wraps the err with the ErrSelectDBNotSupported if it is the rejection of SELECT by a cluster before Valkey 9.
+func selectDBErr(err error) error {
+	if ve, ok := IsValkeyErr(err); ok && strings.Contains(ve.Error(), "SELECT is not allowed in cluster mode") {
+		return fmt.Errorf("%w: %v", ErrSelectDBNotSupported, err)
+	}
+	return err
+}
+
 func (c *clusterClient) refresh(ctx context.Context) (err error) {
 	return c.sc.Do(ctx, c._refresh)
 }
@@ -239,7 +271,11 @@ func (c *clusterClient) _refresh() (err error) {
 		conns[master] = connrole{conn: c.connFn(master, c.opt)}
 		if c.rOpt != nil {
 			for _, nodeInfo := range g.nodes[1:] {
-				conns[nodeInfo.Addr] = connrole{conn: c.connFn(nodeInfo.Addr, c.rOpt)}
+				cr := connrole{conn: c.connFn(nodeInfo.Addr, c.rOpt)}
+				if c.opt.ReplicaLag != nil {
+					cr.lag = newLag()
+				}
+				conns[nodeInfo.Addr] = cr
 			}
 		} else {
 			for _, nodeInfo := range g.nodes[1:] {
@@ -263,6 +299,9 @@ func (c *clusterClient) _refresh() (err error) {
 	for addr, cc := range c.conns {
 		if fresh, ok := conns[addr]; ok {
 			fresh.conn = cc.conn
+			if cc.lag != nil {
+				fresh.lag = cc.lag
+			}
 			conns[addr] = fresh
 		} else {
 			removes = append(removes, cc.conn)
@@ -272,10 +311,21 @@ func (c *clusterClient) _refresh() (err error) {
 
 	wslots := [16384]conn{}
 	var rslots [][]NodeInfo
+	pslots := make([]uint16, 0, len(groups))
+	var sampled []nodes
 	for _, g := range groups {
+		if len(g.slots) > 0 && g.slots[0][0] >= 0 && g.slots[0][0] < 16384 {
+			pslots = append(pslots, uint16(g.slots[0][0]))
+		}
 
 		for i, nodeInfo := range g.nodes {
 			g.nodes[i].conn = conns[nodeInfo.Addr].conn
+			if i > 0 {
+				g.nodes[i].lag = conns[nodeInfo.Addr].lag
+			}
+		}
+		if c.opt.ReplicaLag != nil && c.rOpt != nil {
+			sampled = append(sampled, g.nodes)
 		}
 
 		switch {
@@ -340,12 +390,27 @@ func (c *clusterClient) _refresh() (err error) {
 		}
 	}
 
+	slices.Sort(pslots)
+
 	c.mu.Lock()
 	c.wslots = wslots
 	c.rslots = rslots
+	c.pslots = pslots
+	c.sampled = sampled
 	c.conns = conns
 	c.mu.Unlock()
 
+	c.migrations.reconcile(groups)
+
+	if fn := c.opt.OnTopologyChange; fn != nil {
+		topo := newClusterTopology(groups)
+		change := diffTopology(c.topo, topo)
+		c.topo = topo // only accessed by the _refresh, which is serialized by the c.sc.
+		if !change.IsEmpty() {
+			fn(change)
+		}
+	}
+
 	if len(removes) > 0 {
 		go func(removes []conn) {
 			time.Sleep(time.Second * 5)
@@ -529,21 +594,20 @@ func (c *clusterClient) runClusterTopologyRefreshment() {
 
 func (c *clusterClient) _pick(slot uint16, toReplica bool) (p conn) {
 	c.mu.RLock()
-	if slot == cmds.InitSlot {
+	if slot != cmds.InitSlot && int(slot) >= len(c.wslots) {
+		p = nil // the CrossSlot and the NoSlot are not served by any node
+	} else if slot == cmds.InitSlot {
 		for _, cc := range c.conns {
 			p = cc.conn
 			break
 		}
 	} else if toReplica && c.rslots != nil {
 		if nodes := c.rslots[slot]; len(nodes) > 0 {
-			rIndex := 0
-			if c.opt.ReadNodeSelector != nil {
-				rIndex = c.opt.ReadNodeSelector(slot, nodes)
-				if rIndex < 0 || rIndex >= len(nodes) {
-					rIndex = 0
-				}
+			if rIndex := c._pickReplica(slot, nodes); rIndex >= 0 {
+				p = nodes[rIndex].conn
+			} else {
+				p = c.wslots[slot]
 			}
-			p = nodes[rIndex].conn
 		}
 	} else {
 		p = c.wslots[slot]
@@ -552,7 +616,44 @@ func (c *clusterClient) _pick(slot uint16, toReplica bool) (p conn) {
 	return p
 }
 
+// _pickReplica returns the index of the nodes selected by the ClientOption.ReadNodeSelector for a read-only command.
+// It returns -1 if all the nodes lag behind more than the ClientOption.ReplicaLag.MaxLag.
+func (c *clusterClient) _pickReplica(slot uint16, nodes []NodeInfo) int {
+	if c.opt.ReplicaLag != nil {
+		return pickFresh(slot, nodes, c.opt.ReadNodeSelector, c.opt.ReplicaLag.MaxLag)
+	}
+	rIndex := 0
+	if c.opt.ReadNodeSelector != nil {
+		rIndex = c.opt.ReadNodeSelector(slot, nodes)
+		if rIndex < 0 || rIndex >= len(nodes) {
+			rIndex = 0
+		}
+	}
+	return rIndex
+}
+
+// sampleLags samples the replication lags of the replicas of each shard concurrently.
+func (c *clusterClient) sampleLags(ctx context.Context) {
+	c.mu.RLock()
+	shards := c.sampled
+	c.mu.RUnlock()
+	var wg sync.WaitGroup
+	for _, nodes := range shards {
+		if len(nodes) > 1 {
+			wg.Add(1)
+			go func(nodes []NodeInfo) {
+				sampleLags(ctx, nodes[0].conn, nodes[1:])
+				wg.Done()
+			}(nodes)
+		}
+	}
+	wg.Wait()
+}
+
 func (c *clusterClient) pick(ctx context.Context, slot uint16, toReplica bool) (p conn, err error) {
+	if slot == cmds.CrossSlot {
+		return nil, errCrossSlot
+	}
 	if p = c._pick(slot, toReplica); p == nil {
 		if err := c.refresh(ctx); err != nil {
 			return nil, err
@@ -564,11 +665,34 @@ func (c *clusterClient) pick(ctx context.Context, slot uint16, toReplica bool) (
 	return p, nil
 }
 
+// reroute returns another node of the slot for the read-only cmd if the circuit breaker of the cc is not closed.
+func (c *clusterClient) reroute(slot uint16, cc conn, cmd Completed) conn {
+	if !c.rerouting || !cmd.IsReadOnly() || cc.Circuit() == CircuitClosed {
+		return cc
+	}
+	c.mu.RLock()
+	if i := c._reroute(slot, cc, cmd); i >= 0 {
+		cc = c.rslots[slot][i].conn
+	}
+	c.mu.RUnlock()
+	return cc
+}
+
+// _reroute returns the index of c.rslots[slot] selected by the ReadNodeSelector among nodes whose circuit breaker is closed,
+// if the cmd is read-only and the circuit breaker of the cc is not closed. Otherwise, it returns -1.
+func (c *clusterClient) _reroute(slot uint16, cc conn, cmd Completed) int {
+	if !c.rerouting || c.rslots == nil || cc == nil || !cmd.IsReadOnly() || cc.Circuit() == CircuitClosed {
+		return -1
+	}
+	return pickClosed(slot, c.rslots[slot], c.opt.ReadNodeSelector)
+}
+
 func (c *clusterClient) redirectOrNew(addr string, prev conn, slot uint16, mode RedirectMode) conn {
 	c.mu.RLock()
 	cc := c.conns[addr]
 	c.mu.RUnlock()
 	if cc.conn != nil && prev != cc.conn {
+		c.redirected(addr, prev, cc.conn, slot, mode)
 		return cc.conn
 	}
 	c.mu.Lock()
@@ -576,9 +700,6 @@ func (c *clusterClient) redirectOrNew(addr string, prev conn, slot uint16, mode
 		p := c.connFn(addr, c.opt)
 		cc = connrole{conn: p}
 		c.conns[addr] = cc
-		if mode == RedirectMove && slot != cmds.InitSlot {
-			c.wslots[slot] = p
-		}
 	} else if prev == cc.conn {
 		// try reconnection if the MOVED redirects to the same host,
 		// because the same hostname may actually be resolved into another destination
@@ -590,14 +711,40 @@ func (c *clusterClient) redirectOrNew(addr string, prev conn, slot uint16, mode
 		p := c.connFn(addr, c.opt)
 		cc = connrole{conn: p}
 		c.conns[addr] = cc
-		if mode == RedirectMove && slot != cmds.InitSlot { // MOVED should always point to the primary.
-			c.wslots[slot] = p
-		}
 	}
 	c.mu.Unlock()
+	c.redirected(addr, prev, cc.conn, slot, mode)
 	return cc.conn
 }
 
+// redirected records the slot redirected from the prev to the cc of the addr.
+// A MOVED redirect points the slot to the cc, so that the following commands of the slot are not redirected again
+// before the topology is refreshed. An ASK redirect leaves the slot unchanged, because the slot is still served by
+// the prev until its migration is done.
+func (c *clusterClient) redirected(addr string, prev, cc conn, slot uint16, mode RedirectMode) {
+	if slot == cmds.InitSlot {
+		return
+	}
+	var source string
+	if prev != nil {
+		source = prev.Addr()
+	}
+	switch mode {
+	case RedirectMove: // MOVED should always point to the primary.
+		c.mu.Lock()
+		moved := c.wslots[slot] != cc
+		if moved {
+			c.wslots[slot] = cc
+		}
+		c.mu.Unlock()
+		if moved {
+			c.migrations.moved(slot, source, addr)
+		}
+	case RedirectAsk:
+		c.migrations.ask(slot, source, addr)
+	}
+}
+
 func (c *clusterClient) B() Builder {
 	return c.cmd
 }
@@ -609,15 +756,32 @@ func (c *clusterClient) Do(ctx context.Context, cmd Completed) (resp ValkeyResul
 	return resp
 }
 
-func (c *clusterClient) do(ctx context.Context, cmd Completed) (resp ValkeyResult) {
+func (c *clusterClient) do(ctx context.Context, cmd Completed) ValkeyResult {
+	if isBroadcast(cmd) {
+		return c.broadcast(ctx, cmd)
+	}
+	if cmd.Slot() == cmds.CrossSlot {
+		return c.split(ctx, cmd)
+	}
+	return c.settle(ctx, cmd, nil, ValkeyResult{})
+}
+
+// settle follows redirections and retries of the resp replied by the cc. It sends the cmd first if the cc is nil.
+func (c *clusterClient) settle(ctx context.Context, cmd Completed, cc conn, resp ValkeyResult) ValkeyResult {
+	var err error
 	attempts := 1
 	redirects := 0
+	if cc != nil {
+		goto check
+	}
 retry:
-	cc, err := c.pick(ctx, cmd.Slot(), c.toReplica(cmd))
+	cc, err = c.pick(ctx, cmd.Slot(), c.toReplica(cmd))
 	if err != nil {
 		return newErrResult(err)
 	}
+	cc = c.reroute(cmd.Slot(), cc, cmd)
 	resp = cc.Do(ctx, cmd)
+check:
 	if resp.NonValkeyError() == errConnExpired {
 		goto retry
 	}
@@ -661,6 +825,133 @@ process:
 	return resp
 }
 
+// settled reports whether the resp needs neither redirections nor retries.
+func (c *clusterClient) settled(ctx context.Context, cmd Completed, resp ValkeyResult) bool {
+	if resp.NonValkeyError() == errConnExpired {
+		return false
+	}
+	_, mode := c.shouldRefreshRetry(resp.Error(), ctx)
+	return mode == RedirectNone || (mode == RedirectRetry && !(c.retry && cmd.IsRetryable()))
+}
+
+func (c *clusterClient) DoAsync(ctx context.Context, cmd Completed) *ValkeyFuture {
+	f := newFuture()
+	if isBroadcast(cmd) || cmd.Slot() == cmds.CrossSlot {
+		go func() { f.resolve(c.recycle(cmd, c.do(ctx, cmd))) }()
+		return f
+	}
+	cc, err := c.pick(ctx, cmd.Slot(), c.toReplica(cmd))
+	if err != nil {
+		f.resolve(newErrResult(err))
+		return f
+	}
+	cc = c.reroute(cmd.Slot(), cc, cmd)
+	cc.DoAsync(ctx, cmd, func(resp ValkeyResult) {
+		if !c.settled(ctx, cmd, resp) {
+			go func() { f.resolve(c.recycle(cmd, c.settle(ctx, cmd, cc, resp))) }()
+			return
+		}
+		f.resolve(c.recycle(cmd, resp))
+	})
+	return f
+}
+
+func (c *clusterClient) recycle(cmd Completed, resp ValkeyResult) ValkeyResult {
+	if resp.NonValkeyError() == nil { // not recycle cmds if error, since cmds may be used later in the pipe.
+		cmds.PutCompleted(cmd)
+	}
+	return resp
+}
+
+// DoMultiAsync groups the multi by nodes and pipelines each group asynchronously.
+// Commands that need redirections or retries are resent by DoMulti in a new goroutine.
+// Transactions are not pipelined asynchronously and fall back to DoMulti in a new goroutine.
+func (c *clusterClient) DoMultiAsync(ctx context.Context, multi ...Completed) *ValkeyMultiFuture {
+	f := newMultiFuture(len(multi))
+	if len(multi) == 0 {
+		f.add(1)
+		f.resolve()
+		return f
+	}
+	if hasCrossSlot(multi) || hasBroadcast(multi) {
+		f.add(1)
+		go func() {
+			copy(f.resps, c.DoMulti(ctx, multi...))
+			f.resolve()
+		}()
+		return f
+	}
+	retries, hasInit, err := c.pickMulti(ctx, multi)
+	if err != nil {
+		fillResps(f.resps, err)
+		f.add(1)
+		f.resolve()
+		return f
+	}
+	if hasInit {
+		connretryp.Put(retries)
+		f.add(1)
+		go func() {
+			copy(f.resps, c.DoMulti(ctx, multi...))
+			f.resolve()
+		}()
+		return f
+	}
+	f.add(len(retries.m))
+	for cc, re := range retries.m {
+		delete(retries.m, cc)
+		resps := make([]ValkeyResult, len(re.commands))
+		cc.DoMultiAsync(ctx, resps, func() { c.settleMultiAsync(ctx, f, re, resps) }, re.commands...)
+	}
+	connretryp.Put(retries)
+	return f
+}
+
+// settleMultiAsync fills the results of the re into the f. It resends commands that need redirections or retries in a new goroutine.
+func (c *clusterClient) settleMultiAsync(ctx context.Context, f *ValkeyMultiFuture, re *retry, resps []ValkeyResult) {
+	var follows []int
+	for i, resp := range resps {
+		if c.settled(ctx, re.commands[i], resp) {
+			f.resps[re.cIndexes[i]] = c.recycle(re.commands[i], resp)
+		} else {
+			follows = append(follows, i)
+		}
+	}
+	if len(follows) == 0 {
+		retryp.Put(re)
+		f.resolve()
+		return
+	}
+	go func() {
+		delay := time.Duration(-1)
+		commands := make([]Completed, 0, len(follows))
+		cIndexes := make([]int, 0, len(follows))
+		for _, i := range follows {
+			cmd, resp := re.commands[i], resps[i]
+			if _, mode := c.shouldRefreshRetry(resp.Error(), ctx); mode == RedirectRetry && resp.NonValkeyError() != errConnExpired {
+				d := c.retryHandler.RetryDelay(1, cmd, resp.Error())
+				if d < 0 {
+					f.resps[re.cIndexes[i]] = c.recycle(cmd, resp)
+					continue
+				}
+				delay = max(delay, d)
+			}
+			commands = append(commands, cmd)
+			cIndexes = append(cIndexes, re.cIndexes[i])
+		}
+		if delay >= 0 {
+			c.retryHandler.WaitForRetry(ctx, delay)
+		}
+		if len(commands) != 0 {
+			for j, resp := range c.DoMulti(ctx, commands...) {
+				f.resps[cIndexes[j]] = resp
+			}
+		}
+		retryp.Put(re)
+		f.resolve()
+	}()
+}
+
 func (c *clusterClient) toReplica(cmd Completed) bool {
 	if c.opt.SendToReplicas != nil {
 		return c.opt.SendToReplicas(cmd)
@@ -692,18 +983,25 @@ func (c *clusterClient) _pickMulti(multi []Completed) (retries *connretry, init
 			slot := cmd.Slot()
 			if c.opt.SendToReplicas(cmd) {
 				if nodes := c.rslots[slot]; len(nodes) > 0 {
-					bm.Set(i)
-					rIndex := 0
-					if c.opt.ReadNodeSelector != nil {
-						rIndex = c.opt.ReadNodeSelector(slot, nodes)
-						if rIndex < 0 || rIndex >= len(nodes) {
-							rIndex = 0
-						} else if rIndex != 0 { // the default itor[i] is 0
+					if rIndex := c._pickReplica(slot, nodes); rIndex >= 0 {
+						bm.Set(i)
+						if j := c._reroute(slot, nodes[rIndex].conn, cmd); j >= 0 {
+							rIndex = j
+						}
+						if rIndex != 0 { // the default itor[i] is 0
 							itor[i] = rIndex
 						}
+						cc = nodes[rIndex].conn
+					} else {
+						cc = c.wslots[slot]
 					}
-					cc = nodes[rIndex].conn
 				}
+			} else if j := c._reroute(slot, c.wslots[slot], cmd); j >= 0 {
+				bm.Set(i)
+				if j != 0 {
+					itor[i] = j
+				}
+				cc = c.rslots[slot][j].conn
 			} else {
 				cc = c.wslots[slot]
 			}
@@ -938,6 +1236,12 @@ func (c *clusterClient) DoMulti(ctx context.Context, multi ...Completed) []Valke
 	if len(multi) == 0 {
 		return nil
 	}
+	if hasCrossSlot(multi) {
+		return c.splitMulti(ctx, multi)
+	}
+	if hasBroadcast(multi) {
+		return c.broadcastMulti(ctx, multi)
+	}
 
 	retries, hasInit, err := c.pickMulti(ctx, multi)
 	if err != nil {
@@ -1015,6 +1319,7 @@ retry:
 	if err != nil {
 		return newErrResult(err)
 	}
+	cc = c.reroute(cmd.Slot(), cc, Completed(cmd))
 	resp = cc.DoCache(ctx, cmd, ttl)
 	if resp.NonValkeyError() == errConnExpired {
 		goto retry
@@ -1055,6 +1360,11 @@ process:
 }
 
 func (c *clusterClient) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) (resp ValkeyResult) {
+	unsplit, err := unsplit(Completed(cmd))
+	if err != nil {
+		return newErrResult(err)
+	}
+	cmd = Cacheable(unsplit)
 	resp = c.doCache(ctx, cmd, ttl)
 	if err := resp.NonValkeyError(); err == nil || err == ErrDoCacheAborted {
 		cmds.PutCacheable(cmd)
@@ -1062,6 +1372,7 @@ func (c *clusterClient) DoCache(ctx context.Context, cmd Cacheable, ttl time.Dur
 	return resp
 }
 
+// askingMulti sends an ASKING before each command or transaction, since the ASKING only applies to the next one.
 func (c *clusterClient) askingMulti(cc conn, ctx context.Context, multi []Completed) *valkeyresults {
 	var inTx bool
 	commands := make([]Completed, 0, len(multi)*2)
@@ -1228,8 +1539,13 @@ func (c *clusterClient) _pickMultiCache(multi []CacheableTTL) *connretrycache {
 							rIndex = 0
 						}
 					}
+					if j := c._reroute(slot, nodes[rIndex].conn, Completed(cmd.Cmd)); j >= 0 {
+						rIndex = j
+					}
 					p = nodes[rIndex].conn
 				}
+			} else if j := c._reroute(slot, c.wslots[slot], Completed(cmd.Cmd)); j >= 0 {
+				p = c.rslots[slot][j].conn
 			} else {
 				p = c.wslots[slot]
 			}
@@ -1356,6 +1672,13 @@ func (c *clusterClient) DoMultiCache(ctx context.Context, multi ...CacheableTTL)
 	if len(multi) == 0 {
 		return nil
 	}
+	for i := range multi {
+		cmd, err := unsplit(Completed(multi[i].Cmd))
+		if err != nil {
+			return fillErrs(len(multi), err)
+		}
+		multi[i].Cmd = Cacheable(cmd)
+	}
 
 	retries, err := c.pickMultiCache(ctx, multi)
 	if err != nil {
@@ -1442,6 +1765,10 @@ ret:
 }
 
 func (c *clusterClient) DoStream(ctx context.Context, cmd Completed) ValkeyResultStream {
+	cmd, err := unsplit(cmd)
+	if err != nil {
+		return ValkeyResultStream{e: err}
+	}
 	cc, err := c.pick(ctx, cmd.Slot(), c.toReplica(cmd))
 	if err != nil {
 		return ValkeyResultStream{e: err}
@@ -1455,6 +1782,12 @@ func (c *clusterClient) DoMultiStream(ctx context.Context, multi ...Completed) M
 	if len(multi) == 0 {
 		return ValkeyResultStream{e: io.EOF}
 	}
+	for i := range multi {
+		var err error
+		if multi[i], err = unsplit(multi[i]); err != nil {
+			return ValkeyResultStream{e: err}
+		}
+	}
 	slot := multi[0].Slot()
 	repl := c.toReplica(multi[0])
 	for i := 1; i < len(multi); i++ {
@@ -1478,15 +1811,391 @@ func (c *clusterClient) DoMultiStream(ctx context.Context, multi ...Completed) M
 	return ret
 }
 
+// isBroadcast reports whether the keyless cmd should be sent to multiple nodes according to its request_policy.
+func isBroadcast(cmd Completed) bool {
+	return cmd.Slot() == cmds.InitSlot && (cmd.IsAllShards() || cmd.IsAllNodes())
+}
+
+// split sends the parts of the CrossSlot cmd to their slots concurrently,
+// and then merges their replies into one according to its response_policy.
+func (c *clusterClient) split(ctx context.Context, cmd Completed) ValkeyResult {
+	parts, indexes := cmds.SplitCrossSlot(cmd)
+	if len(parts) == 1 {
+		return c.settle(ctx, parts[0], nil, ValkeyResult{})
+	}
+	resps := c.DoMulti(ctx, parts...)
+	resp := merge(cmd.ResponsePolicy(), indexes, resps)
+	resultsp.Put(&valkeyresults{s: resps})
+	return resp
+}
+
+// splitMulti expands the CrossSlot commands in the multi into their parts, and merges the replies of the parts back.
+func (c *clusterClient) splitMulti(ctx context.Context, multi []Completed) []ValkeyResult {
+	expanded := make([]Completed, 0, len(multi)*2)
+	indexes := make([][][]int, len(multi))
+	for i, cmd := range multi {
+		if cmd.Slot() == cmds.CrossSlot {
+			var parts []Completed
+			parts, indexes[i] = cmds.SplitCrossSlot(cmd)
+			expanded = append(expanded, parts...)
+		} else {
+			expanded = append(expanded, cmd)
+		}
+	}
+	resps := c.DoMulti(ctx, expanded...)
+	results := resultsp.Get(len(multi), len(multi))
+	j := 0
+	for i, cmd := range multi {
+		if n := len(indexes[i]); n > 1 {
+			if results.s[i] = merge(cmd.ResponsePolicy(), indexes[i], resps[j:j+n]); results.s[i].NonValkeyError() == nil {
+				cmds.PutCompleted(cmd) // the parts are recycled by the DoMulti already.
+			}
+			j += n
+		} else {
+			results.s[i] = resps[j]
+			j++
+		}
+	}
+	resultsp.Put(&valkeyresults{s: resps})
+	return results.s
+}
+
+// unsplit returns the CrossSlot cmd with its slot resolved if all its keys belong to the same slot.
+func unsplit(cmd Completed) (Completed, error) {
+	if cmd.Slot() != cmds.CrossSlot {
+		return cmd, nil
+	}
+	parts, _ := cmds.SplitCrossSlot(cmd)
+	if len(parts) != 1 {
+		for _, p := range parts {
+			cmds.PutCompleted(p)
+		}
+		return cmd, errCrossSlot
+	}
+	return parts[0], nil
+}
+
+func hasCrossSlot(multi []Completed) bool {
+	for _, cmd := range multi {
+		if cmd.Slot() == cmds.CrossSlot {
+			return true
+		}
+	}
+	return false
+}
+
+// merge reassembles the replies of the parts of a split command. Non-array replies are aggregated
+// by the response_policy, while array replies are placed in the order of their keys in the split command.
+func merge(policy uint16, indexes [][]int, resps []ValkeyResult) ValkeyResult {
+	if policy != cmds.DefaultResponse {
+		return aggregate(policy, resps)
+	}
+	n := 0
+	for _, idx := range indexes {
+		n += len(idx)
+	}
+	values := make([]ValkeyMessage, n)
+	for i, resp := range resps {
+		if resp.Error() != nil {
+			return resp
+		}
+		arr, err := resp.ToArray()
+		if err != nil {
+			return newErrResult(err)
+		}
+		if len(arr) != len(indexes[i]) {
+			return newErrResult(errSplitReplyLength)
+		}
+		for j, v := range arr {
+			values[indexes[i][j]] = v
+		}
+	}
+	return newResult(slicemsg(resps[0].val.typ, values), nil)
+}
+
+// hasBroadcast reports whether the multi has broadcast commands outside of transactions.
+func hasBroadcast(multi []Completed) bool {
+	tx := false
+	for _, cmd := range multi {
+		switch {
+		case isMulti(cmd):
+			tx = true
+		case isExec(cmd):
+			tx = false
+		case !tx && isBroadcast(cmd):
+			return true
+		}
+	}
+	return false
+}
+
+// broadcastMulti broadcasts the broadcast commands outside of transactions in the multi concurrently, and sends the rest with the DoMulti.
+// Commands inside transactions are sent along with their transactions instead.
+func (c *clusterClient) broadcastMulti(ctx context.Context, multi []Completed) []ValkeyResult {
+	rest := make([]Completed, 0, len(multi))
+	indexes := make([]int, 0, len(multi))
+	results := resultsp.Get(len(multi), len(multi))
+	var wg sync.WaitGroup
+	tx := false
+	for i, cmd := range multi {
+		if isMulti(cmd) {
+			tx = true
+		} else if isExec(cmd) {
+			tx = false
+		} else if !tx && isBroadcast(cmd) {
+			wg.Add(1)
+			go func(i int, cmd Completed) {
+				defer wg.Done()
+				results.s[i] = c.recycle(cmd, c.broadcast(ctx, cmd))
+			}(i, cmd)
+			continue
+		}
+		rest = append(rest, cmd)
+		indexes = append(indexes, i)
+	}
+	if len(rest) != 0 {
+		resps := c.DoMulti(ctx, rest...)
+		for i, resp := range resps {
+			results.s[indexes[i]] = resp
+		}
+		resultsp.Put(&valkeyresults{s: resps})
+	}
+	wg.Wait()
+	return results.s
+}
+
+// broadcast sends the cmd to all primaries or all nodes according to its request_policy,
+// and then aggregates their replies into one according to its response_policy.
+func (c *clusterClient) broadcast(ctx context.Context, cmd Completed) ValkeyResult {
+	ccs := c.broadcastConns(cmd.IsAllNodes())
+	if len(ccs) == 0 {
+		if err := c.refresh(ctx); err != nil {
+			return newErrResult(err)
+		}
+		if ccs = c.broadcastConns(cmd.IsAllNodes()); len(ccs) == 0 {
+			return newErrResult(ErrNoSlot)
+		}
+	}
+	resps := make([]ValkeyResult, len(ccs))
+	var wg sync.WaitGroup
+	wg.Add(len(ccs))
+	for i, cc := range ccs {
+		go func(i int, cc conn) {
+			defer wg.Done()
+			for resps[i] = cc.Do(ctx, cmd); resps[i].NonValkeyError() == errConnExpired; {
+				resps[i] = cc.Do(ctx, cmd)
+			}
+		}(i, cc)
+	}
+	wg.Wait()
+	return aggregate(cmd.ResponsePolicy(), resps)
+}
+
+// broadcastConns returns the conns of all nodes if the all is true, otherwise returns the conns of all primaries.
+func (c *clusterClient) broadcastConns(all bool) (ccs []conn) {
+	c.mu.RLock()
+	if all {
+		ccs = make([]conn, 0, len(c.conns))
+		for _, cc := range c.conns {
+			if !cc.hidden {
+				ccs = append(ccs, cc.conn)
+			}
+		}
+	} else {
+		ccs = make([]conn, 0, len(c.pslots))
+		for _, slot := range c.pslots {
+			if cc := c.wslots[slot]; cc != nil {
+				ccs = append(ccs, cc)
+			}
+		}
+	}
+	c.mu.RUnlock()
+	return ccs
+}
+
+// aggregate merges the replies of a broadcast command according to the response_policy.
+// Ref: https://valkey.io/topics/command-tips/
+func aggregate(policy uint16, resps []ValkeyResult) ValkeyResult {
+	switch policy {
+	case cmds.OneSucceeded:
+		for _, resp := range resps {
+			if resp.Error() == nil {
+				return resp
+			}
+		}
+		return resps[0]
+	case cmds.AllSucceeded:
+		for _, resp := range resps {
+			if resp.Error() != nil {
+				return resp
+			}
+		}
+		return resps[0]
+	}
+	for _, resp := range resps {
+		if err := resp.Error(); err != nil && !IsValkeyNil(err) {
+			return resp
+		}
+	}
+	switch policy {
+	case cmds.AggSum, cmds.AggMin, cmds.AggMax:
+		var agg int64
+		for i, resp := range resps {
+			v, err := resp.ToInt64()
+			if err != nil {
+				return newErrResult(err)
+			}
+			switch {
+			case i == 0, policy == cmds.AggSum:
+				agg += v
+			case policy == cmds.AggMin:
+				agg = min(agg, v)
+			case policy == cmds.AggMax:
+				agg = max(agg, v)
+			}
+		}
+		return newResult(ValkeyMessage{typ: typeInteger, intlen: agg}, nil)
+	case cmds.AggLogicalAnd, cmds.AggLogicalOr:
+		msgs := make([]ValkeyMessage, len(resps))
+		for i, resp := range resps {
+			msgs[i] = resp.val
+		}
+		if resps[0].val.IsInt64() {
+			return logical(policy, msgs)
+		}
+		// element-wise for array replies, such as SCRIPT EXISTS.
+		arrs := make([][]ValkeyMessage, len(resps))
+		for i, resp := range resps {
+			arr, err := resp.ToArray()
+			if err != nil {
+				return newErrResult(err)
+			}
+			if arrs[i] = arr; len(arr) != len(arrs[0]) {
+				return newErrResult(errBroadcastReplyLength)
+			}
+		}
+		values := make([]ValkeyMessage, len(arrs[0]))
+		for j := range values {
+			for i := range arrs {
+				msgs[i] = arrs[i][j]
+			}
+			resp := logical(policy, msgs)
+			if resp.Error() != nil {
+				return resp
+			}
+			values[j] = resp.val
+		}
+		return newResult(slicemsg(resps[0].val.typ, values), nil)
+	}
+	var values []ValkeyMessage
+	for _, resp := range resps {
+		arr, err := resp.ToArray()
+		if err != nil {
+			// not an array reply, return the first non-nil one.
+			for _, resp := range resps {
+				if resp.Error() == nil {
+					return resp
+				}
+			}
+			return resps[0]
+		}
+		values = append(values, arr...)
+	}
+	return newResult(slicemsg(resps[0].val.typ, values), nil)
+}
+
+func logical(policy uint16, msgs []ValkeyMessage) ValkeyResult {
+	agg := policy == cmds.AggLogicalAnd
+	for _, m := range msgs {
+		v, err := m.AsInt64()
+		if err != nil {
+			return newErrResult(err)
+		}
+		if policy == cmds.AggLogicalAnd {
+			agg = agg && v != 0
+		} else {
+			agg = agg || v != 0
+		}
+	}
+	if agg {
+		return newResult(ValkeyMessage{typ: typeInteger, intlen: 1}, nil)
+	}
+	return newResult(ValkeyMessage{typ: typeInteger, intlen: 0}, nil)
+}
+
+// ShardScanner returns a Scanner that runs the SCAN family command built by the build against every shard exactly once.
+// If the key is not empty, only the shard owning the key is scanned.
+// The shards are the ones known when the scan starts. Slots migrated between shards in the middle of the scan are not reconciled,
+// so their keys can be missed or returned twice, and shards added in the middle are not scanned.
+func (c *clusterClient) ShardScanner(ctx context.Context, key string, build func(cursor uint64) []string) *Scanner {
+	var slots []uint16
+	return newShardScanner(func(i int, cursor uint64) (e ScanEntry, ok bool, err error) {
+		if i == 0 && cursor == 0 { // (re)start from the first shard
+			if key != "" {
+				slots = []uint16{cmds.Slot(key)}
+			} else if slots, err = c.shards(ctx); err != nil {
+				return e, false, err
+			}
+		}
+		if i >= len(slots) {
+			return e, false, nil
+		}
+		e, err = c.scan(ctx, slots[i], cursor, build)
+		return e, true, err
+	})
+}
+
+// shards returns the first slot of each shard.
+func (c *clusterClient) shards(ctx context.Context) ([]uint16, error) {
+	c.mu.RLock()
+	slots := c.pslots
+	c.mu.RUnlock()
+	if len(slots) == 0 {
+		if err := c.refresh(ctx); err != nil {
+			return nil, err
+		}
+		c.mu.RLock()
+		slots = c.pslots
+		c.mu.RUnlock()
+		if len(slots) == 0 {
+			return nil, ErrNoSlot
+		}
+	}
+	return slots, nil
+}
+
+// scan sends the SCAN family command with the cursor to the primary of the slot, regardless of the SendToReplicas.
+// If the primary is unreachable and the shard has failed over, the scan is resumed on the new primary with the same cursor.
+func (c *clusterClient) scan(ctx context.Context, slot uint16, cursor uint64, build func(cursor uint64) []string) (e ScanEntry, err error) {
+	for {
+		var primary conn
+		if primary, err = c.pick(ctx, slot, false); err != nil {
+			return e, err
+		}
+		// the cmd is not read-only, so that neither the settle nor the circuit breaker reroutes it to a replica.
+		cmd := cmds.NewSlotCompleted(build(cursor), slot)
+		resp := c.settle(ctx, cmd, primary, primary.Do(ctx, cmd))
+		if resp.NonValkeyError() == nil {
+			cmds.PutCompleted(cmd)
+		}
+		if e, err = resp.AsScanEntry(); err == nil || resp.NonValkeyError() == nil || ctx.Err() != nil {
+			return e, err
+		}
+		if c.refresh(ctx) != nil || c._pick(slot, false) == primary {
+			return e, err
+		}
+	}
+}
+
 func (c *clusterClient) Dedicated(fn func(DedicatedClient) error) (err error) {
-	dcc := &dedicatedClusterClient{cmd: c.cmd, client: c, slot: cmds.NoSlot, retry: c.retry, retryHandler: c.retryHandler}
+	dcc := &dedicatedClusterClient{cmd: cmds.NewBuilder(cmds.InitSlot), client: c, slot: cmds.NoSlot, retry: c.retry, retryHandler: c.retryHandler}
 	err = fn(dcc)
 	dcc.release()
 	return err
 }
 
 func (c *clusterClient) Dedicate() (DedicatedClient, func()) {
-	dcc := &dedicatedClusterClient{cmd: c.cmd, client: c, slot: cmds.NoSlot, retry: c.retry, retryHandler: c.retryHandler}
+	dcc := &dedicatedClusterClient{cmd: cmds.NewBuilder(cmds.InitSlot), client: c, slot: cmds.NoSlot, retry: c.retry, retryHandler: c.retryHandler}
 	return dcc, dcc.release
 }
 
@@ -1503,6 +2212,31 @@ func (c *clusterClient) Nodes() map[string]Client {
 	return _nodes
 }
 
+// SlotOwner returns the address of the primary serving the slot.
+func (c *clusterClient) SlotOwner(slot uint16) (addr string, ok bool) {
+	if slot >= 16384 {
+		return "", false
+	}
+	c.mu.RLock()
+	if cc := c.wslots[slot]; cc != nil {
+		addr, ok = cc.Addr(), true
+	}
+	c.mu.RUnlock()
+	return addr, ok
+}
+
+func (c *clusterClient) Stats() map[string]NodeStats {
+	c.mu.RLock()
+	stats := make(map[string]NodeStats, len(c.conns))
+	for addr, cc := range c.conns {
+		if !cc.hidden {
+			stats[addr] = cc.conn.Stats()
+		}
+	}
+	c.mu.RUnlock()
+	return stats
+}
+
 func (c *clusterClient) Mode() ClientMode {
 	return ClientModeCluster
 }
@@ -1510,6 +2244,7 @@ func (c *clusterClient) Mode() ClientMode {
 func (c *clusterClient) Close() {
 	if atomic.CompareAndSwapUint32(&c.stop, 0, 1) {
 		close(c.stopCh)
+		c.lags.Close()
 	}
 
 	c.mu.RLock()
@@ -1519,20 +2254,42 @@ func (c *clusterClient) Close() {
 	c.mu.RUnlock()
 }
 
+func (c *clusterClient) CloseWithContext(ctx context.Context) error {
+	if atomic.CompareAndSwapUint32(&c.stop, 0, 1) {
+		close(c.stopCh)
+		c.lags.Close()
+	}
+
+	c.mu.RLock()
+	conns := make([]conn, 0, len(c.conns))
+	for _, cc := range c.conns {
+		conns = append(conns, cc.conn)
+	}
+	c.mu.RUnlock()
+	return closeWithContext(ctx, conns)
+}
+
 func (c *clusterClient) shouldRefreshRetry(err error, ctx context.Context) (addr string, mode RedirectMode) {
-	if err != nil && err != Nil && err != ErrDoCacheAborted && atomic.LoadUint32(&c.stop) == 0 {
+	if err != nil && err != Nil && err != ErrDoCacheAborted && err != ErrCircuitOpen && err != ErrOverloaded && atomic.LoadUint32(&c.stop) == 0 {
+		var migrating bool
 		if err, ok := err.(*ValkeyError); ok {
 			if addr, ok = err.IsMoved(); ok {
 				mode = RedirectMove
 			} else if addr, ok = err.IsAsk(); ok {
 				mode = RedirectAsk
-			} else if err.IsClusterDown() || err.IsTryAgain() || err.IsLoading() {
+				migrating = true
+			} else if err.IsTryAgain() {
+				mode = RedirectRetry
+				migrating = true
+			} else if err.IsClusterDown() || err.IsLoading() {
 				mode = RedirectRetry
 			}
 		} else if ctx.Err() == nil {
 			mode = RedirectRetry
 		}
-		if mode != RedirectNone {
+		// ASK and TRYAGAIN are replied during a slot migration, which does not change the topology until it is done
+		// and then replies MOVED. Refreshing on them only causes refresh storms during a large resharding.
+		if mode != RedirectNone && !migrating {
 			c.lazyRefresh()
 		}
 	}
@@ -1608,6 +2365,10 @@ func (c *dedicatedClusterClient) B() Builder {
 }
 
 func (c *dedicatedClusterClient) Do(ctx context.Context, cmd Completed) (resp ValkeyResult) {
+	cmd, err := unsplit(cmd)
+	if err != nil {
+		return newErrResult(err)
+	}
 	attempts := 1
 retry:
 	if w, err := c.acquire(ctx, cmd.Slot()); err != nil {
@@ -1637,6 +2398,16 @@ func (c *dedicatedClusterClient) DoMulti(ctx context.Context, multi ...Completed
 	if len(multi) == 0 {
 		return nil
 	}
+	for i := range multi {
+		var err error
+		if multi[i], err = unsplit(multi[i]); err != nil {
+			resp = resultsp.Get(len(multi), len(multi)).s
+			for i := range resp {
+				resp[i] = newErrResult(err)
+			}
+			return resp
+		}
+	}
 	slot := chooseSlot(multi)
 	if slot == cmds.NoSlot {
 		panic(panicMsgCxSlot)
@@ -1683,6 +2454,9 @@ func (c *dedicatedClusterClient) Receive(ctx context.Context, subscribe Complete
 		w        wire
 		attempts = 1
 	)
+	if subscribe, err = unsplit(subscribe); err != nil {
+		return err
+	}
 retry:
 	if w, err = c.acquire(ctx, subscribe.Slot()); err == nil {
 		err = w.Receive(ctx, subscribe, fn)
@@ -1750,6 +2524,21 @@ func (c *dedicatedClusterClient) Close() {
 	c.release()
 }
 
+func (c *dedicatedClusterClient) CloseWithContext(ctx context.Context) (err error) {
+	c.mu.Lock()
+	if p := c.pshks; p != nil {
+		c.pshks = nil
+		p.close <- ErrClosing
+		close(p.close)
+	}
+	if c.wire != nil {
+		err = c.wire.CloseWithContext(ctx)
+	}
+	c.mu.Unlock()
+	c.release()
+	return err
+}
+
 type RedirectMode int
 
 const (
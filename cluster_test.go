@@ -746,6 +746,49 @@ func TestClusterClientInit(t *testing.T) {
 		}
 	})
 
+	t.Run("Init SelectDB not supported", func(t *testing.T) {
+		v := ValkeyError(strmsg('-', "ERR SELECT is not allowed in cluster mode"))
+		if _, err := newClusterClient(
+			&ClientOption{InitAddress: []string{":0"}, SelectDB: 1},
+			func(dst string, opt *ClientOption) conn {
+				return &mockConn{DialFn: func() error { return &v }}
+			},
+			newRetryer(defaultRetryDelayFn),
+		); !errors.Is(err, ErrSelectDBNotSupported) {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Init SelectDB on all nodes", func(t *testing.T) {
+		var mu sync.Mutex
+		dbs := make(map[string]int)
+		client, err := newClusterClient(
+			&ClientOption{InitAddress: []string{":0"}, SelectDB: 2, SendToReplicas: func(cmd Completed) bool { return true }},
+			func(dst string, opt *ClientOption) conn {
+				mu.Lock()
+				dbs[dst] = opt.SelectDB
+				mu.Unlock()
+				return &mockConn{DoFn: func(cmd Completed) ValkeyResult { return slotsMultiResp }}
+			},
+			newRetryer(defaultRetryDelayFn),
+		)
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		defer client.Close()
+		client.redirectOrNew("127.0.9.1:0", nil, 0, RedirectMove)
+		mu.Lock()
+		defer mu.Unlock()
+		if len(dbs) != 6 {
+			t.Fatalf("unexpected nodes %v", dbs)
+		}
+		for addr, db := range dbs {
+			if db != 2 {
+				t.Fatalf("unexpected db %v of %v", db, addr)
+			}
+		}
+	})
+
 	t.Run("Refresh err", func(t *testing.T) {
 		v := errors.New("refresh err")
 		if _, err := newClusterClient(
@@ -1052,6 +1095,13 @@ func TestClusterClientInit(t *testing.T) {
 				t.Fatalf("unexpected nodes %v", nodes)
 			}
 
+			stats := client.Stats()
+			_, ok = stats["127.0.0.1:0"]
+			_, ok2 = stats["127.0.1.1:1"]
+			if len(stats) != 2 || !ok || !ok2 {
+				t.Fatalf("unexpected stats %v", stats)
+			}
+
 			atomic.AddInt64(num, 1)
 
 			if err := client.refresh(context.Background()); err != nil {
@@ -1961,6 +2011,21 @@ func TestClusterClient(t *testing.T) {
 		}
 	})
 
+	t.Run("Delegate CloseWithContext", func(t *testing.T) {
+		e := errors.New("ctx")
+		m.CloseCtxFn = func(ctx context.Context) error { return e }
+		defer func() { m.CloseCtxFn = nil }()
+		if err := client.CloseWithContext(context.Background()); err != e {
+			t.Fatalf("CloseWithContext is not delegated")
+		}
+		select {
+		case _, ok := <-client.stopCh:
+			if ok {
+				t.Fatalf("stopCh should be closed")
+			}
+		}
+	})
+
 	t.Run("Delegate Close", func(t *testing.T) {
 		once := sync.Once{}
 		called := make(chan struct{})
@@ -2297,6 +2362,24 @@ func TestClusterClient(t *testing.T) {
 		}
 	})
 
+	t.Run("Dedicate Delegate Release On CloseWithContext", func(t *testing.T) {
+		stored := 0
+		e := errors.New("ctx")
+		w := &mockWire{CloseCtxFn: func(ctx context.Context) error { return e }}
+		m.AcquireFn = func() wire { return w }
+		m.StoreFn = func(ww wire) { stored++ }
+		c, _ := client.Dedicate()
+		c.Do(context.Background(), c.B().Get().Key("a").Build())
+
+		if err := c.CloseWithContext(context.Background()); err != e {
+			t.Fatalf("unexpected err %v", err)
+		}
+
+		if stored != 1 {
+			t.Fatalf("unexpected stored count %v", stored)
+		}
+	})
+
 	t.Run("Dedicate Delegate No Duplicate Release", func(t *testing.T) {
 		stored := 0
 		w := &mockWire{}
@@ -4412,6 +4495,68 @@ func TestClusterClientErr(t *testing.T) {
 		}
 	})
 
+	t.Run("slot moved DoAsync", func(t *testing.T) {
+		var count int64
+		client, err := newClusterClient(
+			&ClientOption{InitAddress: []string{":0"}},
+			func(dst string, opt *ClientOption) conn {
+				return &mockConn{DoFn: func(cmd Completed) ValkeyResult {
+					if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+						return slotsMultiResp
+					}
+					if atomic.AddInt64(&count, 1) <= 3 {
+						return newResult(strmsg('-', "MOVED 0 :1"), nil)
+					}
+					return newResult(strmsg('+', "b"), nil)
+				}}
+			},
+			newRetryer(defaultRetryDelayFn),
+		)
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if v, err := client.DoAsync(context.Background(), client.B().Get().Key("a").Build()).Wait(context.Background()).ToString(); err != nil || v != "b" {
+			t.Fatalf("unexpected resp %v %v", v, err)
+		}
+	})
+
+	t.Run("slot moved DoMultiAsync", func(t *testing.T) {
+		var count int64
+		client, err := newClusterClient(
+			&ClientOption{InitAddress: []string{":0"}},
+			func(dst string, opt *ClientOption) conn {
+				return &mockConn{DoFn: func(cmd Completed) ValkeyResult {
+					return slotsMultiResp
+				}, DoMultiFn: func(multi ...Completed) *valkeyresults {
+					ret := make([]ValkeyResult, len(multi))
+					for i := range ret {
+						if multi[i].Commands()[1] == "a" && atomic.AddInt64(&count, 1) <= 3 {
+							ret[i] = newResult(strmsg('-', "MOVED 0 :1"), nil)
+						} else {
+							ret[i] = newResult(strmsg('+', multi[i].Commands()[1]), nil)
+						}
+					}
+					return &valkeyresults{s: ret}
+				}}
+			},
+			newRetryer(defaultRetryDelayFn),
+		)
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		keys := []string{"a", "b", "c", "d"}
+		multi := make([]Completed, len(keys))
+		for i, k := range keys {
+			multi[i] = client.B().Get().Key(k).Build()
+		}
+		resps := client.DoMultiAsync(context.Background(), multi...).Wait(context.Background())
+		for i, k := range keys {
+			if v, err := resps[i].ToString(); err != nil || v != k {
+				t.Fatalf("unexpected resp %v %v", v, err)
+			}
+		}
+	})
+
 	t.Run("slot moved DoMulti transactions", func(t *testing.T) {
 		var count int64
 		client, err := newClusterClient(
@@ -11410,3 +11555,911 @@ func clusterClientWithConnCount(n int) *clusterClient {
 	}
 	return &clusterClient{conns: conns}
 }
+
+func TestClusterClient_CircuitBreakerRerouteReadOnly(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+
+	reply := func(addr string) func(cmd Completed) ValkeyResult {
+		return func(cmd Completed) ValkeyResult {
+			return newResult(strmsg('+', addr+" "+strings.Join(cmd.Commands(), " ")), nil)
+		}
+	}
+	primaryNodeConn := &mockConn{
+		DoFn: func(cmd Completed) ValkeyResult {
+			if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+				return slotsMultiResp
+			}
+			return newErrResult(ErrCircuitOpen)
+		},
+		DoMultiFn: func(multi ...Completed) *valkeyresults {
+			return &valkeyresults{s: fillErrs(len(multi), ErrCircuitOpen)}
+		},
+		DoCacheFn: func(cmd Cacheable, ttl time.Duration) ValkeyResult {
+			return newErrResult(ErrCircuitOpen)
+		},
+		CircuitFn: func() CircuitState { return CircuitOpen },
+	}
+	replicaNodeConn := &mockConn{
+		DoFn: reply("replica"),
+		DoMultiFn: func(multi ...Completed) *valkeyresults {
+			resps := make([]ValkeyResult, len(multi))
+			for i, cmd := range multi {
+				resps[i] = reply("replica")(cmd)
+			}
+			return &valkeyresults{s: resps}
+		},
+		DoCacheFn: func(cmd Cacheable, ttl time.Duration) ValkeyResult {
+			return reply("replica")(Completed(cmd))
+		},
+		DoMultiCacheFn: func(multi ...CacheableTTL) *valkeyresults {
+			resps := make([]ValkeyResult, len(multi))
+			for i, cmd := range multi {
+				resps[i] = reply("replica")(Completed(cmd.Cmd))
+			}
+			return &valkeyresults{s: resps}
+		},
+	}
+
+	client, err := newClusterClient(
+		&ClientOption{
+			InitAddress: []string{"127.0.0.1:0"},
+			SendToReplicas: func(cmd Completed) bool {
+				return false
+			},
+			ReadNodeSelector: func(slot uint16, nodes []NodeInfo) int {
+				return 0
+			},
+			CircuitBreaker: &CircuitBreakerOption{RerouteReadOnly: true},
+		},
+		func(dst string, opt *ClientOption) conn {
+			if dst == "127.0.0.1:0" || dst == "127.0.2.1:0" { // primary nodes
+				return primaryNodeConn
+			}
+			return replicaNodeConn
+		},
+		newRetryer(defaultRetryDelayFn),
+	)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+
+	t.Run("Do ReadOnly", func(t *testing.T) {
+		if v, err := client.Do(context.Background(), client.B().Get().Key("Do").Build()).ToString(); err != nil || v != "replica GET Do" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("Do Write", func(t *testing.T) {
+		if err := client.Do(context.Background(), client.B().Set().Key("Do").Value("v").Build()).Error(); err != ErrCircuitOpen {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("DoMulti", func(t *testing.T) {
+		resps := client.DoMulti(context.Background(),
+			client.B().Get().Key("K1{a}").Build(),
+			client.B().Set().Key("K2{a}").Value("v").Build(),
+			client.B().Get().Key("K1{b}").Build(),
+		)
+		if v, err := resps[0].ToString(); err != nil || v != "replica GET K1{a}" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if err := resps[1].Error(); err != ErrCircuitOpen {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if v, err := resps[2].ToString(); err != nil || v != "replica GET K1{b}" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("DoCache", func(t *testing.T) {
+		if v, err := client.DoCache(context.Background(), client.B().Get().Key("DoCache").Cache(), 100).ToString(); err != nil || v != "replica GET DoCache" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("DoMultiCache", func(t *testing.T) {
+		resps := client.DoMultiCache(context.Background(),
+			CT(client.B().Get().Key("K1{a}").Cache(), 100),
+			CT(client.B().Get().Key("K1{b}").Cache(), 100),
+		)
+		for i, key := range []string{"K1{a}", "K1{b}"} {
+			if v, err := resps[i].ToString(); err != nil || v != "replica GET "+key {
+				t.Fatalf("unexpected response %v %v", v, err)
+			}
+		}
+	})
+}
+
+func TestClusterClientScanner(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+
+	setup := func(slots func() ValkeyResult, scan func(addr string, cmd []string) ValkeyResult) *clusterClient {
+		client, err := newClusterClient(
+			&ClientOption{InitAddress: []string{"127.0.0.1:0"}, DisableRetry: true},
+			func(dst string, opt *ClientOption) conn {
+				return &mockConn{
+					DoFn: func(cmd Completed) ValkeyResult {
+						if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+							return slots()
+						}
+						return scan(dst, cmd.Commands())
+					},
+					AddrFn: func() string { return dst },
+				}
+			},
+			newRetryer(defaultRetryDelayFn),
+		)
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return client
+	}
+
+	t.Run("Scan every primary once", func(t *testing.T) {
+		var mu sync.Mutex
+		calls := map[string][]string{}
+		client := setup(func() ValkeyResult { return slotsMultiResp }, func(addr string, cmd []string) ValkeyResult {
+			mu.Lock()
+			calls[addr] = append(calls[addr], strings.Join(cmd, " "))
+			mu.Unlock()
+			switch addr + " " + strings.Join(cmd, " ") {
+			case "127.0.0.1:0 SCAN 0 MATCH k* COUNT 5 TYPE hash":
+				return scanResp("7", "a")
+			case "127.0.0.1:0 SCAN 7 MATCH k* COUNT 5 TYPE hash":
+				return scanResp("0", "b")
+			case "127.0.2.1:0 SCAN 0 MATCH k* COUNT 5 TYPE hash":
+				return scanResp("0", "c")
+			}
+			t.Errorf("unexpected command %v to %v", cmd, addr)
+			return newErrResult(errors.New("unexpected"))
+		})
+		defer client.Close()
+
+		scanner := ScanAll(client, context.Background(), ScanArgs{Match: "k*", Count: 5, Type: "hash"})
+		for i := 0; i < 2; i++ { // the scanner can be iterated again
+			var keys []string
+			for k := range scanner.Iter() {
+				keys = append(keys, k)
+			}
+			if err := scanner.Err(); err != nil || !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+				t.Fatalf("unexpected keys %v %v", keys, err)
+			}
+		}
+		if len(calls) != 2 || len(calls["127.0.0.1:0"]) != 4 || len(calls["127.0.2.1:0"]) != 2 {
+			t.Fatalf("unexpected calls %v", calls)
+		}
+	})
+
+	t.Run("Resume on the new primary after failover", func(t *testing.T) {
+		var failover atomic.Bool
+		client := setup(func() ValkeyResult {
+			if failover.Load() {
+				return singleSlotResp2
+			}
+			return singleSlotResp
+		}, func(addr string, cmd []string) ValkeyResult {
+			switch addr + " " + strings.Join(cmd, " ") {
+			case "127.0.0.1:0 SCAN 0":
+				return scanResp("3", "a")
+			case "127.0.0.1:0 SCAN 3":
+				failover.Store(true)
+				return newErrResult(io.EOF)
+			case "127.0.3.1:3 SCAN 3":
+				return scanResp("0", "b")
+			}
+			t.Errorf("unexpected command %v to %v", cmd, addr)
+			return newErrResult(errors.New("unexpected"))
+		})
+		defer client.Close()
+
+		scanner := ScanAll(client, context.Background(), ScanArgs{})
+		var keys []string
+		for k := range scanner.Iter() {
+			keys = append(keys, k)
+		}
+		if err := scanner.Err(); err != nil || !reflect.DeepEqual(keys, []string{"a", "b"}) {
+			t.Fatalf("unexpected keys %v %v", keys, err)
+		}
+	})
+
+	t.Run("Return error without failover", func(t *testing.T) {
+		client := setup(func() ValkeyResult { return singleSlotResp }, func(addr string, cmd []string) ValkeyResult {
+			return newErrResult(io.EOF)
+		})
+		defer client.Close()
+
+		scanner := ScanAll(client, context.Background(), ScanArgs{})
+		for range scanner.Iter() {
+			t.Fatalf("should not yield")
+		}
+		if err := scanner.Err(); err != io.EOF {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Scan primaries regardless of SendToReplicas", func(t *testing.T) {
+		client, err := newClusterClient(
+			&ClientOption{
+				InitAddress:     []string{"127.0.0.1:0"},
+				DisableRetry:    true,
+				SendToReplicas:  func(cmd Completed) bool { return true },
+				ReplicaSelector: func(slot uint16, replicas []NodeInfo) int { return 0 },
+			},
+			func(dst string, opt *ClientOption) conn {
+				return &mockConn{
+					DoFn: func(cmd Completed) ValkeyResult {
+						if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+							return slotsMultiResp
+						}
+						switch dst + " " + strings.Join(cmd.Commands(), " ") {
+						case "127.0.0.1:0 SCAN 0":
+							return scanResp("0", "a")
+						case "127.0.2.1:0 SCAN 0":
+							return scanResp("0", "b")
+						}
+						t.Errorf("unexpected command %v to %v", cmd.Commands(), dst)
+						return newErrResult(errors.New("unexpected"))
+					},
+					AddrFn: func() string { return dst },
+				}
+			},
+			newRetryer(defaultRetryDelayFn),
+		)
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		defer client.Close()
+
+		scanner := ScanAll(client, context.Background(), ScanArgs{})
+		var keys []string
+		for k := range scanner.Iter() {
+			keys = append(keys, k)
+		}
+		if err := scanner.Err(); err != nil || !reflect.DeepEqual(keys, []string{"a", "b"}) {
+			t.Fatalf("unexpected keys %v %v", keys, err)
+		}
+	})
+
+	t.Run("HScanAll only scans the shard of the key", func(t *testing.T) {
+		client := setup(func() ValkeyResult { return slotsMultiResp }, func(addr string, cmd []string) ValkeyResult {
+			switch addr + " " + strings.Join(cmd, " ") {
+			case "127.0.2.1:0 HSCAN a 0":
+				return scanResp("0", "f", "v")
+			}
+			t.Errorf("unexpected command %v to %v", cmd, addr)
+			return newErrResult(errors.New("unexpected"))
+		})
+		defer client.Close()
+
+		scanner := HScanAll(client, context.Background(), "a", ScanArgs{})
+		fields := map[string]string{}
+		for f, v := range scanner.Iter2() {
+			fields[f] = v
+		}
+		if err := scanner.Err(); err != nil || !reflect.DeepEqual(fields, map[string]string{"f": "v"}) {
+			t.Fatalf("unexpected fields %v %v", fields, err)
+		}
+	})
+}
+
+func TestClusterClientBroadcast(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+
+	setup := func(fn func(addr string, cmd []string) ValkeyResult) (*clusterClient, *sync.Map) {
+		calls := &sync.Map{}
+		client, err := newClusterClient(
+			&ClientOption{InitAddress: []string{"127.0.0.1:0"}},
+			func(dst string, opt *ClientOption) conn {
+				do := func(cmd Completed) ValkeyResult {
+					if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+						return slotsMultiResp
+					}
+					n, _ := calls.LoadOrStore(dst, new(atomic.Int32))
+					n.(*atomic.Int32).Add(1)
+					return fn(dst, cmd.Commands())
+				}
+				return &mockConn{
+					DoFn: do,
+					DoMultiFn: func(multi ...Completed) *valkeyresults {
+						resps := make([]ValkeyResult, len(multi))
+						for i, cmd := range multi {
+							resps[i] = do(cmd)
+						}
+						return &valkeyresults{s: resps}
+					},
+				}
+			},
+			newRetryer(defaultRetryDelayFn),
+		)
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return client, calls
+	}
+	count := func(calls *sync.Map) (n int) {
+		calls.Range(func(_, _ any) bool { n++; return true })
+		return n
+	}
+
+	t.Run("all_shards agg_sum", func(t *testing.T) {
+		client, calls := setup(func(addr string, cmd []string) ValkeyResult {
+			if addr == "127.0.0.1:0" {
+				return newResult(ValkeyMessage{typ: ':', intlen: 1}, nil)
+			}
+			return newResult(ValkeyMessage{typ: ':', intlen: 2}, nil)
+		})
+		defer client.Close()
+		if v, err := client.Do(context.Background(), client.B().Dbsize().Build()).ToInt64(); err != nil || v != 3 {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if n := count(calls); n != 2 {
+			t.Fatalf("unexpected nodes %v", n)
+		}
+	})
+
+	t.Run("all_shards all_succeeded", func(t *testing.T) {
+		client, _ := setup(func(addr string, cmd []string) ValkeyResult {
+			if addr == "127.0.2.1:0" {
+				return newResult(strmsg('-', "ERR failed"), nil)
+			}
+			return newResult(strmsg('+', "OK"), nil)
+		})
+		defer client.Close()
+		if err := client.Do(context.Background(), client.B().Flushall().Build()).Error(); err == nil || err.Error() != "failed" {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("all_shards one_succeeded", func(t *testing.T) {
+		client, _ := setup(func(addr string, cmd []string) ValkeyResult {
+			if addr == "127.0.2.1:0" {
+				return newResult(strmsg('-', "NOTBUSY No scripts in execution right now."), nil)
+			}
+			return newResult(strmsg('+', "OK"), nil)
+		})
+		defer client.Close()
+		if v, err := client.Do(context.Background(), client.B().ScriptKill().Build()).ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("all_shards agg_logical_and", func(t *testing.T) {
+		client, _ := setup(func(addr string, cmd []string) ValkeyResult {
+			if addr == "127.0.2.1:0" {
+				return newResult(slicemsg('*', []ValkeyMessage{{typ: ':', intlen: 1}, {typ: ':', intlen: 0}}), nil)
+			}
+			return newResult(slicemsg('*', []ValkeyMessage{{typ: ':', intlen: 1}, {typ: ':', intlen: 1}}), nil)
+		})
+		defer client.Close()
+		if v, err := client.Do(context.Background(), client.B().ScriptExists().Sha1("a", "b").Build()).AsIntSlice(); err != nil || !reflect.DeepEqual(v, []int64{1, 0}) {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("all_shards concatenate", func(t *testing.T) {
+		client, _ := setup(func(addr string, cmd []string) ValkeyResult {
+			return newResult(slicemsg('*', []ValkeyMessage{strmsg('+', addr)}), nil)
+		})
+		defer client.Close()
+		v, err := client.Do(context.Background(), client.B().Keys().Pattern("*").Build()).AsStrSlice()
+		if sort.Strings(v); err != nil || !reflect.DeepEqual(v, []string{"127.0.0.1:0", "127.0.2.1:0"}) {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("all_shards first non-nil", func(t *testing.T) {
+		client, _ := setup(func(addr string, cmd []string) ValkeyResult {
+			if addr == "127.0.2.1:0" {
+				return newResult(strmsg('+', "k"), nil)
+			}
+			return newResult(ValkeyMessage{typ: '_'}, nil)
+		})
+		defer client.Close()
+		if v, err := client.Do(context.Background(), client.B().Randomkey().Build()).ToString(); err != nil || v != "k" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("all_nodes", func(t *testing.T) {
+		client, calls := setup(func(addr string, cmd []string) ValkeyResult {
+			return newResult(strmsg('+', "OK"), nil)
+		})
+		defer client.Close()
+		if v, err := client.Do(context.Background(), client.B().ConfigSet().ParameterValue().ParameterValue("a", "b").Build()).ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if n := count(calls); n != 4 {
+			t.Fatalf("unexpected nodes %v", n)
+		}
+	})
+
+	t.Run("DoAsync", func(t *testing.T) {
+		client, _ := setup(func(addr string, cmd []string) ValkeyResult {
+			return newResult(ValkeyMessage{typ: ':', intlen: 2}, nil)
+		})
+		defer client.Close()
+		if v, err := client.DoAsync(context.Background(), client.B().Dbsize().Build()).Wait(context.Background()).ToInt64(); err != nil || v != 4 {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("DoMulti", func(t *testing.T) {
+		client, calls := setup(func(addr string, cmd []string) ValkeyResult {
+			if cmd[0] == "GET" {
+				return newResult(strmsg('+', addr), nil)
+			}
+			return newResult(ValkeyMessage{typ: ':', intlen: 2}, nil)
+		})
+		defer client.Close()
+		multi := func() []Completed {
+			return []Completed{client.B().Get().Key("a").Build(), client.B().Dbsize().Build()}
+		}
+		for _, resps := range [][]ValkeyResult{
+			client.DoMulti(context.Background(), multi()...),
+			client.DoMultiAsync(context.Background(), multi()...).Wait(context.Background()),
+		} {
+			if v, err := resps[0].ToString(); err != nil || v == "" {
+				t.Fatalf("unexpected response %v %v", v, err)
+			}
+			if v, err := resps[1].ToInt64(); err != nil || v != 4 {
+				t.Fatalf("unexpected response %v %v", v, err)
+			}
+		}
+		if n := count(calls); n != 2 {
+			t.Fatalf("unexpected nodes %v", n)
+		}
+		if hasBroadcast([]Completed{client.B().Multi().Build(), client.B().Dbsize().Build(), client.B().Exec().Build()}) {
+			t.Fatalf("commands in transactions should not be broadcast")
+		}
+	})
+}
+
+func TestAggregate(t *testing.T) {
+	ints := func(vs ...int64) (resps []ValkeyResult) {
+		for _, v := range vs {
+			resps = append(resps, newResult(ValkeyMessage{typ: ':', intlen: v}, nil))
+		}
+		return resps
+	}
+	for _, c := range []struct {
+		policy uint16
+		resps  []ValkeyResult
+		want   int64
+	}{
+		{policy: cmds.AggSum, resps: ints(1, 2, 3), want: 6},
+		{policy: cmds.AggMin, resps: ints(3, 1, 2), want: 1},
+		{policy: cmds.AggMax, resps: ints(1, 3, 2), want: 3},
+		{policy: cmds.AggLogicalAnd, resps: ints(1, 0, 1), want: 0},
+		{policy: cmds.AggLogicalAnd, resps: ints(1, 1, 1), want: 1},
+		{policy: cmds.AggLogicalOr, resps: ints(0, 1, 0), want: 1},
+		{policy: cmds.AggLogicalOr, resps: ints(0, 0, 0), want: 0},
+	} {
+		if v, err := aggregate(c.policy, c.resps).ToInt64(); err != nil || v != c.want {
+			t.Fatalf("unexpected response of policy %v: %v %v", c.policy, v, err)
+		}
+	}
+	if err := aggregate(cmds.AggSum, []ValkeyResult{newResult(strmsg('+', "a"), nil)}).Error(); err == nil {
+		t.Fatalf("expected err for non-integer reply")
+	}
+	if err := aggregate(cmds.AggLogicalAnd, []ValkeyResult{
+		newResult(slicemsg('*', []ValkeyMessage{{typ: ':', intlen: 1}}), nil),
+		newResult(slicemsg('*', nil), nil),
+	}).Error(); err != errBroadcastReplyLength {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := aggregate(cmds.OneSucceeded, []ValkeyResult{newErrResult(io.EOF), newErrResult(io.ErrClosedPipe)}).Error(); err != io.EOF {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestClusterClientSplitCrossSlot(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+
+	// slot("a") = 15495 is owned by 127.0.2.1:0 while slot("b") = 3300 and slot("c") = 7365 are owned by 127.0.0.1:0
+	reply := func(addr string, cmd []string) ValkeyResult {
+		switch cmd[0] {
+		case "DEL", "EXISTS":
+			return newResult(ValkeyMessage{typ: ':', intlen: int64(len(cmd) - 1)}, nil)
+		case "MGET":
+			values := make([]ValkeyMessage, len(cmd)-1)
+			for i, k := range cmd[1:] {
+				values[i] = strmsg('+', k)
+			}
+			return newResult(slicemsg('*', values), nil)
+		case "MSET":
+			if addr == "127.0.2.1:0" {
+				return newResult(strmsg('-', "ERR failed"), nil)
+			}
+		}
+		return newResult(strmsg('+', "OK"), nil)
+	}
+	var mu sync.Mutex
+	var sent []string
+	client, err := newClusterClient(
+		&ClientOption{InitAddress: []string{"127.0.0.1:0"}, ClusterOption: ClusterOption{SplitCrossSlot: true}},
+		func(dst string, opt *ClientOption) conn {
+			return &mockConn{
+				DoFn: func(cmd Completed) ValkeyResult {
+					if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+						return slotsMultiResp
+					}
+					mu.Lock()
+					sent = append(sent, dst+" "+strings.Join(cmd.Commands(), " "))
+					mu.Unlock()
+					return reply(dst, cmd.Commands())
+				},
+				DoMultiFn: func(multi ...Completed) *valkeyresults {
+					resps := make([]ValkeyResult, len(multi))
+					mu.Lock()
+					for i, cmd := range multi {
+						sent = append(sent, dst+" "+strings.Join(cmd.Commands(), " "))
+						resps[i] = reply(dst, cmd.Commands())
+					}
+					mu.Unlock()
+					return &valkeyresults{s: resps}
+				},
+			}
+		},
+		newRetryer(defaultRetryDelayFn),
+	)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+	check := func(t *testing.T, expected ...string) {
+		mu.Lock()
+		defer mu.Unlock()
+		sort.Strings(sent)
+		sort.Strings(expected)
+		if !reflect.DeepEqual(sent, expected) {
+			t.Fatalf("unexpected commands %v", sent)
+		}
+		sent = nil
+	}
+	ctx := context.Background()
+
+	t.Run("Do agg_sum", func(t *testing.T) {
+		if v, err := client.Do(ctx, client.B().Del().Key("a", "b", "c").Build()).ToInt64(); err != nil || v != 3 {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		check(t, "127.0.0.1:0 DEL b", "127.0.0.1:0 DEL c", "127.0.2.1:0 DEL a")
+	})
+
+	t.Run("Do concatenate in key order", func(t *testing.T) {
+		if v, err := client.Do(ctx, client.B().Mget().Key("b", "a", "c").Build()).AsStrSlice(); err != nil || !reflect.DeepEqual(v, []string{"b", "a", "c"}) {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		check(t, "127.0.0.1:0 MGET b", "127.0.0.1:0 MGET c", "127.0.2.1:0 MGET a")
+	})
+
+	t.Run("Do all_succeeded", func(t *testing.T) {
+		if err := client.Do(ctx, client.B().Mset().KeyValue().KeyValue("a", "1").KeyValue("b", "2").Build()).Error(); err == nil || err.Error() != "failed" {
+			t.Fatalf("unexpected err %v", err)
+		}
+		check(t, "127.0.0.1:0 MSET b 2", "127.0.2.1:0 MSET a 1")
+	})
+
+	t.Run("Do same slot", func(t *testing.T) {
+		if v, err := client.Do(ctx, client.B().Exists().Key("{a}1", "{a}2").Build()).ToInt64(); err != nil || v != 2 {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		check(t, "127.0.2.1:0 EXISTS {a}1 {a}2")
+	})
+
+	t.Run("DoMulti", func(t *testing.T) {
+		resps := client.DoMulti(ctx,
+			client.B().Get().Key("a").Build(),
+			client.B().Mget().Key("a", "b").Build(),
+			client.B().Exists().Key("c").Build(),
+		)
+		if v, err := resps[0].ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if v, err := resps[1].AsStrSlice(); err != nil || !reflect.DeepEqual(v, []string{"a", "b"}) {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if v, err := resps[2].ToInt64(); err != nil || v != 1 {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		check(t, "127.0.2.1:0 GET a", "127.0.2.1:0 MGET a", "127.0.0.1:0 MGET b", "127.0.0.1:0 EXISTS c")
+	})
+
+	t.Run("DoAsync", func(t *testing.T) {
+		if v, err := client.DoAsync(ctx, client.B().Del().Key("a", "b").Build()).Wait(ctx).ToInt64(); err != nil || v != 2 {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		check(t, "127.0.0.1:0 DEL b", "127.0.2.1:0 DEL a")
+	})
+
+	t.Run("DoMultiAsync", func(t *testing.T) {
+		resps := client.DoMultiAsync(ctx, client.B().Mget().Key("c", "a").Build()).Wait(ctx)
+		if v, err := resps[0].AsStrSlice(); err != nil || !reflect.DeepEqual(v, []string{"c", "a"}) {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		check(t, "127.0.0.1:0 MGET c", "127.0.2.1:0 MGET a")
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		if err := client.DoCache(ctx, client.B().Mget().Key("a", "b").Cache(), time.Second).Error(); err != errCrossSlot {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if s := client.DoStream(ctx, client.B().Del().Key("a", "b").Build()); s.Error() != errCrossSlot {
+			t.Fatalf("unexpected err %v", s.Error())
+		}
+		check(t)
+	})
+
+	t.Run("Dedicated", func(t *testing.T) {
+		defer func() {
+			if !strings.Contains(fmt.Sprint(recover()), "multi key command with different key slots are not allowed") {
+				t.Fatal("cross key slot not panic as expected")
+			}
+		}()
+		client.Dedicated(func(c DedicatedClient) error {
+			c.B().Del().Key("a", "b")
+			return nil
+		})
+	})
+
+	t.Run("Dedicated CrossSlot", func(t *testing.T) {
+		err := client.Dedicated(func(c DedicatedClient) error {
+			if err := c.Do(ctx, client.B().Del().Key("a", "b").Build()).Error(); err != errCrossSlot {
+				t.Fatalf("unexpected err %v", err)
+			}
+			for _, r := range c.DoMulti(ctx, client.B().Get().Key("a").Build(), client.B().Mget().Key("a", "b").Build()) {
+				if err := r.Error(); err != errCrossSlot {
+					t.Fatalf("unexpected err %v", err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		c, cancel := client.Dedicate()
+		defer cancel()
+		if err := c.Do(ctx, client.B().Mget().Key("a", "b").Build()).Error(); err != errCrossSlot {
+			t.Fatalf("unexpected err %v", err)
+		}
+		check(t)
+	})
+
+	t.Run("Pick CrossSlot", func(t *testing.T) {
+		cc := client
+		if p := cc._pick(cmds.CrossSlot, true); p != nil {
+			t.Fatalf("unexpected conn %v", p)
+		}
+		if _, err := cc.pick(ctx, cmds.CrossSlot, false); err != errCrossSlot {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	strs := func(vs ...string) ValkeyResult {
+		values := make([]ValkeyMessage, len(vs))
+		for i, v := range vs {
+			values[i] = strmsg('+', v)
+		}
+		return newResult(slicemsg('*', values), nil)
+	}
+	if v, err := merge(cmds.DefaultResponse, [][]int{{1}, {0, 2}}, []ValkeyResult{strs("b"), strs("a", "c")}).AsStrSlice(); err != nil || !reflect.DeepEqual(v, []string{"a", "b", "c"}) {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+	if err := merge(cmds.DefaultResponse, [][]int{{1}, {0, 2}}, []ValkeyResult{strs("b"), strs("a")}).Error(); err != errSplitReplyLength {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := merge(cmds.DefaultResponse, [][]int{{1}, {0}}, []ValkeyResult{strs("b"), newResult(strmsg('-', "ERR failed"), nil)}).Error(); err == nil || err.Error() != "failed" {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if v, err := merge(cmds.AggSum, [][]int{{1}, {0}}, []ValkeyResult{newResult(ValkeyMessage{typ: ':', intlen: 1}, nil), newResult(ValkeyMessage{typ: ':', intlen: 1}, nil)}).ToInt64(); err != nil || v != 2 {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+}
+
+func TestClusterClientOnTopologyChange(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+
+	var changes []TopologyChange
+	var refreshed atomic.Bool
+	client, err := newClusterClient(
+		&ClientOption{
+			InitAddress:      []string{"127.0.0.1:0"},
+			OnTopologyChange: func(change TopologyChange) { changes = append(changes, change) },
+		},
+		func(dst string, opt *ClientOption) conn {
+			return &mockConn{
+				DoFn: func(cmd Completed) ValkeyResult {
+					if refreshed.Load() {
+						return singleSlotResp2
+					}
+					return slotsMultiResp
+				},
+			}
+		},
+		newRetryer(defaultRetryDelayFn),
+	)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+
+	if err := client.refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if len(changes) != 1 || !reflect.DeepEqual(changes[0], TopologyChange{
+		Added: []TopologyNode{{Addr: "127.0.0.1:0", Primary: true}, {Addr: "127.0.1.1:1"}, {Addr: "127.0.2.1:0", Primary: true}, {Addr: "127.0.3.1:1"}},
+	}) {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+
+	refreshed.Store(true)
+	if err := client.refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if len(changes) != 2 || !reflect.DeepEqual(changes[1], TopologyChange{
+		Added:   []TopologyNode{{Addr: "127.0.3.1:3", Primary: true}},
+		Removed: []TopologyNode{{Addr: "127.0.0.1:0", Primary: true}, {Addr: "127.0.1.1:1"}, {Addr: "127.0.2.1:0", Primary: true}, {Addr: "127.0.3.1:1"}},
+		Moved: []SlotRangeMove{
+			{From: "127.0.0.1:0", To: "127.0.3.1:3", Start: 0, End: 0},
+			{From: "127.0.0.1:0", To: "", Start: 1, End: 8192},
+			{From: "127.0.2.1:0", To: "", Start: 8193, End: 16383},
+		},
+	}) {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+}
+
+func TestClusterClientReplicaLag(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+
+	var offset atomic.Int64
+	offset.Store(100)
+	var lags sync.Map
+	client, err := newClusterClient(
+		&ClientOption{
+			InitAddress:    []string{"127.0.0.1:0"},
+			SendToReplicas: func(cmd Completed) bool { return cmd.IsReadOnly() },
+			ReadNodeSelector: func(slot uint16, nodes []NodeInfo) int {
+				for _, n := range nodes {
+					lags.Store(n.Addr, n.Lag())
+				}
+				return len(nodes) - 1
+			},
+			ReplicaLag: &ReplicaLagOption{Interval: time.Hour, MaxLag: 10},
+		},
+		func(dst string, opt *ClientOption) conn {
+			replica := dst == "127.0.1.1:1" || dst == "127.0.3.1:1"
+			return &mockConn{
+				DoFn: func(cmd Completed) ValkeyResult {
+					switch {
+					case strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS":
+						return slotsMultiResp
+					case cmd == cmds.RoleCmd && replica:
+						return newResult(slicemsg('*', []ValkeyMessage{strmsg('+', "slave"), strmsg('+', ""), {typ: ':', intlen: 0}, strmsg('+', "connected"), {typ: ':', intlen: offset.Load()}}), nil)
+					case cmd == cmds.RoleCmd:
+						return newResult(slicemsg('*', []ValkeyMessage{strmsg('+', "master"), {typ: ':', intlen: 100}, slicemsg('*', nil)}), nil)
+					}
+					return newResult(strmsg('+', dst), nil)
+				},
+				DoMultiFn: func(multi ...Completed) *valkeyresults {
+					resps := make([]ValkeyResult, len(multi))
+					for i := range multi {
+						resps[i] = newResult(strmsg('+', dst), nil)
+					}
+					return &valkeyresults{s: resps}
+				},
+			}
+		},
+		newRetryer(defaultRetryDelayFn),
+	)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	read := func() (string, string) {
+		v1, _ := client.Do(ctx, client.B().Get().Key("b").Build()).ToString()
+		v2, _ := client.DoMulti(ctx, client.B().Get().Key("b").Build())[0].ToString()
+		return v1, v2
+	}
+
+	client.sampleLags(ctx)
+	if v1, v2 := read(); v1 != "127.0.1.1:1" || v2 != "127.0.1.1:1" {
+		t.Fatalf("unexpected node %v %v", v1, v2)
+	}
+
+	offset.Store(50)
+	client.sampleLags(ctx)
+	if v1, v2 := read(); v1 != "127.0.0.1:0" || v2 != "127.0.0.1:0" {
+		t.Fatalf("the lagging replica should be excluded %v %v", v1, v2)
+	}
+	if lag, _ := lags.Load("127.0.1.1:1"); lag != int64(50) {
+		t.Fatalf("unexpected lag %v", lag)
+	}
+	if lag, _ := lags.Load("127.0.0.1:0"); lag != int64(0) {
+		t.Fatalf("unexpected lag %v", lag)
+	}
+}
+
+func TestClusterClientSlotMigration(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var moved atomic.Bool
+	var sourceGets atomic.Int64
+	var mu sync.Mutex
+	var events []SlotMigration
+	slot := KeySlot("b")
+	client, err := newClusterClient(
+		&ClientOption{InitAddress: []string{":0"}, ClusterOption: ClusterOption{
+			OnSlotMigration: func(m SlotMigration) {
+				mu.Lock()
+				events = append(events, m)
+				mu.Unlock()
+			},
+		}},
+		func(dst string, opt *ClientOption) conn {
+			return &mockConn{
+				AddrFn: func() string { return dst },
+				DoFn: func(cmd Completed) ValkeyResult {
+					if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+						return slotsMultiResp
+					}
+					if dst == "127.0.2.1:0" {
+						return newResult(strmsg('+', dst), nil)
+					}
+					sourceGets.Add(1)
+					if moved.Load() {
+						return newResult(strmsg('-', fmt.Sprintf("MOVED %d 127.0.2.1:0", slot)), nil)
+					}
+					return newResult(strmsg('-', fmt.Sprintf("ASK %d 127.0.2.1:0", slot)), nil)
+				},
+				DoMultiFn: func(multi ...Completed) *valkeyresults {
+					return &valkeyresults{s: []ValkeyResult{newResult(strmsg('+', "OK"), nil), newResult(strmsg('+', dst), nil)}}
+				},
+			}
+		},
+		newRetryer(defaultRetryDelayFn),
+	)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+	last := func() SlotMigration {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(events) == 0 {
+			return SlotMigration{}
+		}
+		return events[len(events)-1]
+	}
+
+	for range 2 {
+		if v, err := client.Do(context.Background(), client.B().Get().Key("b").Build()).ToString(); err != nil || v != "127.0.2.1:0" {
+			t.Fatalf("unexpected resp %v %v", v, err)
+		}
+	}
+	if e := last(); len(events) != 1 || e != (SlotMigration{Source: "127.0.0.1:0", Target: "127.0.2.1:0", Slot: slot}) {
+		t.Fatalf("unexpected events %v", events)
+	}
+	if n := client.sc.suppressing(); n != 0 {
+		t.Fatalf("ASK should not trigger refreshes")
+	}
+
+	moved.Store(true)
+	if v, err := client.Do(context.Background(), client.B().Get().Key("b").Build()).ToString(); err != nil || v != "127.0.2.1:0" {
+		t.Fatalf("unexpected resp %v %v", v, err)
+	}
+	if e := last(); len(events) != 2 || e != (SlotMigration{Source: "127.0.0.1:0", Target: "127.0.2.1:0", Slot: slot, Done: true}) {
+		t.Fatalf("unexpected events %v", events)
+	}
+	n := sourceGets.Load()
+	if v, err := client.Do(context.Background(), client.B().Get().Key("b").Build()).ToString(); err != nil || v != "127.0.2.1:0" {
+		t.Fatalf("unexpected resp %v %v", v, err)
+	}
+	if sourceGets.Load() != n {
+		t.Fatalf("the moved slot should be sent to the target directly")
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 type queuedCmd struct {
 	ch    chan ValkeyResult
+	fn    func(ValkeyResult)
 	one   Completed
 	multi []Completed
 	resps []ValkeyResult
@@ -16,6 +17,8 @@ type flowBuffer struct {
 	r chan queuedCmd
 	w chan queuedCmd
 	c *chan ValkeyResult
+	a chan ValkeyResult // receives results of async commands
+	g func(ValkeyResult)
 }
 
 var _ queue = (*flowBuffer)(nil)
@@ -30,6 +33,7 @@ func newFlowBuffer(factor int) *flowBuffer {
 		f: make(chan queuedCmd, size),
 		r: make(chan queuedCmd, size),
 		w: make(chan queuedCmd, size),
+		a: make(chan ValkeyResult, 1),
 	}
 	for range size {
 		r.f <- queuedCmd{
@@ -61,6 +65,17 @@ func (b *flowBuffer) PutMulti(ctx context.Context, m []Completed, resps []Valkey
 	}
 }
 
+func (b *flowBuffer) PutAsync(ctx context.Context, one Completed, multi []Completed, resps []ValkeyResult, fn func(ValkeyResult)) error {
+	select {
+	case cmd := <-b.f:
+		cmd.one, cmd.multi, cmd.resps, cmd.fn = one, multi, resps, fn
+		b.w <- cmd
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // NextWriteCmd should be only called by one dedicated thread
 func (b *flowBuffer) NextWriteCmd() (one Completed, multi []Completed, ch chan ValkeyResult) {
 	select {
@@ -86,6 +101,9 @@ func (b *flowBuffer) NextResultCh() (one Completed, multi []Completed, ch chan V
 	case cmd := <-b.r:
 		b.c = &cmd.ch
 		one, multi, ch, resps = cmd.one, cmd.multi, cmd.ch, cmd.resps
+		if cmd.fn != nil {
+			ch, b.g = b.a, cmd.fn
+		}
 	default:
 	}
 	return
@@ -97,4 +115,8 @@ func (b *flowBuffer) FinishResult() {
 		b.f <- queuedCmd{ch: *b.c}
 		b.c = nil
 	}
+	if fn := b.g; fn != nil {
+		b.g = nil
+		fn(<-b.a)
+	}
 }
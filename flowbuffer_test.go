@@ -109,6 +109,32 @@ func TestFlowBuffer(t *testing.T) {
 		}
 	})
 
+	t.Run("PutAsync", func(t *testing.T) {
+		q := newFlowBuffer(DefaultRingScale)
+		var got []ValkeyResult
+		fn := func(resp ValkeyResult) { got = append(got, resp) }
+		if err := q.PutAsync(context.Background(), cmds.NewCompleted([]string{"0"}), nil, nil, fn); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if err := q.PutAsync(context.Background(), Completed{}, cmds.NewMultiCompleted([][]string{{"1"}}), nil, fn); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		for i := range 2 {
+			if _, _, ch := q.NextWriteCmd(); ch == nil {
+				t.Fatalf("NextWriteCmd should returns next cmd")
+			}
+			_, _, ch, _ := q.NextResultCh()
+			if ch == nil || cap(ch) == 0 {
+				t.Fatalf("NextResultCh should returns a buffered channel for async cmds")
+			}
+			ch <- newResult(strmsg('+', strconv.Itoa(i)), nil)
+			q.FinishResult()
+			if len(got) != i+1 || got[i].val.string() != strconv.Itoa(i) {
+				t.Fatalf("FinishResult should pass the result to the fn")
+			}
+		}
+	})
+
 	t.Run("PutOne Wakeup WaitForWrite", func(t *testing.T) {
 		buffer := newFlowBuffer(DefaultRingScale)
 		if one, _, ch := buffer.NextWriteCmd(); ch == nil {
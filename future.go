@@ -0,0 +1,124 @@
+package valkey
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ValkeyFuture is the pending result of a Client.DoAsync call.
+type ValkeyFuture struct {
+	done chan struct{}
+	fns  []func(ValkeyResult)
+	resp ValkeyResult
+	mu   sync.Mutex
+}
+
+func newFuture() *ValkeyFuture {
+	return &ValkeyFuture{done: make(chan struct{})}
+}
+
+func (f *ValkeyFuture) resolve(resp ValkeyResult) {
+	f.mu.Lock()
+	f.resp = resp
+	fns := f.fns
+	f.fns = nil
+	close(f.done)
+	f.mu.Unlock()
+	for _, fn := range fns {
+		fn(resp)
+	}
+}
+
+// Done returns a channel that is closed when the result is ready.
+func (f *ValkeyFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the result is ready or the ctx is done.
+// The ctx only bounds the waiting. It does not cancel the command, which can still be waited again later.
+func (f *ValkeyFuture) Wait(ctx context.Context) ValkeyResult {
+	select {
+	case <-f.done:
+		return f.resp
+	case <-ctx.Done():
+		return newErrResult(ctx.Err())
+	}
+}
+
+// OnDone registers the fn to be called with the result once it is ready, or calls it immediately if the result is already ready.
+// The fn may be called by the goroutine reading responses from the connection, so it must not block.
+func (f *ValkeyFuture) OnDone(fn func(ValkeyResult)) {
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		fn(f.resp)
+	default:
+		f.fns = append(f.fns, fn)
+		f.mu.Unlock()
+	}
+}
+
+// ValkeyMultiFuture is the pending results of a Client.DoMultiAsync call.
+type ValkeyMultiFuture struct {
+	done  chan struct{}
+	fns   []func([]ValkeyResult)
+	resps []ValkeyResult
+	mu    sync.Mutex
+	parts atomic.Int32
+}
+
+func newMultiFuture(n int) *ValkeyMultiFuture {
+	return &ValkeyMultiFuture{done: make(chan struct{}), resps: make([]ValkeyResult, n)}
+}
+
+// add increases the number of parts of the resps that must be resolved before the future is done.
+func (f *ValkeyMultiFuture) add(parts int) {
+	f.parts.Add(int32(parts))
+}
+
+// resolve marks one part of the resps as resolved. The last one completes the future.
+func (f *ValkeyMultiFuture) resolve() {
+	if f.parts.Add(-1) != 0 {
+		return
+	}
+	f.mu.Lock()
+	fns := f.fns
+	f.fns = nil
+	close(f.done)
+	f.mu.Unlock()
+	for _, fn := range fns {
+		fn(f.resps)
+	}
+}
+
+// Done returns a channel that is closed when all results are ready.
+func (f *ValkeyMultiFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until all results are ready or the ctx is done.
+// The ctx only bounds the waiting. It does not cancel the commands, which can still be waited again later.
+func (f *ValkeyMultiFuture) Wait(ctx context.Context) []ValkeyResult {
+	select {
+	case <-f.done:
+		return f.resps
+	case <-ctx.Done():
+		return fillErrs(len(f.resps), ctx.Err())
+	}
+}
+
+// OnDone registers the fn to be called with the results once they are ready, or calls it immediately if they are already ready.
+// The fn may be called by the goroutine reading responses from the connection, so it must not block.
+func (f *ValkeyMultiFuture) OnDone(fn func([]ValkeyResult)) {
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		fn(f.resps)
+	default:
+		f.fns = append(f.fns, fn)
+		f.mu.Unlock()
+	}
+}
@@ -0,0 +1,92 @@
+package valkey
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFuture(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	t.Run("Wait", func(t *testing.T) {
+		f := newFuture()
+		var called []string
+		f.OnDone(func(resp ValkeyResult) {
+			v, _ := resp.ToString()
+			called = append(called, v)
+		})
+		select {
+		case <-f.Done():
+			t.Fatalf("future should not be done")
+		default:
+		}
+		f.resolve(newResult(strmsg('+', "OK"), nil))
+		if v, err := f.Wait(context.Background()).ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		<-f.Done()
+		f.OnDone(func(resp ValkeyResult) {
+			v, _ := resp.ToString()
+			called = append(called, v)
+		})
+		if len(called) != 2 || called[0] != "OK" || called[1] != "OK" {
+			t.Fatalf("unexpected OnDone calls %v", called)
+		}
+	})
+
+	t.Run("Wait Context Is Done", func(t *testing.T) {
+		f := newFuture()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := f.Wait(ctx).Error(); err != context.Canceled {
+			t.Fatalf("unexpected err %v", err)
+		}
+		f.resolve(newResult(strmsg('+', "OK"), nil))
+		if v, err := f.Wait(context.Background()).ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+}
+
+func TestMultiFuture(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	t.Run("Wait", func(t *testing.T) {
+		f := newMultiFuture(2)
+		f.add(2)
+		var called int
+		f.OnDone(func(resps []ValkeyResult) { called += len(resps) })
+		f.resps[0] = newResult(strmsg('+', "0"), nil)
+		f.resolve()
+		select {
+		case <-f.Done():
+			t.Fatalf("future should not be done until all parts are resolved")
+		default:
+		}
+		f.resps[1] = newResult(strmsg('+', "1"), nil)
+		f.resolve()
+		for i, resp := range f.Wait(context.Background()) {
+			if v, err := resp.ToString(); err != nil || v != string(rune('0'+i)) {
+				t.Fatalf("unexpected response %v %v", v, err)
+			}
+		}
+		f.OnDone(func(resps []ValkeyResult) { called += len(resps) })
+		if called != 4 {
+			t.Fatalf("unexpected OnDone calls %v", called)
+		}
+	})
+
+	t.Run("Wait Context Is Done", func(t *testing.T) {
+		f := newMultiFuture(2)
+		f.add(1)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		for _, resp := range f.Wait(ctx) {
+			if err := resp.Error(); err != context.Canceled {
+				t.Fatalf("unexpected err %v", err)
+			}
+		}
+		f.resolve()
+		if resps := f.Wait(context.Background()); len(resps) != 2 {
+			t.Fatalf("unexpected resps %v", resps)
+		}
+	})
+}
@@ -47,6 +47,9 @@ type command struct {
 	Group     string     `json:"group"`
 	Since     string     `json:"since"`
 	Arguments []argument `json:"arguments"`
+
+	RequestPolicy  string `json:"request_policy"`
+	ResponsePolicy string `json:"response_policy"`
 }
 
 type argument struct {
@@ -794,10 +797,15 @@ func printRootBuilder(w io.Writer, root goStruct) {
 		appends = append(appends, fmt.Sprintf(`"%s"`, cmd))
 	}
 
+	ks := "b.ks"
+	if root.Node.Cmd.RequestPolicy == "multi_shard" {
+		ks = "toCrossSlot(b.ks)"
+	}
+
 	if tag := rootCf(root); tag != "" {
-		fmt.Fprintf(w, "\tc = %s{cs: get(), ks: b.ks, cf: int16(%s)}\n", root.FullName, tag)
+		fmt.Fprintf(w, "\tc = %s{cs: get(), ks: %s, cf: int16(%s)}\n", root.FullName, ks, tag)
 	} else {
-		fmt.Fprintf(w, "\tc = %s{cs: get(), ks: b.ks}\n", root.FullName)
+		fmt.Fprintf(w, "\tc = %s{cs: get(), ks: %s}\n", root.FullName, ks)
 	}
 	fmt.Fprintf(w, "\tc.cs.s = append(c.cs.s, %s)\n", strings.Join(appends, ", "))
 	fmt.Fprintf(w, "\treturn c\n")
@@ -844,6 +852,54 @@ func rootCf(root goStruct) (tag string) {
 		tag = "readonly"
 	}
 
+	if policy := policyTags(root.Node.Cmd); policy != "" {
+		if tag != "" {
+			tag += " | "
+		}
+		tag += policy
+	}
+
+	return tag
+}
+
+// policyTags converts the request_policy and response_policy tips into tags.
+// The tips are only set on part of the commands in the commands.json, listed in the README.
+// Ref: https://valkey.io/topics/command-tips/
+func policyTags(cmd command) (tag string) {
+	switch cmd.RequestPolicy {
+	case "":
+		if cmd.ResponsePolicy != "" {
+			panic("response_policy without request_policy")
+		}
+		return ""
+	case "all_nodes":
+		tag = "allNodesTag"
+	case "all_shards":
+		tag = "allShardsTag"
+	case "multi_shard":
+		tag = "multiShardTag"
+	default:
+		panic("unknown request_policy " + cmd.RequestPolicy)
+	}
+	switch cmd.ResponsePolicy {
+	case "", "special":
+	case "all_succeeded":
+		tag += " | AllSucceeded"
+	case "one_succeeded":
+		tag += " | OneSucceeded"
+	case "agg_sum":
+		tag += " | AggSum"
+	case "agg_logical_and":
+		tag += " | AggLogicalAnd"
+	case "agg_logical_or":
+		tag += " | AggLogicalOr"
+	case "agg_min":
+		tag += " | AggMin"
+	case "agg_max":
+		tag += " | AggMax"
+	default:
+		panic("unknown response_policy " + cmd.ResponsePolicy)
+	}
 	return tag
 }
 
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"iter"
+	"math/rand/v2"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -349,19 +352,39 @@ func clusterJsonMGet(client Client, ctx context.Context, keys []string, path str
 var ErrMSetNXNotSet = errors.New("MSETNX: no key was set")
 
 type Scanner struct {
-	next func(cursor uint64) (ScanEntry, error)
-	err  error
+	next  func(cursor uint64) (ScanEntry, error)
+	shard func(i int, cursor uint64) (ScanEntry, bool, error) // used instead of the next by the shard scanners
+	err   error
 }
 
 func NewScanner(next func(cursor uint64) (ScanEntry, error)) *Scanner {
 	return &Scanner{next: next}
 }
 
+// newShardScanner returns a Scanner that scans the shards one by one with the shard, each from the cursor 0 until it is back to 0.
+// The shard returns false if there is no i-th shard to scan.
+func newShardScanner(shard func(i int, cursor uint64) (ScanEntry, bool, error)) *Scanner {
+	return &Scanner{shard: shard}
+}
+
 func (s *Scanner) scan() iter.Seq[[]string] {
 	return func(yield func([]string) bool) {
 		var e ScanEntry
-		for e, s.err = s.next(0); s.err == nil && yield(e.Elements) && e.Cursor != 0; {
-			e, s.err = s.next(e.Cursor)
+		if s.shard == nil {
+			for e, s.err = s.next(0); s.err == nil && yield(e.Elements) && e.Cursor != 0; {
+				e, s.err = s.next(e.Cursor)
+			}
+			return
+		}
+		for i, ok := 0, true; ok && s.err == nil; i++ {
+			for e, ok, s.err = s.shard(i, 0); ok && s.err == nil; e, ok, s.err = s.shard(i, e.Cursor) {
+				if !yield(e.Elements) {
+					return
+				}
+				if e.Cursor == 0 {
+					break
+				}
+			}
 		}
 	}
 }
@@ -394,6 +417,172 @@ func (s *Scanner) Err() error {
 	return s.err
 }
 
+// ScanArgs are the optional arguments of the ScanAll, HScanAll, SScanAll and ZScanAll helpers.
+type ScanArgs struct {
+	// Match is the MATCH pattern. It is not sent if empty.
+	Match string
+	// Type is the TYPE filter. It is only used by ScanAll and is not sent if empty.
+	Type string
+	// Count is the COUNT hint. It is not sent if not positive.
+	Count int64
+}
+
+func (a ScanArgs) build(cmd []string, cursor uint64) []string {
+	cmd = append(cmd, strconv.FormatUint(cursor, 10))
+	if a.Match != "" {
+		cmd = append(cmd, "MATCH", a.Match)
+	}
+	if a.Count > 0 {
+		cmd = append(cmd, "COUNT", strconv.FormatInt(a.Count, 10))
+	}
+	return cmd
+}
+
+// ScanAll is a helper that returns a Scanner iterating all keys with SCAN.
+// For a cluster client, it scans the primary of every shard exactly once,
+// and if a shard fails over in the middle, the scan is resumed on the new primary with the same cursor.
+// For a client sharded by the ShardedOption, it scans the primary of every shard not ejected exactly once.
+func ScanAll(client Client, ctx context.Context, args ScanArgs) *Scanner {
+	build := func(cursor uint64) []string {
+		cmd := args.build([]string{"SCAN"}, cursor)
+		if args.Type != "" {
+			cmd = append(cmd, "TYPE", args.Type)
+		}
+		return cmd
+	}
+	return newScanner(client, ctx, "", build)
+}
+
+// HScanAll is a helper that returns a Scanner iterating all fields and values of the hash key with HSCAN.
+// Use Scanner.Iter2 to iterate them in pairs.
+func HScanAll(client Client, ctx context.Context, key string, args ScanArgs) *Scanner {
+	return newScanner(client, ctx, key, func(cursor uint64) []string {
+		return args.build([]string{"HSCAN", key}, cursor)
+	})
+}
+
+// SScanAll is a helper that returns a Scanner iterating all members of the set key with SSCAN.
+func SScanAll(client Client, ctx context.Context, key string, args ScanArgs) *Scanner {
+	return newScanner(client, ctx, key, func(cursor uint64) []string {
+		return args.build([]string{"SSCAN", key}, cursor)
+	})
+}
+
+// ZScanAll is a helper that returns a Scanner iterating all members and scores of the sorted set key with ZSCAN.
+// Use Scanner.Iter2 to iterate them in pairs.
+func ZScanAll(client Client, ctx context.Context, key string, args ScanArgs) *Scanner {
+	return newScanner(client, ctx, key, func(cursor uint64) []string {
+		return args.build([]string{"ZSCAN", key}, cursor)
+	})
+}
+
+// shardScanner is implemented by the clients scanning every shard for the ScanAll helpers. Its method is exported,
+// so that the Client wrappers, such as the valkeyhook and the valkeyotel, can forward it to the clients they wrap.
+// The ShardScanner returns nil if the client has no shards to scan.
+type shardScanner interface {
+	ShardScanner(ctx context.Context, key string, build func(cursor uint64) []string) *Scanner
+}
+
+func newScanner(client Client, ctx context.Context, key string, build func(cursor uint64) []string) *Scanner {
+	if c, ok := client.(shardScanner); ok {
+		if s := c.ShardScanner(ctx, key, build); s != nil {
+			return s
+		}
+	}
+	return NewScanner(func(cursor uint64) (ScanEntry, error) {
+		cmd := intl.NewReadOnlyCompleted(build(cursor))
+		if key != "" {
+			cmd = cmd.SetSlot(key)
+		}
+		return client.Do(ctx, cmd).AsScanEntry()
+	})
+}
+
+// KeySlot returns the hash slot of the key in a valkey cluster. Only the hash tag is hashed if the key contains one,
+// for example, "{user1000}.following" and "{user1000}.followers" are in the same slot.
+func KeySlot(key string) uint16 {
+	return intl.Slot(key)
+}
+
+// slotOwner is implemented by the clients routing commands to different nodes by their slots. Its method is exported,
+// so that the Client wrappers, such as the valkeyhook and the valkeyotel, can forward it to the clients they wrap.
+type slotOwner interface {
+	SlotOwner(slot uint16) (addr string, ok bool)
+}
+
+// SlotOwner returns the address of the primary currently serving the slot, according to the topology known by the client.
+// For a client sharded by the ShardedOption, it returns the primary of the shard owning the slot.
+// For other non-cluster clients, it returns the address of the node the client writes to, regardless of the slot.
+// It returns false if the slot is not served by any node or out of range.
+func SlotOwner(client Client, slot uint16) (addr string, ok bool) {
+	if slot >= 16384 {
+		return "", false
+	}
+	switch c := client.(type) {
+	case slotOwner:
+		return c.SlotOwner(slot)
+	case *singleClient:
+		return c.conn.Addr(), true
+	case *standalone:
+		return c.primary.Load().conn.Addr(), true
+	case *sentinelClient:
+		if c.replica {
+			return c.rConn.Load().(conn).Addr(), true
+		}
+		return c.mConn.Load().(conn).Addr(), true
+	}
+	return "", false
+}
+
+// DoMultiBySlot is a helper that builds a command for each of the items and partitions the commands by the nodes owning their slots.
+// Each partition is sent with one DoMulti concurrently, and the results are returned in the same order as the items.
+// Commands without a key or whose slots are not served are put into the same partition and routed by the DoMulti.
+func DoMultiBySlot[T any](client Client, ctx context.Context, items []T, build func(item T) Completed) []ValkeyResult {
+	if len(items) == 0 {
+		return nil
+	}
+	multi := make([]Completed, len(items))
+	for i, item := range items {
+		multi[i] = build(item)
+	}
+	if _, ok := client.(slotOwner); !ok {
+		return client.DoMulti(ctx, multi...)
+	}
+
+	owners := make(map[uint16]string)
+	groups := make(map[string][]int)
+	for i, cmd := range multi {
+		slot := cmd.Slot()
+		owner, ok := owners[slot]
+		if !ok {
+			owner, _ = SlotOwner(client, slot)
+			owners[slot] = owner
+		}
+		groups[owner] = append(groups[owner], i)
+	}
+	if len(groups) == 1 {
+		return client.DoMulti(ctx, multi...)
+	}
+
+	results := make([]ValkeyResult, len(items))
+	var wg sync.WaitGroup
+	wg.Add(len(groups))
+	for _, indexes := range groups {
+		go func(indexes []int) {
+			defer wg.Done()
+			partition := make([]Completed, len(indexes))
+			for i, j := range indexes {
+				partition[i] = multi[j]
+			}
+			for i, resp := range client.DoMulti(ctx, partition...) {
+				results[indexes[i]] = resp
+			}
+		}(indexes)
+	}
+	wg.Wait()
+	return results
+}
+
 // PreferReplicaNodeSelector prioritizes reading from any replica using Round-Robin.
 // If no replicas are available, it falls back to the primary.
 func PreferReplicaNodeSelector() ReadNodeSelectorFunc {
@@ -442,6 +631,27 @@ func AZAffinityReplicasAndPrimaryNodeSelector(clientAZ string) ReadNodeSelectorF
 	}
 }
 
+// LowestLatencyNodeSelector prioritizes the node, including the primary, with the lowest round-trip time measured by NodeInfo.RTT.
+// It compares two randomly chosen nodes and picks the faster one (power of two choices),
+// so that the reads are not herded onto the single fastest node. Nodes not measured yet are preferred to be measured.
+func LowestLatencyNodeSelector() ReadNodeSelectorFunc {
+	return func(_ uint16, nodes []NodeInfo) int {
+		n := len(nodes)
+		if n <= 1 {
+			return n - 1
+		}
+		i := rand.IntN(n)
+		j := rand.IntN(n - 1)
+		if j >= i {
+			j++
+		}
+		if nodes[j].RTT() < nodes[i].RTT() {
+			return j
+		}
+		return i
+	}
+}
+
 // newAZSelector creates the internal selector closure with a specific start index.
 func newAZSelector(clientAZ string, startIdx int) func(uint16, []NodeInfo) int {
 	var counter atomic.Uint32
@@ -6,7 +6,11 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	intl "github.com/valkey-io/valkey-go/internal/cmds"
 )
 
 //gocyclo:ignore
@@ -1684,6 +1688,65 @@ func TestScannerIter2(t *testing.T) {
 	})
 }
 
+func TestShardScannerIter(t *testing.T) {
+	shards := [][]ScanEntry{
+		{{Elements: []string{"a1"}, Cursor: 1}, {Elements: []string{"a2"}, Cursor: 0}},
+		{{Elements: []string{"b1"}, Cursor: 0}},
+		{{Elements: []string{"c1"}, Cursor: 5}, {Elements: []string{"c2"}, Cursor: 0}},
+	}
+	var scanned []string
+	scanner := newShardScanner(func(i int, cursor uint64) (ScanEntry, bool, error) {
+		if i >= len(shards) {
+			return ScanEntry{}, false, nil
+		}
+		scanned = append(scanned, strconv.Itoa(i)+":"+strconv.FormatUint(cursor, 10))
+		if cursor == 0 {
+			return shards[i][0], true, nil
+		}
+		return shards[i][1], true, nil
+	})
+	var keys []string
+	for key := range scanner.Iter() {
+		keys = append(keys, key)
+	}
+	if scanner.Err() != nil || !reflect.DeepEqual(keys, []string{"a1", "a2", "b1", "c1", "c2"}) {
+		t.Fatalf("unexpected keys %v %v", keys, scanner.Err())
+	}
+	if !reflect.DeepEqual(scanned, []string{"0:0", "0:1", "1:0", "2:0", "2:5"}) {
+		t.Fatalf("each shard should be scanned from the cursor 0 exactly once %v", scanned)
+	}
+
+	t.Run("error", func(t *testing.T) {
+		scanner := newShardScanner(func(i int, cursor uint64) (ScanEntry, bool, error) {
+			if i == 1 {
+				return ScanEntry{}, true, errors.New("scan error")
+			}
+			return ScanEntry{Elements: []string{"a"}}, true, nil
+		})
+		var keys []string
+		for key := range scanner.Iter() {
+			keys = append(keys, key)
+		}
+		if scanner.Err() == nil || len(keys) != 1 {
+			t.Fatalf("unexpected keys %v %v", keys, scanner.Err())
+		}
+	})
+
+	t.Run("early exit", func(t *testing.T) {
+		calls := 0
+		scanner := newShardScanner(func(i int, cursor uint64) (ScanEntry, bool, error) {
+			calls++
+			return ScanEntry{Elements: []string{"a"}}, true, nil
+		})
+		for range scanner.Iter() {
+			break
+		}
+		if scanner.Err() != nil || calls != 1 {
+			t.Fatalf("unexpected calls %v %v", calls, scanner.Err())
+		}
+	})
+}
+
 func TestAZAffinityNodesSelection(t *testing.T) {
 	// Setup AZs can be changed per test
 	var primAZ, rep1AZ, rep2AZ, rep3AZ string
@@ -2229,3 +2292,255 @@ func TestClusterHelpersMgetcmdspRecycle(t *testing.T) {
 		}
 	})
 }
+
+func TestLowestLatencyNodeSelector(t *testing.T) {
+	node := func(rtt time.Duration) NodeInfo {
+		return NodeInfo{conn: &mockConn{RTTFn: func() time.Duration { return rtt }}}
+	}
+	selector := LowestLatencyNodeSelector()
+
+	if i := selector(0, nil); i != -1 {
+		t.Fatalf("unexpected index %v", i)
+	}
+	if i := selector(0, []NodeInfo{node(time.Second)}); i != 0 {
+		t.Fatalf("unexpected index %v", i)
+	}
+	for range 100 {
+		if i := selector(0, []NodeInfo{node(time.Second), node(time.Millisecond)}); i != 1 {
+			t.Fatalf("unexpected index %v", i)
+		}
+	}
+
+	nodes := []NodeInfo{node(3 * time.Millisecond), node(time.Millisecond), node(2 * time.Millisecond)}
+	counts := make([]int, len(nodes))
+	for range 3000 {
+		counts[selector(0, nodes)]++
+	}
+	if counts[0] != 0 { // the slowest one never wins a comparison
+		t.Fatalf("unexpected counts %v", counts)
+	}
+	if counts[1] <= counts[2] || counts[2] == 0 { // the fastest one is preferred without herding
+		t.Fatalf("unexpected counts %v", counts)
+	}
+}
+
+func TestKeySlot(t *testing.T) {
+	if KeySlot("a") != 15495 || KeySlot("{a}.b") != 15495 || KeySlot("{}a") == KeySlot("a") {
+		t.Fatalf("unexpected slots")
+	}
+	if KeySlot("{user1000}.following") != KeySlot("{user1000}.followers") {
+		t.Fatalf("keys with the same hash tag should be in the same slot")
+	}
+}
+
+func TestSlotOwner(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	t.Run("cluster", func(t *testing.T) {
+		client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+			return &mockConn{
+				DoFn:   func(cmd Completed) ValkeyResult { return slotsMultiResp },
+				AddrFn: func() string { return dst },
+			}
+		}, newRetryer(defaultRetryDelayFn))
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		defer client.Close()
+		for _, c := range []struct {
+			addr string
+			slot uint16
+			ok   bool
+		}{
+			{slot: 0, addr: "127.0.0.1:0", ok: true},
+			{slot: KeySlot("b"), addr: "127.0.0.1:0", ok: true},
+			{slot: KeySlot("a"), addr: "127.0.2.1:0", ok: true},
+			{slot: 16384, addr: "", ok: false},
+		} {
+			if addr, ok := SlotOwner(client, c.slot); addr != c.addr || ok != c.ok {
+				t.Fatalf("unexpected owner of %v: %v %v", c.slot, addr, ok)
+			}
+		}
+	})
+	t.Run("single", func(t *testing.T) {
+		client := newSingleClientWithConn(&mockConn{AddrFn: func() string { return "127.0.0.1:6379" }}, intl.NewBuilder(intl.NoSlot), false, false, newRetryer(defaultRetryDelayFn), false)
+		if addr, ok := SlotOwner(client, KeySlot("a")); addr != "127.0.0.1:6379" || !ok {
+			t.Fatalf("unexpected owner %v %v", addr, ok)
+		}
+	})
+	t.Run("sharded", func(t *testing.T) {
+		client := newShardedTestClient(t, &shardedMock{})
+		defer client.Close()
+		keys := []string{"a", "b", "c", "d", "e", "f"}
+		for key, addr := range owners(client, keys) {
+			if owner, ok := SlotOwner(client, KeySlot(key)); owner != addr || !ok {
+				t.Fatalf("unexpected owner of %v: %v %v", key, owner, ok)
+			}
+		}
+		if addr, ok := SlotOwner(client, 16384); addr != "" || ok {
+			t.Fatalf("unexpected owner %v %v", addr, ok)
+		}
+	})
+	t.Run("wrapped", func(t *testing.T) {
+		client := newShardedTestClient(t, &shardedMock{})
+		defer client.Close()
+		wrapped := wrappedClient{Client: client}
+		for _, key := range []string{"a", "b", "c"} {
+			if addr, ok := SlotOwner(wrapped, KeySlot(key)); !ok || addr != client.shards[client.index(KeySlot(key))].client.(*singleClient).conn.Addr() {
+				t.Fatalf("unexpected owner of %v: %v %v", key, addr, ok)
+			}
+		}
+	})
+}
+
+// wrappedClient forwards the SlotOwner as the Client wrappers, such as the valkeyhook, do.
+type wrappedClient struct {
+	Client
+}
+
+func (w wrappedClient) SlotOwner(slot uint16) (string, bool) {
+	return SlotOwner(w.Client, slot)
+}
+
+func TestDoMultiBySlot(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+		return &mockConn{
+			DoFn:   func(cmd Completed) ValkeyResult { return slotsMultiResp },
+			AddrFn: func() string { return dst },
+			DoMultiFn: func(multi ...Completed) *valkeyresults {
+				mu.Lock()
+				calls[dst]++
+				mu.Unlock()
+				resps := make([]ValkeyResult, len(multi))
+				for i, cmd := range multi {
+					resps[i] = newResult(strmsg('+', cmd.Commands()[1]+"@"+dst), nil)
+				}
+				return &valkeyresults{s: resps}
+			},
+		}
+	}, newRetryer(defaultRetryDelayFn))
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+
+	if resps := DoMultiBySlot(client, context.Background(), []string{}, nil); resps != nil {
+		t.Fatalf("unexpected resps %v", resps)
+	}
+	keys := []string{"a", "b", "c", "d"}
+	resps := DoMultiBySlot(client, context.Background(), keys, func(key string) Completed {
+		return client.B().Get().Key(key).Build()
+	})
+	for i, expected := range []string{"a@127.0.2.1:0", "b@127.0.0.1:0", "c@127.0.0.1:0", "d@127.0.2.1:0"} {
+		if v, err := resps[i].ToString(); err != nil || v != expected {
+			t.Fatalf("unexpected resp %v %v", v, err)
+		}
+	}
+	if calls["127.0.0.1:0"] != 1 || calls["127.0.2.1:0"] != 1 {
+		t.Fatalf("each node should receive one pipeline, got %v", calls)
+	}
+
+	resps = DoMultiBySlot[string](wrappedClient{Client: client}, context.Background(), keys, func(key string) Completed {
+		return client.B().Get().Key(key).Build()
+	})
+	for i, expected := range []string{"a@127.0.2.1:0", "b@127.0.0.1:0", "c@127.0.0.1:0", "d@127.0.2.1:0"} {
+		if v, err := resps[i].ToString(); err != nil || v != expected {
+			t.Fatalf("unexpected resp %v %v", v, err)
+		}
+	}
+	if calls["127.0.0.1:0"] != 2 || calls["127.0.2.1:0"] != 2 {
+		t.Fatalf("each node should receive one pipeline, got %v", calls)
+	}
+}
+
+func scanResp(cursor string, elements ...string) ValkeyResult {
+	values := make([]ValkeyMessage, len(elements))
+	for i, e := range elements {
+		values[i] = strmsg('$', e)
+	}
+	return newResult(slicemsg('*', []ValkeyMessage{strmsg('$', cursor), slicemsg('*', values)}), nil)
+}
+
+func TestScanAll(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	m := &mockConn{}
+	client, err := newSingleClient(
+		&ClientOption{InitAddress: []string{""}},
+		m,
+		func(dst string, opt *ClientOption) conn { return m },
+		newRetryer(defaultRetryDelayFn),
+	)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+
+	t.Run("ScanAll", func(t *testing.T) {
+		m.DoFn = func(cmd Completed) ValkeyResult {
+			switch strings.Join(cmd.Commands(), " ") {
+			case "SCAN 0 MATCH k* COUNT 10 TYPE string":
+				return scanResp("5", "k1", "k2")
+			case "SCAN 5 MATCH k* COUNT 10 TYPE string":
+				return scanResp("0", "k3")
+			}
+			t.Fatalf("unexpected command %v", cmd.Commands())
+			return ValkeyResult{}
+		}
+		scanner := ScanAll(client, context.Background(), ScanArgs{Match: "k*", Count: 10, Type: "string"})
+		var keys []string
+		for k := range scanner.Iter() {
+			keys = append(keys, k)
+		}
+		if err := scanner.Err(); err != nil || !reflect.DeepEqual(keys, []string{"k1", "k2", "k3"}) {
+			t.Fatalf("unexpected keys %v %v", keys, err)
+		}
+	})
+
+	t.Run("HScanAll SScanAll ZScanAll", func(t *testing.T) {
+		for _, c := range []struct {
+			cmd string
+			fn  func(client Client, ctx context.Context, key string, args ScanArgs) *Scanner
+		}{
+			{cmd: "HSCAN", fn: HScanAll},
+			{cmd: "SSCAN", fn: SScanAll},
+			{cmd: "ZSCAN", fn: ZScanAll},
+		} {
+			m.DoFn = func(cmd Completed) ValkeyResult {
+				if cmd.Slot() != intl.Slot("key") {
+					t.Fatalf("unexpected slot %v", cmd.Slot())
+				}
+				switch strings.Join(cmd.Commands(), " ") {
+				case c.cmd + " key 0 MATCH f*":
+					return scanResp("3", "f1", "v1")
+				case c.cmd + " key 3 MATCH f*":
+					return scanResp("0", "f2", "v2")
+				}
+				t.Fatalf("unexpected command %v", cmd.Commands())
+				return ValkeyResult{}
+			}
+			scanner := c.fn(client, context.Background(), "key", ScanArgs{Match: "f*", Type: "ignored"})
+			fields := map[string]string{}
+			for k, v := range scanner.Iter2() {
+				fields[k] = v
+			}
+			if err := scanner.Err(); err != nil || !reflect.DeepEqual(fields, map[string]string{"f1": "v1", "f2": "v2"}) {
+				t.Fatalf("unexpected %v fields %v %v", c.cmd, fields, err)
+			}
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		m.DoFn = func(cmd Completed) ValkeyResult {
+			return newResult(strmsg('-', "ERR scan failed"), nil)
+		}
+		scanner := ScanAll(client, context.Background(), ScanArgs{})
+		for range scanner.Iter() {
+			t.Fatalf("should not yield")
+		}
+		if err := scanner.Err(); err == nil || err.Error() != "scan failed" {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
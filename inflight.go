@@ -0,0 +1,80 @@
+package valkey
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// inflight limits the number of in-flight commands of a pipelining connection.
+type inflight struct {
+	cond    *sync.Cond
+	n       atomic.Int64
+	waiters atomic.Int64
+	max     int64
+	wait    bool
+}
+
+func newInflight(max int, wait bool) *inflight {
+	return &inflight{cond: sync.NewCond(&sync.Mutex{}), max: int64(max), wait: wait}
+}
+
+// acquire reserves n in-flight commands. A request larger than the max is admitted only if there is nothing in-flight.
+// It returns ErrOverloaded immediately if the limit is reached, unless wait is set,
+// in which case it waits until enough commands are finished or the ctx is done.
+func (l *inflight) acquire(ctx context.Context, n int64) error {
+	if l.tryAcquire(n) {
+		return nil
+	}
+	if !l.wait {
+		return ErrOverloaded
+	}
+
+	l.cond.L.Lock()
+	defer l.cond.L.Unlock()
+
+	if ctx.Err() == nil && ctx.Done() != nil {
+		waitCtx, cancel := context.WithCancelCause(ctx)
+		defer cancel(errAcquireComplete)
+
+		go func() {
+			<-waitCtx.Done()
+			if context.Cause(waitCtx) != errAcquireComplete {
+				l.cond.L.Lock()
+				l.cond.Broadcast()
+				l.cond.L.Unlock()
+			}
+		}()
+	}
+
+	l.waiters.Add(1)
+	defer l.waiters.Add(-1)
+	for !l.tryAcquire(n) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		l.cond.Wait()
+	}
+	return nil
+}
+
+func (l *inflight) tryAcquire(n int64) bool {
+	for {
+		cur := l.n.Load()
+		if cur+n > l.max && cur != 0 {
+			return false
+		}
+		if l.n.CompareAndSwap(cur, cur+n) {
+			return true
+		}
+	}
+}
+
+func (l *inflight) release(n int64) {
+	l.n.Add(-n)
+	if l.waiters.Load() > 0 {
+		l.cond.L.Lock()
+		l.cond.Broadcast()
+		l.cond.L.Unlock()
+	}
+}
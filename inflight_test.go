@@ -0,0 +1,56 @@
+package valkey
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInflightFailFast(t *testing.T) {
+	l := newInflight(2, false)
+	if err := l.acquire(context.Background(), 2); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := l.acquire(context.Background(), 1); err != ErrOverloaded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	l.release(1)
+	if err := l.acquire(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	l.release(2)
+	if err := l.acquire(context.Background(), 5); err != nil { // oversize is admitted if idle
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := l.acquire(context.Background(), 1); err != ErrOverloaded {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestInflightWait(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	l := newInflight(1, true)
+	if err := l.acquire(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- l.acquire(context.Background(), 1)
+	}()
+	for l.waiters.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	l.release(1)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(ctx, 1); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if l.n.Load() != 1 {
+		t.Fatalf("unexpected in-flight %v", l.n.Load())
+	}
+}
@@ -16,10 +16,41 @@ const (
 	// goroutine commits directly to the client-side cache (no
 	// MULTI/EXEC unwrap). Set via Cacheable.ToStaticTTL().
 	staticTTLTag = uint16(1 << 6)
+	priorityTag  = uint16(1 << 5) // make command use the dedicated high priority pipeline
+	allNodesTag  = uint16(1 << 4) // request_policy:all_nodes, make command be broadcast to all nodes in cluster
+	allShardsTag = uint16(1 << 3) // request_policy:all_shards, make command be broadcast to all primaries in cluster
+	// multiShardTag is request_policy:multi_shard, make command be split by key slots in cluster
+	multiShardTag = allNodesTag | allShardsTag
+	respMask      = uint16(7) // the lowest 3 bits hold the response_policy of the command
 	// InitSlot indicates that the command be sent to any valkey node in cluster
 	InitSlot = uint16(1 << 14)
 	// NoSlot indicates that the command has no key slot specified
 	NoSlot = uint16(1 << 15)
+	// SplitSlot is the same as InitSlot except that multi_shard commands built with it are allowed to have keys of different slots
+	SplitSlot = InitSlot | 1
+	// CrossSlot indicates that the multi_shard command should be split by its key slots
+	CrossSlot = InitSlot | 2
+)
+
+// The response_policy tips of a command broadcast by its request_policy.
+// Ref: https://valkey.io/topics/command-tips/
+const (
+	// DefaultResponse concatenates array replies, otherwise returns the first non-nil reply.
+	DefaultResponse = uint16(iota)
+	// AllSucceeded returns an error reply if any, otherwise returns any one of the replies.
+	AllSucceeded
+	// OneSucceeded returns a non-error reply if any, otherwise returns an error reply.
+	OneSucceeded
+	// AggSum returns the sum of the integer replies.
+	AggSum
+	// AggLogicalAnd returns the logical AND of the integer replies, element-wise for array replies.
+	AggLogicalAnd
+	// AggLogicalOr returns the logical OR of the integer replies, element-wise for array replies.
+	AggLogicalOr
+	// AggMin returns the minimum of the integer replies.
+	AggMin
+	// AggMax returns the maximum of the integer replies.
+	AggMax
 )
 
 var (
@@ -90,6 +121,15 @@ var (
 		cs: newCommandSlice([]string{"UNSUBSCRIBE", "+sentinel", "+slave", "-sdown", "+sdown", "+switch-master", "+reboot"}),
 		cf: unsubTag,
 	}
+	// InvalidateSubscribe is predefined SUBSCRIBE __redis__:invalidate
+	InvalidateSubscribe = Completed{
+		cs: newCommandSlice([]string{"SUBSCRIBE", "__redis__:invalidate"}),
+		cf: noRetTag,
+	}
+	// ClientIDCmd is predefined CLIENT ID
+	ClientIDCmd = Completed{
+		cs: newCommandSlice([]string{"CLIENT", "ID"}),
+	}
 	// ClientTrackingOffCmd is predefined CLIENT TRACKING OFF
 	ClientTrackingOffCmd = Completed{
 		cs: newCommandSlice([]string{"CLIENT", "TRACKING", "OFF"}),
@@ -138,6 +178,12 @@ func (c Completed) ToRetryable() Completed {
 	return c
 }
 
+// ToPriority returns a new command with priorityTag
+func (c Completed) ToPriority() Completed {
+	c.cf |= priorityTag
+	return c
+}
+
 // IsEmpty checks if it is an empty command.
 func (c *Completed) IsEmpty() bool {
 	return c.cs == nil || len(c.cs.s) == 0
@@ -198,6 +244,31 @@ func (c *Completed) IsRetryable() bool {
 	return c.cf&retryableTag == retryableTag
 }
 
+// IsPriority checks if it is set priorityTag which prefers the high priority pipeline
+func (c *Completed) IsPriority() bool {
+	return c.cf&priorityTag == priorityTag
+}
+
+// IsAllNodes checks if it is set allNodesTag which should be broadcast to all nodes in cluster
+func (c *Completed) IsAllNodes() bool {
+	return c.cf&multiShardTag == allNodesTag
+}
+
+// IsAllShards checks if it is set allShardsTag which should be broadcast to all primaries in cluster
+func (c *Completed) IsAllShards() bool {
+	return c.cf&multiShardTag == allShardsTag
+}
+
+// IsMultiShard checks if it is set multiShardTag which can be split by key slots in cluster
+func (c *Completed) IsMultiShard() bool {
+	return c.cf&multiShardTag == multiShardTag
+}
+
+// ResponsePolicy returns the response_policy used to aggregate replies of the broadcast command
+func (c *Completed) ResponsePolicy() uint16 {
+	return c.cf & respMask
+}
+
 // Commands returns the commands as []string.
 // Note that the returned []string should not be modified
 // and should not be read after passing into the Client interface, because it will be recycled.
@@ -207,6 +278,9 @@ func (c *Completed) Commands() []string {
 
 // Slot returns the command key slot
 func (c *Completed) Slot() uint16 {
+	if c.ks == SplitSlot {
+		return InitSlot
+	}
 	return c.ks
 }
 
@@ -262,6 +336,9 @@ func (c Cacheable) ToStaticTTL() Cacheable {
 
 // Slot returns the command key slot
 func (c *Cacheable) Slot() uint16 {
+	if c.ks == SplitSlot {
+		return InitSlot
+	}
 	return c.ks
 }
 
@@ -355,6 +432,11 @@ func NewReadOnlyCompleted(ss []string) Completed {
 	return Completed{cs: newCommandSlice(ss), cf: readonly}
 }
 
+// NewSlotCompleted creates an arbitrary Completed command which is sent to the given slot.
+func NewSlotCompleted(ss []string, slot uint16) Completed {
+	return Completed{cs: newCommandSlice(ss), ks: slot}
+}
+
 // NewMGetCompleted creates an arbitrary readonly Completed command.
 func NewMGetCompleted(ss []string) Completed {
 	return Completed{cs: newCommandSlice(ss), cf: mtGetTag}
@@ -457,10 +539,58 @@ func NewMultiCompleted(cs [][]string) []Completed {
 	return ret
 }
 
+// SplitCrossSlot splits the CrossSlot command c into commands of each key slot, and
+// returns them along with the indexes of their keys in the c. It returns only one command
+// sharing the same underlying CommandSlice of the c if all keys belong to the same slot.
+func SplitCrossSlot(c Completed) (parts []Completed, indexes [][]int) {
+	step := 1
+	if c.cs.s[0] == "MSET" {
+		step = 2 // key value pairs
+	}
+	groups := make(map[uint16]int, 8)
+	for i := 1; i < len(c.cs.s); i += step {
+		ks := slot(c.cs.s[i])
+		g, ok := groups[ks]
+		if !ok {
+			g = len(parts)
+			groups[ks] = g
+			cs := get()
+			cs.s = append(cs.s, c.cs.s[0])
+			parts = append(parts, Completed{cs: cs, cf: c.cf, ks: ks})
+			indexes = append(indexes, nil)
+		}
+		parts[g].cs.s = append(parts[g].cs.s, c.cs.s[i:i+step]...)
+		indexes[g] = append(indexes[g], (i-1)/step)
+	}
+	if len(parts) > 1 {
+		for _, p := range parts {
+			p.cs.l = int32(len(p.cs.s))
+		}
+		return parts, indexes
+	}
+	ks := InitSlot
+	if len(parts) == 1 {
+		ks = parts[0].ks
+		Put(parts[0].cs)
+	}
+	return []Completed{{cs: c.cs, cf: c.cf, ks: ks}}, indexes
+}
+
+// toCrossSlot marks the multi_shard command built with SplitSlot to be split by its key slots.
+func toCrossSlot(ks uint16) uint16 {
+	if ks == SplitSlot {
+		return CrossSlot
+	}
+	return ks
+}
+
 func check(prev, new uint16) uint16 {
-	if prev == InitSlot || prev == new {
+	if prev == InitSlot || prev == SplitSlot || prev == new {
 		return new
 	}
+	if prev == CrossSlot {
+		return CrossSlot
+	}
 	panic(multiKeySlotErr)
 }
 
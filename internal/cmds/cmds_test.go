@@ -110,6 +110,16 @@ func TestCompleted_ToBlock(t *testing.T) {
 	}
 }
 
+func TestCompleted_ToPriority(t *testing.T) {
+	cmd := NewCompleted([]string{"a", "b"})
+	if cmd.IsPriority() {
+		t.Fatalf("should not be priority command")
+	}
+	if cmd = cmd.ToPriority(); !cmd.IsPriority() || cmd.IsBlock() || cmd.IsReadOnly() {
+		t.Fatalf("should be priority command only")
+	}
+}
+
 func TestCompleted_IsOptIn(t *testing.T) {
 	if cmd := NewCompleted([]string{"a", "b"}); cmd.IsOptIn() {
 		t.Fatalf("should not be opt-in command")
@@ -216,6 +226,9 @@ func TestComplete_IsReadOnly(t *testing.T) {
 	if cmd := NewReadOnlyCompleted([]string{"a", "b"}); cmd.IsWrite() {
 		t.Fatalf("should not be write command")
 	}
+	if cmd := NewSlotCompleted([]string{"a", "b"}, 100); cmd.IsReadOnly() || cmd.Slot() != 100 {
+		t.Fatalf("should be command of slot 100")
+	}
 }
 
 func TestNewMultiCompleted(t *testing.T) {
@@ -427,3 +440,69 @@ func TestCacheableCS(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestCompleted_Policy(t *testing.T) {
+	builder := NewBuilder(InitSlot)
+	if cmd := builder.Dbsize().Build(); !cmd.IsAllShards() || cmd.IsAllNodes() || cmd.ResponsePolicy() != AggSum {
+		t.Fatalf("DBSIZE should be all_shards and agg_sum")
+	}
+	if cmd := builder.ScriptFlush().Build(); cmd.IsAllShards() || !cmd.IsAllNodes() || cmd.ResponsePolicy() != AllSucceeded {
+		t.Fatalf("SCRIPT FLUSH should be all_nodes and all_succeeded")
+	}
+	if cmd := builder.Keys().Pattern("*").Build(); !cmd.IsAllShards() || !cmd.IsReadOnly() || cmd.ResponsePolicy() != DefaultResponse {
+		t.Fatalf("KEYS should be readonly and all_shards")
+	}
+	if cmd := builder.Get().Key("a").Build(); cmd.IsAllShards() || cmd.IsAllNodes() || cmd.ResponsePolicy() != DefaultResponse {
+		t.Fatalf("GET should not have policies")
+	}
+	if cmd := builder.Del().Key("a").Build(); !cmd.IsMultiShard() || cmd.IsAllShards() || cmd.IsAllNodes() || cmd.ResponsePolicy() != AggSum {
+		t.Fatalf("DEL should be multi_shard and agg_sum")
+	}
+	if cmd := builder.Mset().KeyValue().KeyValue("a", "1").Build(); !cmd.IsMultiShard() || cmd.ResponsePolicy() != AllSucceeded {
+		t.Fatalf("MSET should be multi_shard and all_succeeded")
+	}
+}
+
+func TestCompleted_SplitSlot(t *testing.T) {
+	builder := NewBuilder(SplitSlot)
+	if cmd := builder.Ping().Build(); cmd.Slot() != InitSlot {
+		t.Fatalf("unexpected slot %v", cmd.Slot())
+	}
+	if cmd := builder.Get().Key("a").Build(); cmd.Slot() != slot("a") {
+		t.Fatalf("unexpected slot %v", cmd.Slot())
+	}
+	if cmd := builder.Mget().Key("a").Key("b").Build(); cmd.Slot() != CrossSlot {
+		t.Fatalf("unexpected slot %v", cmd.Slot())
+	}
+	if cmd := builder.Del().Key("{a}1").Build(); cmd.Slot() != CrossSlot {
+		t.Fatalf("unexpected slot %v", cmd.Slot())
+	}
+	t.Run("Split", func(t *testing.T) {
+		parts, indexes := SplitCrossSlot(builder.Mset().KeyValue().KeyValue("a", "1").KeyValue("b", "2").KeyValue("{a}c", "3").Build())
+		if len(parts) != 2 || !reflect.DeepEqual(indexes, [][]int{{0, 2}, {1}}) {
+			t.Fatalf("unexpected parts %v %v", parts, indexes)
+		}
+		if !reflect.DeepEqual(parts[0].Commands(), []string{"MSET", "a", "1", "{a}c", "3"}) || parts[0].Slot() != slot("a") || !parts[0].IsMultiShard() {
+			t.Fatalf("unexpected part %v", parts[0].Commands())
+		}
+		if !reflect.DeepEqual(parts[1].Commands(), []string{"MSET", "b", "2"}) || parts[1].Slot() != slot("b") {
+			t.Fatalf("unexpected part %v", parts[1].Commands())
+		}
+		parts[1].cs.Verify()
+	})
+	t.Run("Same Slot", func(t *testing.T) {
+		cmd := builder.Exists().Key("{a}1", "{a}2").Build()
+		parts, indexes := SplitCrossSlot(cmd)
+		if len(parts) != 1 || parts[0].cs != cmd.cs || parts[0].Slot() != slot("a") || !parts[0].IsReadOnly() || !reflect.DeepEqual(indexes, [][]int{{0, 1}}) {
+			t.Fatalf("unexpected parts %v %v", parts, indexes)
+		}
+	})
+	t.Run("Panic Cross Slot", func(t *testing.T) {
+		defer func() {
+			if !strings.Contains(recover().(string), multiKeySlotErr) {
+				t.Fatal("cross key slot not panic as expected")
+			}
+		}()
+		builder.Sinter().Key("a", "b")
+	})
+}
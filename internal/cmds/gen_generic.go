@@ -73,7 +73,7 @@ func (c CopySource) Destination(destination string) CopyDestination {
 type Del Incomplete
 
 func (b Builder) Del() (c Del) {
-	c = Del{cs: get(), ks: b.ks}
+	c = Del{cs: get(), ks: toCrossSlot(b.ks), cf: int16(multiShardTag | AggSum)}
 	c.cs.s = append(c.cs.s, "DEL")
 	return c
 }
@@ -143,7 +143,7 @@ func (c DumpKey) Build() Completed {
 type Exists Incomplete
 
 func (b Builder) Exists() (c Exists) {
-	c = Exists{cs: get(), ks: b.ks, cf: int16(readonly)}
+	c = Exists{cs: get(), ks: toCrossSlot(b.ks), cf: int16(readonly | multiShardTag | AggSum)}
 	c.cs.s = append(c.cs.s, "EXISTS")
 	return c
 }
@@ -378,7 +378,7 @@ func (c ExpiretimeKey) Cache() Cacheable {
 type Keys Incomplete
 
 func (b Builder) Keys() (c Keys) {
-	c = Keys{cs: get(), ks: b.ks, cf: int16(readonly)}
+	c = Keys{cs: get(), ks: b.ks, cf: int16(readonly | allShardsTag)}
 	c.cs.s = append(c.cs.s, "KEYS")
 	return c
 }
@@ -1022,7 +1022,7 @@ func (c PttlKey) Cache() Cacheable {
 type Randomkey Incomplete
 
 func (b Builder) Randomkey() (c Randomkey) {
-	c = Randomkey{cs: get(), ks: b.ks, cf: int16(readonly)}
+	c = Randomkey{cs: get(), ks: b.ks, cf: int16(readonly | allShardsTag)}
 	c.cs.s = append(c.cs.s, "RANDOMKEY")
 	return c
 }
@@ -1747,7 +1747,7 @@ func (c SortStore) Build() Completed {
 type Touch Incomplete
 
 func (b Builder) Touch() (c Touch) {
-	c = Touch{cs: get(), ks: b.ks, cf: int16(readonly)}
+	c = Touch{cs: get(), ks: toCrossSlot(b.ks), cf: int16(readonly | multiShardTag | AggSum)}
 	c.cs.s = append(c.cs.s, "TOUCH")
 	return c
 }
@@ -1852,7 +1852,7 @@ func (c TypeKey) Cache() Cacheable {
 type Unlink Incomplete
 
 func (b Builder) Unlink() (c Unlink) {
-	c = Unlink{cs: get(), ks: b.ks}
+	c = Unlink{cs: get(), ks: toCrossSlot(b.ks), cf: int16(multiShardTag | AggSum)}
 	c.cs.s = append(c.cs.s, "UNLINK")
 	return c
 }
@@ -568,7 +568,7 @@ func (c FcallRoNumkeys) Cache() Cacheable {
 type FunctionDelete Incomplete
 
 func (b Builder) FunctionDelete() (c FunctionDelete) {
-	c = FunctionDelete{cs: get(), ks: b.ks}
+	c = FunctionDelete{cs: get(), ks: b.ks, cf: int16(allShardsTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "FUNCTION", "DELETE")
 	return c
 }
@@ -601,7 +601,7 @@ func (c FunctionDump) Build() Completed {
 type FunctionFlush Incomplete
 
 func (b Builder) FunctionFlush() (c FunctionFlush) {
-	c = FunctionFlush{cs: get(), ks: b.ks}
+	c = FunctionFlush{cs: get(), ks: b.ks, cf: int16(allShardsTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "FUNCTION", "FLUSH")
 	return c
 }
@@ -651,7 +651,7 @@ func (c FunctionHelp) Build() Completed {
 type FunctionKill Incomplete
 
 func (b Builder) FunctionKill() (c FunctionKill) {
-	c = FunctionKill{cs: get(), ks: b.ks}
+	c = FunctionKill{cs: get(), ks: b.ks, cf: int16(allShardsTag | OneSucceeded)}
 	c.cs.s = append(c.cs.s, "FUNCTION", "KILL")
 	return c
 }
@@ -706,7 +706,7 @@ func (c FunctionListWithcode) Build() Completed {
 type FunctionLoad Incomplete
 
 func (b Builder) FunctionLoad() (c FunctionLoad) {
-	c = FunctionLoad{cs: get(), ks: b.ks}
+	c = FunctionLoad{cs: get(), ks: b.ks, cf: int16(allShardsTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "FUNCTION", "LOAD")
 	return c
 }
@@ -738,7 +738,7 @@ func (c FunctionLoadReplace) FunctionCode(functionCode string) FunctionLoadFunct
 type FunctionRestore Incomplete
 
 func (b Builder) FunctionRestore() (c FunctionRestore) {
-	c = FunctionRestore{cs: get(), ks: b.ks}
+	c = FunctionRestore{cs: get(), ks: b.ks, cf: int16(allShardsTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "FUNCTION", "RESTORE")
 	return c
 }
@@ -851,7 +851,7 @@ func (c ScriptDebugModeYes) Build() Completed {
 type ScriptExists Incomplete
 
 func (b Builder) ScriptExists() (c ScriptExists) {
-	c = ScriptExists{cs: get(), ks: b.ks}
+	c = ScriptExists{cs: get(), ks: b.ks, cf: int16(allShardsTag | AggLogicalAnd)}
 	c.cs.s = append(c.cs.s, "SCRIPT", "EXISTS")
 	return c
 }
@@ -876,7 +876,7 @@ func (c ScriptExistsSha1) Build() Completed {
 type ScriptFlush Incomplete
 
 func (b Builder) ScriptFlush() (c ScriptFlush) {
-	c = ScriptFlush{cs: get(), ks: b.ks}
+	c = ScriptFlush{cs: get(), ks: b.ks, cf: int16(allNodesTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "SCRIPT", "FLUSH")
 	return c
 }
@@ -913,7 +913,7 @@ func (c ScriptFlushAsyncSync) Build() Completed {
 type ScriptKill Incomplete
 
 func (b Builder) ScriptKill() (c ScriptKill) {
-	c = ScriptKill{cs: get(), ks: b.ks}
+	c = ScriptKill{cs: get(), ks: b.ks, cf: int16(allShardsTag | OneSucceeded)}
 	c.cs.s = append(c.cs.s, "SCRIPT", "KILL")
 	return c
 }
@@ -926,7 +926,7 @@ func (c ScriptKill) Build() Completed {
 type ScriptLoad Incomplete
 
 func (b Builder) ScriptLoad() (c ScriptLoad) {
-	c = ScriptLoad{cs: get(), ks: b.ks}
+	c = ScriptLoad{cs: get(), ks: b.ks, cf: int16(allNodesTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "SCRIPT", "LOAD")
 	return c
 }
@@ -570,7 +570,7 @@ func (c ConfigGetParameter) Build() Completed {
 type ConfigResetstat Incomplete
 
 func (b Builder) ConfigResetstat() (c ConfigResetstat) {
-	c = ConfigResetstat{cs: get(), ks: b.ks}
+	c = ConfigResetstat{cs: get(), ks: b.ks, cf: int16(allNodesTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "CONFIG", "RESETSTAT")
 	return c
 }
@@ -583,7 +583,7 @@ func (c ConfigResetstat) Build() Completed {
 type ConfigRewrite Incomplete
 
 func (b Builder) ConfigRewrite() (c ConfigRewrite) {
-	c = ConfigRewrite{cs: get(), ks: b.ks}
+	c = ConfigRewrite{cs: get(), ks: b.ks, cf: int16(allNodesTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "CONFIG", "REWRITE")
 	return c
 }
@@ -596,7 +596,7 @@ func (c ConfigRewrite) Build() Completed {
 type ConfigSet Incomplete
 
 func (b Builder) ConfigSet() (c ConfigSet) {
-	c = ConfigSet{cs: get(), ks: b.ks}
+	c = ConfigSet{cs: get(), ks: b.ks, cf: int16(allNodesTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "CONFIG", "SET")
 	return c
 }
@@ -620,7 +620,7 @@ func (c ConfigSetParameterValue) Build() Completed {
 type Dbsize Incomplete
 
 func (b Builder) Dbsize() (c Dbsize) {
-	c = Dbsize{cs: get(), ks: b.ks, cf: int16(readonly)}
+	c = Dbsize{cs: get(), ks: b.ks, cf: int16(readonly | allShardsTag | AggSum)}
 	c.cs.s = append(c.cs.s, "DBSIZE")
 	return c
 }
@@ -771,7 +771,7 @@ func (c FailoverTimeout) Build() Completed {
 type Flushall Incomplete
 
 func (b Builder) Flushall() (c Flushall) {
-	c = Flushall{cs: get(), ks: b.ks}
+	c = Flushall{cs: get(), ks: b.ks, cf: int16(allShardsTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "FLUSHALL")
 	return c
 }
@@ -808,7 +808,7 @@ func (c FlushallAsyncSync) Build() Completed {
 type Flushdb Incomplete
 
 func (b Builder) Flushdb() (c Flushdb) {
-	c = Flushdb{cs: get(), ks: b.ks}
+	c = Flushdb{cs: get(), ks: b.ks, cf: int16(allShardsTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "FLUSHDB")
 	return c
 }
@@ -1233,7 +1233,7 @@ func (c LatencyLatest) Build() Completed {
 type LatencyReset Incomplete
 
 func (b Builder) LatencyReset() (c LatencyReset) {
-	c = LatencyReset{cs: get(), ks: b.ks}
+	c = LatencyReset{cs: get(), ks: b.ks, cf: int16(allNodesTag | AggSum)}
 	c.cs.s = append(c.cs.s, "LATENCY", "RESET")
 	return c
 }
@@ -1327,7 +1327,7 @@ func (c MemoryMallocStats) Build() Completed {
 type MemoryPurge Incomplete
 
 func (b Builder) MemoryPurge() (c MemoryPurge) {
-	c = MemoryPurge{cs: get(), ks: b.ks}
+	c = MemoryPurge{cs: get(), ks: b.ks, cf: int16(allShardsTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "MEMORY", "PURGE")
 	return c
 }
@@ -1872,7 +1872,7 @@ func (c SlowlogHelp) Build() Completed {
 type SlowlogLen Incomplete
 
 func (b Builder) SlowlogLen() (c SlowlogLen) {
-	c = SlowlogLen{cs: get(), ks: b.ks, cf: int16(readonly)}
+	c = SlowlogLen{cs: get(), ks: b.ks, cf: int16(readonly | allNodesTag | AggSum)}
 	c.cs.s = append(c.cs.s, "SLOWLOG", "LEN")
 	return c
 }
@@ -1885,7 +1885,7 @@ func (c SlowlogLen) Build() Completed {
 type SlowlogReset Incomplete
 
 func (b Builder) SlowlogReset() (c SlowlogReset) {
-	c = SlowlogReset{cs: get(), ks: b.ks}
+	c = SlowlogReset{cs: get(), ks: b.ks, cf: int16(allNodesTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "SLOWLOG", "RESET")
 	return c
 }
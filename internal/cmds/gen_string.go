@@ -1074,7 +1074,7 @@ func (c LcsWithmatchlen) Build() Completed {
 type Mget Incomplete
 
 func (b Builder) Mget() (c Mget) {
-	c = Mget{cs: get(), ks: b.ks, cf: int16(mtGetTag)}
+	c = Mget{cs: get(), ks: toCrossSlot(b.ks), cf: int16(mtGetTag | multiShardTag)}
 	c.cs.s = append(c.cs.s, "MGET")
 	return c
 }
@@ -1124,7 +1124,7 @@ func (c MgetKey) Cache() Cacheable {
 type Mset Incomplete
 
 func (b Builder) Mset() (c Mset) {
-	c = Mset{cs: get(), ks: b.ks}
+	c = Mset{cs: get(), ks: toCrossSlot(b.ks), cf: int16(multiShardTag | AllSucceeded)}
 	c.cs.s = append(c.cs.s, "MSET")
 	return c
 }
@@ -0,0 +1,135 @@
+package valkey
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valkey-io/valkey-go/internal/cmds"
+)
+
+const defaultReplicaLagInterval = time.Second
+
+// sampler calls the sample every interval until it is closed. The first sample is taken immediately if the immediate is true.
+type sampler struct {
+	stop chan struct{}
+	once sync.Once
+}
+
+func newSampler(interval time.Duration, immediate bool, sample func(ctx context.Context)) *sampler {
+	s := &sampler{stop: make(chan struct{})}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for run := immediate; ; run = true {
+			if run {
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				sample(ctx)
+				cancel()
+			}
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+func newLagSampler(opt *ReplicaLagOption, sample func(ctx context.Context)) *sampler {
+	interval := opt.Interval
+	if interval <= 0 {
+		interval = defaultReplicaLagInterval
+	}
+	return newSampler(interval, true, sample)
+}
+
+func (s *sampler) Close() {
+	if s != nil {
+		s.once.Do(func() { close(s.stop) })
+	}
+}
+
+// newLag returns the lag of a replica not sampled yet, which keeps it from serving read-only commands until it is sampled.
+func newLag() *atomic.Int64 {
+	lag := &atomic.Int64{}
+	lag.Store(math.MaxInt64)
+	return lag
+}
+
+// replOffset returns the replication offset of the node by the ROLE command.
+// It returns false if the node is a replica not connected to its primary.
+func replOffset(ctx context.Context, cc conn) (int64, bool) {
+	resp, err := cc.Do(ctx, cmds.RoleCmd).ToArray()
+	if err != nil || len(resp) < 2 {
+		return 0, false
+	}
+	var offset ValkeyMessage
+	switch resp[0].string() {
+	case "master":
+		offset = resp[1]
+	case "slave":
+		if len(resp) < 5 || resp[3].string() != "connected" {
+			return 0, false
+		}
+		offset = resp[4]
+	default:
+		return 0, false
+	}
+	v, err := offset.AsInt64()
+	return v, err == nil
+}
+
+// sampleLags samples the replication offsets of the primary and its replicas, and stores the lags into the replicas.
+// The lags are left unchanged if the primary fails to be sampled.
+func sampleLags(ctx context.Context, primary conn, replicas []NodeInfo) {
+	po, ok := replOffset(ctx, primary)
+	if !ok {
+		return
+	}
+	for _, replica := range replicas {
+		lag := int64(math.MaxInt64)
+		if ro, ok := replOffset(ctx, replica.conn); ok {
+			lag = max(po-ro, 0)
+		}
+		replica.lag.Store(lag)
+	}
+}
+
+// pickFresh returns the index of the nodes selected by the selector among nodes lagging behind no more than the maxLag.
+// It returns -1 if all the nodes lag behind more than the maxLag.
+func pickFresh(slot uint16, nodes []NodeInfo, selector ReadNodeSelectorFunc, maxLag int64) int {
+	if len(nodes) == 0 {
+		return -1
+	}
+	i := 0
+	if selector != nil {
+		if i = selector(slot, nodes); i < 0 || i >= len(nodes) {
+			i = 0
+		}
+	}
+	if nodes[i].Lag() <= maxLag {
+		return i
+	}
+	fresh := make([]NodeInfo, 0, len(nodes))
+	index := make([]int, 0, len(nodes))
+	for i, node := range nodes {
+		if node.Lag() <= maxLag {
+			fresh = append(fresh, node)
+			index = append(index, i)
+		}
+	}
+	if len(fresh) == 0 {
+		return -1
+	}
+	i = 0
+	if selector != nil {
+		if i = selector(slot, fresh); i < 0 || i >= len(fresh) {
+			i = 0
+		}
+	}
+	return index[i]
+}
@@ -0,0 +1,100 @@
+package valkey
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valkey-io/valkey-go/internal/cmds"
+)
+
+func roleConn(role ...ValkeyMessage) *mockConn {
+	return &mockConn{
+		DoFn: func(cmd Completed) ValkeyResult {
+			if cmd != cmds.RoleCmd {
+				return newResult(strmsg('+', "OK"), nil)
+			}
+			if len(role) == 0 {
+				return newErrResult(context.DeadlineExceeded)
+			}
+			return newResult(slicemsg('*', role), nil)
+		},
+	}
+}
+
+func primaryRole(offset int64) *mockConn {
+	return roleConn(strmsg('+', "master"), ValkeyMessage{typ: ':', intlen: offset}, slicemsg('*', nil))
+}
+
+func replicaRole(state string, offset int64) *mockConn {
+	return roleConn(strmsg('+', "slave"), strmsg('+', "127.0.0.1"), ValkeyMessage{typ: ':', intlen: 6379}, strmsg('+', state), ValkeyMessage{typ: ':', intlen: offset})
+}
+
+func TestSampleLags(t *testing.T) {
+	replicas := []NodeInfo{
+		{conn: replicaRole("connected", 90), lag: &atomic.Int64{}},
+		{conn: replicaRole("connected", 110), lag: &atomic.Int64{}},
+		{conn: replicaRole("connecting", 100), lag: &atomic.Int64{}},
+		{conn: roleConn(), lag: &atomic.Int64{}},
+	}
+	sampleLags(context.Background(), primaryRole(100), replicas)
+	for i, lag := range []int64{10, 0, math.MaxInt64, math.MaxInt64} {
+		if replicas[i].Lag() != lag {
+			t.Fatalf("unexpected lag %v of replica %d", replicas[i].Lag(), i)
+		}
+	}
+
+	replicas[0].lag.Store(1)
+	sampleLags(context.Background(), roleConn(), replicas[:1])
+	if replicas[0].Lag() != 1 {
+		t.Fatalf("lags should be unchanged if the primary fails to be sampled")
+	}
+	if (NodeInfo{}).Lag() != 0 {
+		t.Fatalf("unexpected lag of a primary")
+	}
+	if (NodeInfo{lag: newLag()}).Lag() != math.MaxInt64 {
+		t.Fatalf("unexpected lag of a replica not sampled")
+	}
+	if i := pickFresh(0, []NodeInfo{{lag: newLag()}}, nil, 10); i != -1 {
+		t.Fatalf("a replica not sampled should be excluded")
+	}
+}
+
+func TestPickFresh(t *testing.T) {
+	lag := func(v int64) *atomic.Int64 {
+		l := &atomic.Int64{}
+		l.Store(v)
+		return l
+	}
+	nodes := []NodeInfo{{Addr: "0"}, {Addr: "1", lag: lag(100)}, {Addr: "2", lag: lag(5)}}
+	last := func(_ uint16, nodes []NodeInfo) int { return len(nodes) - 1 }
+	if i := pickFresh(0, nodes, last, 10); i != 2 {
+		t.Fatalf("unexpected index %v", i)
+	}
+	if i := pickFresh(0, nodes[:2], last, 10); i != 0 {
+		t.Fatalf("unexpected index %v", i)
+	}
+	if i := pickFresh(0, nodes[1:2], last, 10); i != -1 {
+		t.Fatalf("unexpected index %v", i)
+	}
+	if i := pickFresh(0, nodes[1:], nil, 10); i != 1 {
+		t.Fatalf("unexpected index %v", i)
+	}
+	if i := pickFresh(0, nil, nil, 10); i != -1 {
+		t.Fatalf("unexpected index %v", i)
+	}
+}
+
+func TestLagSampler(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var n atomic.Int32
+	s := newLagSampler(&ReplicaLagOption{Interval: time.Millisecond}, func(ctx context.Context) { n.Add(1) })
+	for n.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	s.Close()
+	s.Close()
+	(*sampler)(nil).Close()
+}
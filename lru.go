@@ -3,6 +3,7 @@ package valkey
 import (
 	"container/list"
 	"context"
+	"hash/maphash"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,12 +25,19 @@ const (
 )
 
 type cacheEntry struct {
-	err  error
-	ch   chan struct{}
-	kc   *keyCache
-	cmd  string
-	val  ValkeyMessage
-	size int
+	err   error
+	ch    chan struct{}
+	kc    *keyCache
+	owner *sharedLRU // the connection fetched the entry if the lru is shared
+	cmd   string
+	val   ValkeyMessage
+	stale ValkeyMessage // the expired value kept by the pending entry for stale reads. See WithCacheStale.
+	size  int
+	hash  uint64 // the hash of the key and the cmd for the CachePolicy
+	main  bool   // the entry is admitted from the window to the main list
+	// invalid is set if the pending entry is invalidated before its reply, which can happen if invalidations are redirected
+	// to another connection over RESP2. Its reply is delivered to the waiters without being cached, because it may be stale.
+	invalid bool
 }
 
 func (e *cacheEntry) Wait(ctx context.Context) (ValkeyMessage, error) {
@@ -55,53 +63,138 @@ type keyCache struct {
 var _ CacheStore = (*lru)(nil)
 
 type lru struct {
-	store map[string]*keyCache
-	list  *list.List
-	mu    sync.RWMutex
-	size  int
-	max   int
+	policy    CachePolicy
+	store     map[string]*keyCache
+	list      *list.List
+	window    *list.List // the list of new entries if the policy has a window
+	seed      maphash.Seed
+	mu        sync.RWMutex
+	size      int
+	max       int
+	ratio     float64 // the window ratio of the policy
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
 }
 
 func newLRU(opt CacheStoreOption) CacheStore {
-	return &lru{
+	c := &lru{
 		max:   opt.CacheSizeEachConn,
 		store: make(map[string]*keyCache),
 		list:  list.New(),
 	}
+	c.setPolicy(opt.CachePolicy)
+	return c
+}
+
+func (c *lru) setPolicy(policy CachePolicy) {
+	if c.policy = policy; policy != nil {
+		c.seed = maphash.MakeSeed()
+		if c.ratio = min(policy.Window(), 1); c.ratio > 0 {
+			c.window = list.New()
+		}
+	}
+}
+
+// listOf returns the list holding the entry. New entries are held by the window until they are admitted to the main list.
+func (c *lru) listOf(e *cacheEntry) *list.List {
+	if e.main || c.window == nil {
+		return c.list
+	}
+	return c.window
+}
+
+func (c *lru) pushBack(e *cacheEntry) *list.Element {
+	if c.policy != nil {
+		e.hash = c.hash(e.kc.key, e.cmd)
+	}
+	return c.listOf(e).PushBack(e)
+}
+
+func (c *lru) moveToBack(ele *list.Element) {
+	if c.list != nil {
+		c.listOf(ele.Value.(*cacheEntry)).MoveToBack(ele)
+	}
+}
+
+func (c *lru) remove(ele *list.Element) {
+	c.listOf(ele.Value.(*cacheEntry)).Remove(ele)
+}
+
+func (c *lru) hash(key, cmd string) uint64 {
+	var h maphash.Hash
+	h.SetSeed(c.seed)
+	h.WriteString(key)
+	h.WriteString(cmd)
+	return h.Sum64()
+}
+
+// hit records a lookup served by the entry, including joining its pending request.
+func (c *lru) hit(kc *keyCache, e *cacheEntry) uint32 {
+	c.hits.Add(1)
+	if c.policy != nil {
+		c.policy.Record(e.hash)
+	}
+	return atomic.AddUint32(&kc.hits, 1)
+}
+
+// miss records a lookup of the key and the cmd requiring a request to valkey.
+func (c *lru) miss(kc *keyCache, cmd string) {
+	c.misses.Add(1)
+	if c.policy != nil {
+		c.policy.Record(c.hash(kc.key, cmd))
+	}
+	atomic.AddUint32(&kc.miss, 1)
 }
 
 func (c *lru) Flight(key, cmd string, ttl time.Duration, now time.Time) (v ValkeyMessage, ce CacheEntry) {
+	v, ce, _ = c.flight(nil, key, cmd, ttl, now, 0)
+	return v, ce
+}
+
+func (c *lru) flightStale(key, cmd string, ttl time.Duration, now time.Time, window time.Duration) (ValkeyMessage, CacheEntry, ValkeyMessage) {
+	return c.flight(nil, key, cmd, ttl, now, window)
+}
+
+// flight is the Flight that also returns the stale value if the entry is pending or missed, and its value expired within the window.
+// The value expired within the window is kept by the new pending entry until the entry is updated, invalidated, or canceled.
+// After canceled, the value is put back to the cache for later stale reads.
+func (c *lru) flight(owner *sharedLRU, key, cmd string, ttl time.Duration, now time.Time, window time.Duration) (v ValkeyMessage, ce CacheEntry, stale ValkeyMessage) {
 	var ok bool
 	var kc *keyCache
 	var ele, back *list.Element
 	var e *cacheEntry
+	var size int
 
 	c.mu.RLock()
 	if kc, ok = c.store[key]; ok {
 		if ele = kc.cache[cmd]; ele != nil {
 			e = ele.Value.(*cacheEntry)
 			v = e.val
-			back = c.list.Back()
+			stale = e.stale
+			back = c.listOf(e).Back()
 		}
 	}
 	c.mu.RUnlock()
 
 	if e != nil && (v.typ == 0 || v.relativePTTL(now) > 0) {
-		hits := atomic.AddUint32(&kc.hits, 1)
+		hits := c.hit(kc, e)
 		if ele != back && hits&moveThreshold == 0 {
 			c.mu.Lock()
-			if c.list != nil {
-				c.list.MoveToBack(ele)
-			}
+			c.moveToBack(ele)
 			c.mu.Unlock()
 		}
-		return v, e
+		return v, e, staleWithin(stale, now, window)
 	}
 
 	v = ValkeyMessage{}
 	e = nil
+	stale = ValkeyMessage{}
 
 	c.mu.Lock()
+	if owner != nil && owner.closed {
+		goto ret
+	}
 	if kc, ok = c.store[key]; !ok {
 		if c.store == nil {
 			goto ret
@@ -111,25 +204,39 @@ func (c *lru) Flight(key, cmd string, ttl time.Duration, now time.Time) (v Valke
 	}
 	if ele = kc.cache[cmd]; ele != nil {
 		if e = ele.Value.(*cacheEntry); e.val.typ == 0 || e.val.relativePTTL(now) > 0 {
-			atomic.AddUint32(&kc.hits, 1)
+			c.hit(kc, e)
 			v = e.val
-			c.list.MoveToBack(ele)
+			stale = staleWithin(e.stale, now, window)
+			c.moveToBack(ele)
 			ce = e
 			goto ret
 		} else {
-			c.list.Remove(ele)
-			c.size -= e.size
+			c.remove(ele)
+			if stale = staleWithin(e.val, now, window); stale.typ == 0 {
+				c.size -= e.size
+			} else {
+				size = e.size // the size is taken over by the new pending entry
+			}
 		}
 	}
-	atomic.AddUint32(&kc.miss, 1)
+	c.miss(kc, cmd)
 	v.setExpireAt(now.Add(ttl).UnixMilli())
-	kc.cache[cmd] = c.list.PushBack(&cacheEntry{cmd: cmd, kc: kc, val: v, ch: make(chan struct{})})
+	kc.cache[cmd] = c.pushBack(&cacheEntry{cmd: cmd, kc: kc, val: v, stale: stale, size: size, ch: make(chan struct{}), owner: owner})
 ret:
 	c.mu.Unlock()
-	return v, ce
+	return v, ce, stale
 }
 
 func (c *lru) Flights(now time.Time, multi []CacheableTTL, results []ValkeyResult, entries map[int]CacheEntry) (missed []int) {
+	return c.flights(nil, now, multi, results, entries, 0, nil)
+}
+
+func (c *lru) flightsStale(now time.Time, multi []CacheableTTL, results []ValkeyResult, entries map[int]CacheEntry, window time.Duration, stales []ValkeyMessage) []int {
+	return c.flights(nil, now, multi, results, entries, window, stales)
+}
+
+// flights is the Flights that also stores the stale value of each pending or missed entry into the stales if it is not nil. See flight.
+func (c *lru) flights(owner *sharedLRU, now time.Time, multi []CacheableTTL, results []ValkeyResult, entries map[int]CacheEntry, window time.Duration, stales []ValkeyMessage) (missed []int) {
 	var moves []*list.Element
 
 	c.mu.RLock()
@@ -141,12 +248,15 @@ func (c *lru) Flights(now time.Time, multi []CacheableTTL, results []ValkeyResul
 				v := e.val
 				if v.typ == 0 {
 					entries[i] = e
+					if stales != nil {
+						stales[i] = staleWithin(e.stale, now, window)
+					}
 				} else if v.relativePTTL(now) > 0 {
 					results[i] = newResult(v, nil)
 				} else {
 					goto miss1
 				}
-				if atomic.AddUint32(&kc.hits, 1)&moveThreshold == 0 {
+				if c.hit(kc, e)&moveThreshold == 0 {
 					if moves == nil {
 						moves = make([]*list.Element, 0, len(multi))
 					}
@@ -165,10 +275,8 @@ func (c *lru) Flights(now time.Time, multi []CacheableTTL, results []ValkeyResul
 
 	if len(moves) > 0 {
 		c.mu.Lock()
-		if c.list != nil {
-			for _, ele := range moves {
-				c.list.MoveToBack(ele)
-			}
+		for _, ele := range moves {
+			c.moveToBack(ele)
 		}
 		c.mu.Unlock()
 	}
@@ -179,7 +287,7 @@ func (c *lru) Flights(now time.Time, multi []CacheableTTL, results []ValkeyResul
 
 	j := 0
 	c.mu.Lock()
-	if c.store == nil {
+	if c.store == nil || (owner != nil && owner.closed) {
 		c.mu.Unlock()
 		return missed
 	}
@@ -190,27 +298,39 @@ func (c *lru) Flights(now time.Time, multi []CacheableTTL, results []ValkeyResul
 			kc = &keyCache{cache: make(map[string]*list.Element, 1), key: key}
 			c.store[key] = kc
 		}
+		var stale ValkeyMessage
+		var size int
 		if ele := kc.cache[cmd]; ele != nil {
 			e := ele.Value.(*cacheEntry)
 			v := e.val
 			if v.typ == 0 {
 				entries[i] = e
+				if stales != nil {
+					stales[i] = staleWithin(e.stale, now, window)
+				}
 			} else if v.relativePTTL(now) > 0 {
 				results[i] = newResult(v, nil)
 			} else {
-				c.list.Remove(ele)
-				c.size -= e.size
+				c.remove(ele)
+				if stale = staleWithin(v, now, window); stale.typ == 0 {
+					c.size -= e.size
+				} else {
+					size = e.size
+				}
 				goto miss2
 			}
-			atomic.AddUint32(&kc.hits, 1)
-			c.list.MoveToBack(ele)
+			c.hit(kc, e)
+			c.moveToBack(ele)
 			continue
 		}
 	miss2:
-		atomic.AddUint32(&kc.miss, 1)
+		if stales != nil {
+			stales[i] = stale
+		}
+		c.miss(kc, cmd)
 		v := ValkeyMessage{}
 		v.setExpireAt(now.Add(multi[i].TTL).UnixMilli())
-		kc.cache[cmd] = c.list.PushBack(&cacheEntry{cmd: cmd, kc: kc, val: v, ch: make(chan struct{})})
+		kc.cache[cmd] = c.pushBack(&cacheEntry{cmd: cmd, kc: kc, val: v, stale: stale, size: size, ch: make(chan struct{}), owner: owner})
 		missed[j] = i
 		j++
 	}
@@ -219,11 +339,16 @@ func (c *lru) Flights(now time.Time, multi []CacheableTTL, results []ValkeyResul
 }
 
 func (c *lru) Update(key, cmd string, value ValkeyMessage) (pxat int64) {
+	return c.update(nil, key, cmd, value)
+}
+
+func (c *lru) update(owner *sharedLRU, key, cmd string, value ValkeyMessage) (pxat int64) {
 	var ch chan struct{}
+	var filled *list.Element
 	c.mu.Lock()
 	if kc, ok := c.store[key]; ok {
 		if ele := kc.cache[cmd]; ele != nil {
-			if e := ele.Value.(*cacheEntry); e.val.typ == 0 {
+			if e := ele.Value.(*cacheEntry); e.val.typ == 0 && e.owner == owner {
 				pxat = value.getExpireAt()
 				cpttl := e.val.getExpireAt()
 				if cpttl < pxat || pxat == 0 {
@@ -232,23 +357,22 @@ func (c *lru) Update(key, cmd string, value ValkeyMessage) (pxat int64) {
 					value.setExpireAt(pxat)
 				}
 				e.val = value
-				e.size = entryBaseSize + 2*(len(key)+len(cmd)) + value.approximateSize()
-				c.size += e.size
+				e.stale = ValkeyMessage{}
 				ch = e.ch
-			}
-
-			ele = c.list.Front()
-			for c.size > c.max && ele != nil {
-				if e := ele.Value.(*cacheEntry); e.val.typ != 0 { // do not delete pending entries
-					kc := e.kc
-					if delete(kc.cache, e.cmd); len(kc.cache) == 0 {
-						delete(c.store, kc.key)
+				if e.invalid {
+					if delete(kc.cache, cmd); len(kc.cache) == 0 {
+						delete(c.store, key)
 					}
-					c.list.Remove(ele)
+					c.remove(ele)
 					c.size -= e.size
+				} else {
+					size := entryBaseSize + 2*(len(key)+len(cmd)) + value.approximateSize()
+					c.size += size - e.size
+					e.size = size
+					filled = ele
 				}
-				ele = ele.Next()
 			}
+			c.evict(filled)
 		}
 	}
 	c.mu.Unlock()
@@ -258,18 +382,106 @@ func (c *lru) Update(key, cmd string, value ValkeyMessage) (pxat int64) {
 	return
 }
 
+// evict evicts entries if the cache is over the max after the filled entry is updated.
+// Without the policy, the least recently used entry is evicted. With the policy, a candidate, which is the filled entry
+// or the least recently used entry of the window once the window is over its share, competes with the least recently used entry
+// of the main list by the policy.Admit, and the loser is evicted.
+func (c *lru) evict(filled *list.Element) {
+	if c.policy == nil {
+		for ele := c.list.Front(); c.size > c.max && ele != nil; ele = ele.Next() {
+			if e := ele.Value.(*cacheEntry); e.val.typ != 0 { // do not delete pending entries
+				c.drop(ele)
+			}
+		}
+		return
+	}
+	for c.size > c.max {
+		var candidate *list.Element
+		if c.window == nil {
+			candidate = filled
+		} else if c.window.Len() > max(1, int(c.ratio*float64(c.window.Len()+c.list.Len()))) {
+			candidate = front(c.window, nil)
+		}
+		victim := front(c.list, candidate)
+		switch {
+		case candidate != nil && victim != nil:
+			if c.policy.Admit(candidate.Value.(*cacheEntry).hash, victim.Value.(*cacheEntry).hash) {
+				c.drop(victim)
+				c.admit(candidate)
+			} else {
+				c.drop(candidate)
+				filled = nil
+			}
+		case candidate != nil:
+			if c.window == nil {
+				c.drop(candidate)
+				filled = nil
+			} else {
+				c.admit(candidate)
+			}
+		case victim != nil:
+			c.drop(victim)
+		case c.window != nil:
+			if victim = front(c.window, nil); victim == nil {
+				return
+			}
+			c.drop(victim)
+		default:
+			return
+		}
+	}
+}
+
+// admit moves the entry from the window to the main list.
+func (c *lru) admit(ele *list.Element) {
+	if e := ele.Value.(*cacheEntry); !e.main && c.window != nil {
+		c.window.Remove(ele)
+		e.main = true
+		e.kc.cache[e.cmd] = c.list.PushBack(e)
+	}
+}
+
+// drop deletes the non-pending entry for eviction.
+func (c *lru) drop(ele *list.Element) {
+	e := ele.Value.(*cacheEntry)
+	if delete(e.kc.cache, e.cmd); len(e.kc.cache) == 0 {
+		delete(c.store, e.kc.key)
+	}
+	c.remove(ele)
+	c.size -= e.size
+	c.evictions.Add(1)
+}
+
+// front returns the least recently used non-pending entry of the list except the skip.
+func front(l *list.List, skip *list.Element) *list.Element {
+	for ele := l.Front(); ele != nil; ele = ele.Next() {
+		if ele != skip && ele.Value.(*cacheEntry).val.typ != 0 {
+			return ele
+		}
+	}
+	return nil
+}
+
 func (c *lru) Cancel(key, cmd string, err error) {
+	c.cancel(nil, key, cmd, err)
+}
+
+func (c *lru) cancel(owner *sharedLRU, key, cmd string, err error) {
 	var ch chan struct{}
 	c.mu.Lock()
 	if kc, ok := c.store[key]; ok {
 		if ele := kc.cache[cmd]; ele != nil {
-			if e := ele.Value.(*cacheEntry); e.val.typ == 0 {
+			if e := ele.Value.(*cacheEntry); e.val.typ == 0 && e.owner == owner {
 				e.err = err
 				ch = e.ch
-				if delete(kc.cache, cmd); len(kc.cache) == 0 {
-					delete(c.store, key)
+				if e.stale.typ != 0 { // put the stale value back for later stale reads
+					ele.Value = &cacheEntry{cmd: cmd, kc: kc, val: e.stale, size: e.size, owner: owner, hash: e.hash, main: e.main}
+				} else {
+					if delete(kc.cache, cmd); len(kc.cache) == 0 {
+						delete(c.store, key)
+					}
+					c.remove(ele)
 				}
-				c.list.Remove(ele)
 			}
 		}
 	}
@@ -291,15 +503,18 @@ func (c *lru) GetTTL(key, cmd string) (ttl time.Duration) {
 	return
 }
 
-func (c *lru) purge(key string, kc *keyCache) {
+// purge deletes the non-pending entries of the kc. Pending entries are marked invalid if the mark is true.
+func (c *lru) purge(key string, kc *keyCache, mark bool) {
 	if kc != nil {
 		for cmd, ele := range kc.cache {
 			if ele != nil {
 				e := ele.Value.(*cacheEntry)
 				if e.val.typ == 0 { // do not delete pending entries
+					c.dropStale(e)
+					e.invalid = e.invalid || mark
 					continue
 				}
-				c.list.Remove(ele)
+				c.remove(ele)
 				c.size -= e.size
 			}
 			if delete(kc.cache, cmd); len(kc.cache) == 0 {
@@ -309,20 +524,50 @@ func (c *lru) purge(key string, kc *keyCache) {
 	}
 }
 
+// dropStale drops the stale value kept by the pending entry, because it is invalidated.
+func (c *lru) dropStale(e *cacheEntry) {
+	if e.stale.typ != 0 {
+		e.stale = ValkeyMessage{}
+		c.size -= e.size
+		e.size = 0
+	}
+}
+
 func (c *lru) Delete(keys []ValkeyMessage) {
+	c.delete(keys, false)
+}
+
+// invalidate is the Delete that also invalidates pending entries. See cacheEntry.invalid.
+func (c *lru) invalidate(keys []ValkeyMessage) {
+	c.delete(keys, true)
+}
+
+func (c *lru) delete(keys []ValkeyMessage, mark bool) {
 	c.mu.Lock()
 	if keys == nil {
 		for key, kc := range c.store {
-			c.purge(key, kc)
+			c.purge(key, kc, mark)
 		}
 	} else {
 		for _, k := range keys {
-			c.purge(k.string(), c.store[k.string()])
+			c.purge(k.string(), c.store[k.string()], mark)
 		}
 	}
 	c.mu.Unlock()
 }
 
+func (c *lru) stats(s *PipelineStats) {
+	c.mu.RLock()
+	if c.list != nil {
+		s.CacheBytes, s.CacheEntries = c.size, c.list.Len()
+		if c.window != nil {
+			s.CacheEntries += c.window.Len()
+		}
+	}
+	c.mu.RUnlock()
+	s.CacheHits, s.CacheMisses, s.CacheEvictions = c.hits.Load(), c.misses.Load(), c.evictions.Load()
+}
+
 func (c *lru) Close(err error) {
 	c.mu.Lock()
 	for _, kc := range c.store {
@@ -337,5 +582,153 @@ func (c *lru) Close(err error) {
 	}
 	c.store = nil
 	c.list = nil
+	c.window = nil
+	c.mu.Unlock()
+}
+
+// flush deletes the entries owned by the owner. If closing, pending entries are also deleted with the err,
+// and the owner can't own new entries since then. Otherwise, pending entries are marked invalid if the mark is true.
+func (c *lru) flush(owner *sharedLRU, closing, mark bool, err error) {
+	var chs []chan struct{}
+	c.mu.Lock()
+	owner.closed = owner.closed || closing
+	for key, kc := range c.store {
+		for cmd, ele := range kc.cache {
+			e := ele.Value.(*cacheEntry)
+			if e.owner != owner {
+				continue
+			}
+			if e.val.typ == 0 && !closing {
+				c.dropStale(e)
+				e.invalid = e.invalid || mark
+				continue
+			}
+			if e.val.typ == 0 {
+				e.err = err
+				chs = append(chs, e.ch)
+			}
+			c.size -= e.size
+			c.remove(ele)
+			if delete(kc.cache, cmd); len(kc.cache) == 0 {
+				delete(c.store, key)
+			}
+		}
+	}
 	c.mu.Unlock()
+	for _, ch := range chs {
+		close(ch)
+	}
+}
+
+// NewSharedCacheStoreFn returns a NewCacheStoreFn that makes the connections to the same node and database
+// share one client-side cache of maxBytes instead of having their own caches sized by the ClientOption.CacheSizeEachConn.
+// Connections to different nodes or databases, including those of different clients, never share cached entries.
+// Since invalidations of a cached entry are only delivered to the connection that fetched it,
+// the entries fetched by a connection are flushed when the connection is closed. The default maxBytes is DefaultCacheBytes.
+// The CachePolicy of a shared cache is the one of its first connection, created by its ClientOption.NewCachePolicyFn.
+// A shared cache is dropped when the last connection sharing it is closed.
+func NewSharedCacheStoreFn(maxBytes int) NewCacheStoreFn {
+	if maxBytes <= 0 {
+		maxBytes = DefaultCacheBytes
+	}
+	var mu sync.Mutex
+	shared := make(map[string]*sharedRefs)
+	return func(opt CacheStoreOption) CacheStore {
+		mu.Lock()
+		r, ok := shared[opt.node]
+		if !ok {
+			r = &sharedRefs{lru: newLRU(CacheStoreOption{CacheSizeEachConn: maxBytes, CachePolicy: opt.CachePolicy}).(*lru)}
+			shared[opt.node] = r
+		}
+		r.refs++
+		mu.Unlock()
+		return &sharedLRU{lru: r.lru, release: func() {
+			mu.Lock()
+			if r.refs--; r.refs == 0 {
+				delete(shared, opt.node)
+			}
+			mu.Unlock()
+		}}
+	}
+}
+
+// sharedRefs is the lru shared by the connections to a node, and the number of them.
+type sharedRefs struct {
+	lru  *lru
+	refs int
+}
+
+var _ CacheStore = (*sharedLRU)(nil)
+
+// sharedLRU is the CacheStore of a connection sharing the lru with other connections.
+type sharedLRU struct {
+	*lru
+	release func() // releases the lru from the NewSharedCacheStoreFn when the connection is closed
+	once    sync.Once
+	closed  bool // guarded by the lru.mu
+}
+
+func (s *sharedLRU) Flight(key, cmd string, ttl time.Duration, now time.Time) (ValkeyMessage, CacheEntry) {
+	v, ce, _ := s.lru.flight(s, key, cmd, ttl, now, 0)
+	return v, ce
+}
+
+func (s *sharedLRU) Flights(now time.Time, multi []CacheableTTL, results []ValkeyResult, entries map[int]CacheEntry) []int {
+	return s.lru.flights(s, now, multi, results, entries, 0, nil)
+}
+
+func (s *sharedLRU) flightStale(key, cmd string, ttl time.Duration, now time.Time, window time.Duration) (ValkeyMessage, CacheEntry, ValkeyMessage) {
+	return s.lru.flight(s, key, cmd, ttl, now, window)
+}
+
+func (s *sharedLRU) flightsStale(now time.Time, multi []CacheableTTL, results []ValkeyResult, entries map[int]CacheEntry, window time.Duration, stales []ValkeyMessage) []int {
+	return s.lru.flights(s, now, multi, results, entries, window, stales)
+}
+
+func (s *sharedLRU) Update(key, cmd string, value ValkeyMessage) int64 {
+	return s.lru.update(s, key, cmd, value)
+}
+
+func (s *sharedLRU) Cancel(key, cmd string, err error) {
+	s.lru.cancel(s, key, cmd, err)
+}
+
+// Delete deletes the entries under the keys regardless of their owners, but it only deletes the entries of the connection if the keys are nil.
+func (s *sharedLRU) Delete(keys []ValkeyMessage) {
+	if keys == nil {
+		s.lru.flush(s, false, false, nil)
+	} else {
+		s.lru.Delete(keys)
+	}
+}
+
+func (s *sharedLRU) invalidate(keys []ValkeyMessage) {
+	if keys == nil {
+		s.lru.flush(s, false, true, nil)
+	} else {
+		s.lru.invalidate(keys)
+	}
+}
+
+// Close flushes the entries of the connection only, and the shared lru is kept for other connections.
+func (s *sharedLRU) Close(err error) {
+	s.lru.flush(s, true, false, err)
+	s.once.Do(s.release)
+}
+
+// lruStore is implemented by the lru and the sharedLRU for batched lookups, stale reads, stats, and redirected invalidations.
+type lruStore interface {
+	Flights(now time.Time, multi []CacheableTTL, results []ValkeyResult, entries map[int]CacheEntry) (missed []int)
+	flightStale(key, cmd string, ttl time.Duration, now time.Time, window time.Duration) (v ValkeyMessage, ce CacheEntry, stale ValkeyMessage)
+	flightsStale(now time.Time, multi []CacheableTTL, results []ValkeyResult, entries map[int]CacheEntry, window time.Duration, stales []ValkeyMessage) (missed []int)
+	stats(s *PipelineStats)
+	invalidate(keys []ValkeyMessage)
+}
+
+// staleWithin returns the v if it expired within the window, that is, before its hard expiration. Otherwise, it returns an empty message.
+func staleWithin(v ValkeyMessage, now time.Time, window time.Duration) ValkeyMessage {
+	if window > 0 && v.typ != 0 && v.relativePTTL(now) > -window.Milliseconds() {
+		return v
+	}
+	return ValkeyMessage{}
 }
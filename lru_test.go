@@ -459,6 +459,35 @@ func TestLRU(t *testing.T) {
 	})
 }
 
+func TestLRUInvalidatePending(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	for name, fn := range map[string]func() CacheStore{
+		"LRU":       func() CacheStore { return newLRU(CacheStoreOption{CacheSizeEachConn: DefaultCacheBytes}) },
+		"SharedLRU": func() CacheStore { return NewSharedCacheStoreFn(DefaultCacheBytes)(CacheStoreOption{}) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, keys := range [][]ValkeyMessage{{strmsg('+', "0")}, nil} {
+				store := fn()
+				store.Flight("0", "GET", TTL, time.Now())
+				_, e := store.Flight("0", "GET", TTL, time.Now())
+				store.(lruStore).invalidate(keys)
+				store.Update("0", "GET", strmsg('+', "0"))
+				if v, err := e.Wait(context.Background()); err != nil || v.string() != "0" {
+					t.Fatalf("the reply should be delivered to the waiters %v %v", v, err)
+				}
+				if v, e := store.Flight("0", "GET", TTL, time.Now()); v.typ != 0 || e != nil {
+					t.Fatalf("the reply invalidated before its arrival should not be cached %v %v", v, e)
+				}
+				var s PipelineStats
+				if store.(lruStore).stats(&s); s.CacheBytes != 0 {
+					t.Fatalf("unexpected size %v", s.CacheBytes)
+				}
+				store.Close(nil)
+			}
+		})
+	}
+}
+
 func flights(lru *lru, now time.Time, ttl time.Duration, args ...string) (ValkeyMessage, CacheEntry) {
 	results := make([]ValkeyResult, 1)
 	entries := make(map[int]CacheEntry, 1)
@@ -474,6 +503,127 @@ func roughly(ttl, expect time.Duration) bool {
 	return ttl >= (expect/4) && ttl <= expect
 }
 
+func TestSharedLRU(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	fn := NewSharedCacheStoreFn(entryMinSize * Entries * 2)
+	a, b := fn(CacheStoreOption{}).(*sharedLRU), fn(CacheStoreOption{}).(*sharedLRU)
+	update := func(store CacheStore, key string) {
+		t.Helper()
+		if v, entry := store.Flight(key, "GET", TTL, time.Now()); v.typ != 0 || entry != nil {
+			t.Fatalf("got unexpected value from the first Flight: %v %v", v, entry)
+		}
+		store.Update(key, "GET", strmsg('+', key))
+	}
+	hit := func(store CacheStore, key string) bool {
+		v, _ := store.Flight(key, "GET", TTL, time.Now())
+		return v.typ != 0
+	}
+
+	t.Run("Share Entries", func(t *testing.T) {
+		if v, entry := a.Flight("0", "GET", TTL, time.Now()); v.typ != 0 || entry != nil {
+			t.Fatalf("got unexpected value from the first Flight: %v %v", v, entry)
+		}
+		_, entry := b.Flight("0", "GET", TTL, time.Now())
+		if entry == nil {
+			t.Fatalf("should wait for the pending entry of another connection")
+		}
+		b.Update("0", "GET", strmsg('+', "b")) // only the owner can update the pending entry.
+		a.Update("0", "GET", strmsg('+', "0"))
+		if v, err := entry.Wait(context.Background()); err != nil || v.string() != "0" {
+			t.Fatalf("got unexpected value from the Wait: %v %v", v, err)
+		}
+		if !hit(b, "0") {
+			t.Fatalf("the entry should be shared")
+		}
+		if a.lru != b.lru || a.list.Len() != 1 {
+			t.Fatalf("the lru should be shared")
+		}
+	})
+
+	t.Run("Scoped By Node", func(t *testing.T) {
+		c := fn(CacheStoreOption{node: "127.0.0.1:6379/0"}).(*sharedLRU)
+		d := fn(CacheStoreOption{node: "127.0.0.1:6379/1"}).(*sharedLRU)
+		e := fn(CacheStoreOption{node: "127.0.0.1:6379/0"}).(*sharedLRU)
+		if c.lru == a.lru || c.lru == d.lru || c.lru != e.lru {
+			t.Fatalf("the lru should only be shared by the connections to the same node and database")
+		}
+		if update(c, "0"); hit(d, "0") || !hit(e, "0") {
+			t.Fatalf("the entry should not be shared across nodes")
+		}
+		c.Close(nil)
+		d.Close(nil)
+		e.Close(nil)
+	})
+
+	t.Run("Dropped By The Last Close", func(t *testing.T) {
+		node := "127.0.0.1:6380/0"
+		c, d := fn(CacheStoreOption{node: node}).(*sharedLRU), fn(CacheStoreOption{node: node}).(*sharedLRU)
+		c.Close(nil)
+		c.Close(nil) // closing twice releases the lru only once
+		e := fn(CacheStoreOption{node: node}).(*sharedLRU)
+		if e.lru != d.lru {
+			t.Fatalf("the lru should be kept while any connection is open")
+		}
+		d.Close(nil)
+		e.Close(nil)
+		if f := fn(CacheStoreOption{node: node}).(*sharedLRU); f.lru == e.lru {
+			t.Fatalf("the lru should be dropped after the last connection is closed")
+		} else {
+			f.Close(nil)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		update(b, "1")
+		b.Delete([]ValkeyMessage{strmsg('+', "0")}) // invalidations from any connection delete the entries.
+		if hit(a, "0") {
+			t.Fatalf("the entry should be deleted")
+		}
+		a.Update("0", "GET", strmsg('+', "0"))
+		b.Delete(nil) // flush only the entries of the connection.
+		if !hit(a, "0") || hit(a, "1") {
+			t.Fatalf("only the entries of b should be flushed")
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		update(b, "4")
+		a.Flight("2", "GET", TTL, time.Now())
+		_, entry := b.Flight("2", "GET", TTL, time.Now())
+		err := errors.New("closed")
+		a.Close(err)
+		if _, err2 := entry.Wait(context.Background()); err2 != err {
+			t.Fatalf("got unexpected err from the Wait: %v", err2)
+		}
+		if hit(b, "0") || !hit(b, "4") {
+			t.Fatalf("only the entries of a should be flushed")
+		}
+		for range 2 {
+			if v, entry := a.Flight("3", "GET", TTL, time.Now()); v.typ != 0 || entry != nil {
+				t.Fatalf("a closed connection should not own new entries: %v %v", v, entry)
+			}
+		}
+		if !hit(a, "4") {
+			t.Fatalf("a closed connection can still read the shared entries")
+		}
+	})
+
+	t.Run("Single Budget", func(t *testing.T) {
+		c := fn(CacheStoreOption{CacheSizeEachConn: DefaultCacheBytes})
+		for i := 0; i < Entries*4; i++ {
+			if i%2 == 0 {
+				update(b, strconv.Itoa(i+10))
+			} else {
+				update(c, strconv.Itoa(i+10))
+			}
+		}
+		var s PipelineStats
+		if b.stats(&s); s.CacheBytes > entryMinSize*Entries*2 || s.CacheEntries > Entries*2 {
+			t.Fatalf("the shared lru should be bounded by one budget: %v %v", s.CacheBytes, s.CacheEntries)
+		}
+	})
+}
+
 func BenchmarkLRU(b *testing.B) {
 	lru := newLRU(CacheStoreOption{CacheSizeEachConn: entryMinSize * Entries})
 	b.Run("Flight", func(b *testing.B) {
@@ -494,6 +644,184 @@ func BenchmarkLRU(b *testing.B) {
 	})
 }
 
+func TestLRUStale(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	setup := func(t *testing.T) (*lru, time.Time) {
+		store := newLRU(CacheStoreOption{CacheSizeEachConn: DefaultCacheBytes}).(*lru)
+		now := time.Now()
+		store.Flight("0", "GET", TTL, now)
+		m := strmsg('+', "0")
+		m.setExpireAt(now.Add(PTTL * time.Millisecond).UnixMilli())
+		store.Update("0", "GET", m)
+		return store, now.Add(2 * PTTL * time.Millisecond) // expired
+	}
+
+	t.Run("Keep Stale While Pending", func(t *testing.T) {
+		store, expired := setup(t)
+		size := store.size
+		if v, e, stale := store.flightStale("0", "GET", TTL, expired, TTL); v.typ != 0 || e != nil || stale.string() != "0" {
+			t.Fatalf("unexpected stale %v %v %v", v, e, stale)
+		}
+		if store.size != size {
+			t.Fatalf("the size should be taken over by the pending entry %v %v", store.size, size)
+		}
+		if v, e, stale := store.flightStale("0", "GET", TTL, expired, TTL); v.typ != 0 || e == nil || stale.string() != "0" {
+			t.Fatalf("unexpected stale %v %v %v", v, e, stale)
+		}
+		if _, e, stale := store.flightStale("0", "GET", TTL, expired, PTTL*time.Millisecond/2); e == nil || stale.typ != 0 {
+			t.Fatalf("the stale should not be returned out of the window %v", stale)
+		}
+		if v, e := store.Flight("0", "GET", TTL, expired); v.typ != 0 || e == nil {
+			t.Fatalf("unexpected value %v %v", v, e)
+		}
+		store.Update("0", "GET", strmsg('+', "1"))
+		if v, _, stale := store.flightStale("0", "GET", TTL, time.Now(), TTL); v.string() != "1" || stale.typ != 0 {
+			t.Fatalf("unexpected value %v %v", v, stale)
+		}
+	})
+
+	t.Run("Put Stale Back On Cancel", func(t *testing.T) {
+		store, expired := setup(t)
+		store.flightStale("0", "GET", TTL, expired, TTL)
+		_, e, _ := store.flightStale("0", "GET", TTL, expired, TTL)
+		store.Cancel("0", "GET", errors.New("err"))
+		if _, err := e.Wait(context.Background()); err == nil || err.Error() != "err" {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if v, e, stale := store.flightStale("0", "GET", TTL, expired, TTL); v.typ != 0 || e != nil || stale.string() != "0" {
+			t.Fatalf("unexpected stale %v %v %v", v, e, stale)
+		}
+	})
+
+	t.Run("Out Of Window", func(t *testing.T) {
+		store, expired := setup(t)
+		if v, e, stale := store.flightStale("0", "GET", TTL, expired, PTTL*time.Millisecond/2); v.typ != 0 || e != nil || stale.typ != 0 {
+			t.Fatalf("unexpected stale %v %v %v", v, e, stale)
+		}
+		store.Cancel("0", "GET", errors.New("err"))
+		if store.size != 0 {
+			t.Fatalf("unexpected size %v", store.size)
+		}
+	})
+
+	t.Run("Drop Stale On Invalidation", func(t *testing.T) {
+		store, expired := setup(t)
+		store.flightStale("0", "GET", TTL, expired, TTL)
+		store.Delete([]ValkeyMessage{strmsg('+', "0")})
+		if _, e, stale := store.flightStale("0", "GET", TTL, expired, TTL); e == nil || stale.typ != 0 {
+			t.Fatalf("the stale should be dropped %v", stale)
+		}
+		if store.size != 0 {
+			t.Fatalf("unexpected size %v", store.size)
+		}
+		store.Cancel("0", "GET", errors.New("err"))
+		if v, e, _ := store.flightStale("0", "GET", TTL, expired, TTL); v.typ != 0 || e != nil {
+			t.Fatalf("the entry should be deleted %v %v", v, e)
+		}
+	})
+
+	t.Run("Flights", func(t *testing.T) {
+		store, expired := setup(t)
+		multi := []CacheableTTL{CT(Cacheable(cmds.NewCompleted([]string{"GET", "0"})), TTL)}
+		stales := make([]ValkeyMessage, 1)
+		results := make([]ValkeyResult, 1)
+		entries := make(map[int]CacheEntry)
+		if missed := store.flightsStale(expired, multi, results, entries, TTL, stales); len(missed) != 1 || stales[0].string() != "0" {
+			t.Fatalf("unexpected missed %v %v", missed, stales)
+		}
+		stales[0] = ValkeyMessage{}
+		if missed := store.flightsStale(expired, multi, results, entries, TTL, stales); len(missed) != 0 || entries[0] == nil || stales[0].string() != "0" {
+			t.Fatalf("unexpected missed %v %v", missed, stales)
+		}
+	})
+}
+
+func TestLRUPolicy(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	const capacity = 10
+	fill := func(store CacheStore, key string) {
+		if v, _ := store.Flight(key, "GET", TTL, time.Now()); v.typ == 0 {
+			store.Update(key, "GET", strmsg('+', key))
+		}
+	}
+	cached := func(store CacheStore, key string) bool {
+		v, _ := store.Flight(key, "GET", TTL, time.Now())
+		if v.typ == 0 {
+			store.Cancel(key, "GET", errors.New("err"))
+		}
+		return v.typ != 0
+	}
+	scan := func(policy CachePolicy) *lru {
+		store := newLRU(CacheStoreOption{CacheSizeEachConn: entryMinSize * capacity, CachePolicy: policy}).(*lru)
+		for i := 0; i < 20; i++ {
+			fill(store, "hot")
+		}
+		for i := 0; i < capacity*10; i++ {
+			fill(store, "cold"+strconv.Itoa(i))
+		}
+		return store
+	}
+
+	t.Run("LRU Evicts Hot Entries On Scan", func(t *testing.T) {
+		if store := scan(nil); cached(store, "hot") {
+			t.Fatalf("the hot entry should be evicted by the scan without the policy")
+		}
+	})
+
+	t.Run("TinyLFU Keeps Hot Entries On Scan", func(t *testing.T) {
+		store := scan(NewTinyLFUPolicyFn(TinyLFUOption{})())
+		if !cached(store, "hot") {
+			t.Fatalf("the hot entry should be kept by the policy")
+		}
+		if store.size > entryMinSize*capacity*3/2 {
+			t.Fatalf("the cache should be bounded %v", store.size)
+		}
+	})
+
+	t.Run("W-TinyLFU Keeps Hot Entries On Scan", func(t *testing.T) {
+		store := scan(NewTinyLFUPolicyFn(TinyLFUOption{Window: 0.2})())
+		if !cached(store, "hot") {
+			t.Fatalf("the hot entry should be kept by the policy")
+		}
+		if !cached(store, "cold"+strconv.Itoa(capacity*10-1)) {
+			t.Fatalf("the most recent entry should be kept by the window")
+		}
+		if store.window.Len() == 0 || store.list.Len() == 0 {
+			t.Fatalf("unexpected window %v and main %v", store.window.Len(), store.list.Len())
+		}
+	})
+
+	t.Run("Counters", func(t *testing.T) {
+		store := newLRU(CacheStoreOption{CacheSizeEachConn: entryMinSize * capacity, CachePolicy: NewTinyLFUPolicyFn(TinyLFUOption{Window: 0.2})()}).(*lru)
+		for i := 0; i < capacity*2; i++ {
+			fill(store, strconv.Itoa(i))
+		}
+		fill(store, "0")
+		var s PipelineStats
+		store.stats(&s)
+		if s.CacheMisses < capacity*2 || s.CacheHits+s.CacheMisses != capacity*2+1 || s.CacheEvictions == 0 {
+			t.Fatalf("unexpected counters %v %v %v", s.CacheHits, s.CacheMisses, s.CacheEvictions)
+		}
+		if s.CacheEntries != store.list.Len()+store.window.Len() || s.CacheEntries > capacity {
+			t.Fatalf("unexpected entries %v", s.CacheEntries)
+		}
+		store.Close(nil)
+		store.stats(&s)
+		if s.CacheEvictions == 0 {
+			t.Fatalf("the counters should be kept after closed")
+		}
+	})
+
+	t.Run("Shared", func(t *testing.T) {
+		fn := NewSharedCacheStoreFn(entryMinSize * capacity)
+		a := fn(CacheStoreOption{CachePolicy: NewTinyLFUPolicyFn(TinyLFUOption{})()})
+		b := fn(CacheStoreOption{})
+		if a.(*sharedLRU).policy == nil || b.(*sharedLRU).policy == nil {
+			t.Fatalf("the shared lru should use the policy of the first connection")
+		}
+	})
+}
+
 func TestEntry(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	t.Run("Wait", func(t *testing.T) {
@@ -431,6 +431,20 @@ func (c *client) DoMulti(ctx context.Context, cmd ...Completed) (resp []ValkeyRe
 	return nil
 }
 
+func (c *client) DoAsync(ctx context.Context, cmd Completed) *ValkeyFuture {
+	f := newFuture()
+	f.resolve(c.Do(ctx, cmd))
+	return f
+}
+
+func (c *client) DoMultiAsync(ctx context.Context, cmd ...Completed) *ValkeyMultiFuture {
+	f := newMultiFuture(len(cmd))
+	f.add(1)
+	copy(f.resps, c.DoMulti(ctx, cmd...))
+	f.resolve()
+	return f
+}
+
 func (c *client) DoStream(_ context.Context, _ Completed) (resp ValkeyResultStream) {
 	return ValkeyResultStream{}
 }
@@ -471,6 +485,10 @@ func (c *client) Nodes() map[string]Client {
 	return map[string]Client{"addr": c}
 }
 
+func (c *client) Stats() map[string]NodeStats {
+	return map[string]NodeStats{"addr": {}}
+}
+
 func (c *client) Mode() ClientMode {
 	return c.ModeFn()
 }
@@ -481,6 +499,11 @@ func (c *client) Close() {
 	}
 }
 
+func (c *client) CloseWithContext(ctx context.Context) error {
+	c.Close()
+	return nil
+}
+
 func TestNewLuaScriptWithLoadSha1(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	body := strconv.Itoa(rand.Int())
@@ -0,0 +1,104 @@
+package valkey
+
+import (
+	"sync"
+)
+
+// SlotMigration is the progress of a slot migrating from one primary to another observed by a cluster client.
+// It is delivered to the ClusterOption.OnSlotMigration.
+type SlotMigration struct {
+	// Source is the address of the primary the slot migrates from.
+	Source string
+	// Target is the address of the primary the slot migrates to.
+	Target string
+	Slot   uint16
+	// Done reports whether the slot is served by the Target. Otherwise, the slot is still being migrated,
+	// and the keys already moved to the Target are served by ASK redirects.
+	Done bool
+}
+
+// migrations tracks the migrating slots observed from ASK and MOVED redirects and reconciles them with the refreshed topology.
+// The topology itself does not tell the migrating or importing slots, so a migration without redirects is not tracked.
+type migrations struct {
+	fn    func(SlotMigration)
+	slots map[uint16]SlotMigration
+	mu    sync.Mutex
+}
+
+func newMigrations(fn func(SlotMigration)) *migrations {
+	return &migrations{fn: fn, slots: make(map[uint16]SlotMigration)}
+}
+
+// ask records the slot migrating from the source to the target by an ASK redirect.
+func (m *migrations) ask(slot uint16, source, target string) {
+	if slot >= 16384 {
+		return
+	}
+	m.mu.Lock()
+	p, ok := m.slots[slot]
+	if ok && p.Target == target {
+		m.mu.Unlock()
+		return
+	}
+	if ok { // the slot was being migrated to another target, which is aborted.
+		source = p.Source
+	}
+	s := SlotMigration{Source: source, Target: target, Slot: slot}
+	m.slots[slot] = s
+	m.mu.Unlock()
+	m.notify(s)
+}
+
+// moved finishes the migration of the slot by a MOVED redirect.
+// The migration is reported even if it is not tracked, because an atomic slot migration redirects with MOVED only.
+func (m *migrations) moved(slot uint16, source, target string) {
+	if slot >= 16384 {
+		return
+	}
+	m.mu.Lock()
+	if p, ok := m.slots[slot]; ok {
+		source = p.Source
+		delete(m.slots, slot)
+	}
+	m.mu.Unlock()
+	m.notify(SlotMigration{Source: source, Target: target, Slot: slot, Done: true})
+}
+
+// reconcile finishes the tracked migrations whose slots are not served by their sources in the groups anymore.
+func (m *migrations) reconcile(groups map[string]group) {
+	m.mu.Lock()
+	if len(m.slots) == 0 {
+		m.mu.Unlock()
+		return
+	}
+	var done []SlotMigration
+	for slot, s := range m.slots {
+		if owner := slotOwnerOf(groups, slot); owner != s.Source {
+			s.Target = owner
+			s.Done = true
+			done = append(done, s)
+			delete(m.slots, slot)
+		}
+	}
+	m.mu.Unlock()
+	for _, s := range done {
+		m.notify(s)
+	}
+}
+
+func (m *migrations) notify(s SlotMigration) {
+	if m.fn != nil {
+		m.fn(s)
+	}
+}
+
+func slotOwnerOf(groups map[string]group, slot uint16) string {
+	for addr, g := range groups {
+		for _, r := range g.slots {
+			if r[0] <= int64(slot) && int64(slot) <= r[1] {
+				return addr
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package valkey
+
+import (
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	var events []SlotMigration
+	m := newMigrations(func(s SlotMigration) { events = append(events, s) })
+
+	m.ask(1, "a", "b")
+	m.ask(1, "a", "b")
+	m.ask(2, "a", "b")
+	m.ask(16384, "a", "b")
+	if len(events) != 2 || events[0] != (SlotMigration{Source: "a", Target: "b", Slot: 1}) {
+		t.Fatalf("unexpected events %v", events)
+	}
+
+	m.ask(2, "b", "c") // the migration to b is aborted, and the slot is migrated to c instead.
+	if e := events[2]; e != (SlotMigration{Source: "a", Target: "c", Slot: 2}) {
+		t.Fatalf("unexpected event %v", e)
+	}
+
+	m.reconcile(map[string]group{"a": {slots: [][2]int64{{0, 100}}}})
+	if len(events) != 3 {
+		t.Fatalf("slots still served by the source should not be done %v", events)
+	}
+	m.reconcile(map[string]group{"a": {slots: [][2]int64{{0, 0}, {2, 100}}}, "b": {slots: [][2]int64{{1, 1}}}})
+	if len(events) != 4 || events[3] != (SlotMigration{Source: "a", Target: "b", Slot: 1, Done: true}) {
+		t.Fatalf("unexpected events %v", events)
+	}
+
+	m.moved(2, "b", "c")
+	if len(events) != 5 || events[4] != (SlotMigration{Source: "a", Target: "c", Slot: 2, Done: true}) {
+		t.Fatalf("unexpected events %v", events)
+	}
+	m.moved(3, "a", "b") // an atomic slot migration is only reported done.
+	if len(events) != 6 || events[5] != (SlotMigration{Source: "a", Target: "b", Slot: 3, Done: true}) {
+		t.Fatalf("unexpected events %v", events)
+	}
+	if len(m.slots) != 0 {
+		t.Fatalf("unexpected slots %v", m.slots)
+	}
+
+	m = newMigrations(nil)
+	m.ask(1, "a", "b")
+	m.reconcile(nil)
+	if len(m.slots) != 0 {
+		t.Fatalf("unexpected slots %v", m.slots)
+	}
+}
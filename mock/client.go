@@ -86,6 +86,20 @@ func (mr *ClientMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*Client)(nil).Close))
 }
 
+// CloseWithContext mocks base method.
+func (m *Client) CloseWithContext(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseWithContext", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseWithContext indicates an expected call of CloseWithContext.
+func (mr *ClientMockRecorder) CloseWithContext(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseWithContext", reflect.TypeOf((*Client)(nil).CloseWithContext), arg0)
+}
+
 // Dedicate mocks base method.
 func (m *Client) Dedicate() (valkey.DedicatedClient, func()) {
 	m.ctrl.T.Helper()
@@ -176,6 +190,39 @@ func (mr *ClientMockRecorder) DoMulti(arg0 any, arg1 ...any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoMulti", reflect.TypeOf((*Client)(nil).DoMulti), varargs...)
 }
 
+// DoAsync mocks base method.
+func (m *Client) DoAsync(arg0 context.Context, arg1 valkey.Completed) *valkey.ValkeyFuture {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoAsync", arg0, arg1)
+	ret0, _ := ret[0].(*valkey.ValkeyFuture)
+	return ret0
+}
+
+// DoAsync indicates an expected call of DoAsync.
+func (mr *ClientMockRecorder) DoAsync(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoAsync", reflect.TypeOf((*Client)(nil).DoAsync), arg0, arg1)
+}
+
+// DoMultiAsync mocks base method.
+func (m *Client) DoMultiAsync(arg0 context.Context, arg1 ...valkey.Completed) *valkey.ValkeyMultiFuture {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DoMultiAsync", varargs...)
+	ret0, _ := ret[0].(*valkey.ValkeyMultiFuture)
+	return ret0
+}
+
+// DoMultiAsync indicates an expected call of DoMultiAsync.
+func (mr *ClientMockRecorder) DoMultiAsync(arg0 any, arg1 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoMultiAsync", reflect.TypeOf((*Client)(nil).DoMultiAsync), varargs...)
+}
+
 // DoMultiStream mocks base method.
 func (m *Client) DoMultiStream(arg0 context.Context, arg1 ...valkey.Completed) valkey.MultiValkeyResultStream {
 	m.ctrl.T.Helper()
@@ -242,6 +289,20 @@ func (mr *ClientMockRecorder) Receive(arg0, arg1, arg2 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Receive", reflect.TypeOf((*Client)(nil).Receive), arg0, arg1, arg2)
 }
 
+// Stats mocks base method.
+func (m *Client) Stats() map[string]valkey.NodeStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(map[string]valkey.NodeStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *ClientMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*Client)(nil).Stats))
+}
+
 // DedicatedClient mocks the DedicatedClient interface.
 type DedicatedClient struct {
 	ctrl     *gomock.Controller
@@ -286,6 +347,20 @@ func (mr *DedicatedClientMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*DedicatedClient)(nil).Close))
 }
 
+// CloseWithContext mocks base method.
+func (m *DedicatedClient) CloseWithContext(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseWithContext", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseWithContext indicates an expected call of CloseWithContext.
+func (mr *DedicatedClientMockRecorder) CloseWithContext(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseWithContext", reflect.TypeOf((*DedicatedClient)(nil).CloseWithContext), arg0)
+}
+
 // Do mocks the base method.
 func (m *DedicatedClient) Do(arg0 context.Context, arg1 valkey.Completed) valkey.ValkeyResult {
 	m.ctrl.T.Helper()
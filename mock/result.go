@@ -3,6 +3,7 @@ package mock
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"net"
 	"strconv"
@@ -125,6 +126,20 @@ func MultiValkeyResultStreamError(err error) valkey.ValkeyResultStream {
 	return ValkeyResultStreamError(err)
 }
 
+// Future returns a valkey.ValkeyFuture that is already done with the result.
+func Future(result valkey.ValkeyResult) *valkey.ValkeyFuture {
+	f := &future{done: make(chan struct{}), resp: result}
+	close(f.done)
+	return (*valkey.ValkeyFuture)(unsafe.Pointer(f))
+}
+
+// MultiFuture returns a valkey.ValkeyMultiFuture that is already done with the results.
+func MultiFuture(results ...valkey.ValkeyResult) *valkey.ValkeyMultiFuture {
+	f := &multiFuture{done: make(chan struct{}), resps: results}
+	close(f.done)
+	return (*valkey.ValkeyMultiFuture)(unsafe.Pointer(f))
+}
+
 type message struct {
 	attrs   *valkey.ValkeyMessage
 	bytes   *byte
@@ -169,18 +184,42 @@ type result struct {
 	val valkey.ValkeyMessage
 }
 
+type future struct {
+	done chan struct{}
+	fns  []func(valkey.ValkeyResult)
+	resp valkey.ValkeyResult
+	mu   sync.Mutex
+}
+
+type multiFuture struct {
+	done  chan struct{}
+	fns   []func([]valkey.ValkeyResult)
+	resps []valkey.ValkeyResult
+	mu    sync.Mutex
+	parts atomic.Int32
+}
+
+// pool and pipe mirror the layouts of the valkey.pool and valkey.pipe, and must be updated along with them.
 type pool struct {
-	dead    any
-	cond    *sync.Cond
-	timer   *time.Timer
-	make    func() any
-	list    []any
-	cleanup time.Duration
-	size    int
-	minSize int
-	cap     int
-	down    bool
-	timerOn bool
+	dead     any
+	cond     *sync.Cond
+	timer    *time.Timer
+	htimer   *time.Timer
+	make     func(ctx context.Context) any
+	list     []any
+	inuse    map[any]struct{}
+	cleanup  time.Duration
+	check    time.Duration
+	size     int
+	minSize  int
+	minIdle  int
+	cap      int
+	waiters  int
+	down     bool
+	timerOn  bool
+	filling  bool
+	checking bool
+	forced   bool
 }
 
 type pipe struct {
@@ -194,10 +233,12 @@ type pipe struct {
 	w               *bufio.Writer
 	close           chan struct{}
 	onInvalidations func([]valkey.ValkeyMessage)
+	bcast           *valkey.BroadcastTracking
 	ssubs           *any // pubsub smessage subscriptions
 	nsubs           *any // pubsub  message subscriptions
 	psubs           *any // pubsub pmessage subscriptions
 	r2p             *any
+	r2inv           *any
 	pingTimer       *time.Timer // timer for background ping
 	lftmTimer       *time.Timer // lifetime timer
 	info            map[string]valkey.ValkeyMessage
@@ -206,6 +247,9 @@ type pipe struct {
 	maxFlushDelay   time.Duration
 	lftm            time.Duration // lifetime
 	wrCounter       atomic.Uint64
+	wlat            atomic.Int64
+	rtt             atomic.Int64
+	r2id            int64
 	version         int32
 	blcksig         int32
 	state           int32
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"reflect"
@@ -105,6 +106,31 @@ func TestErrorResult(t *testing.T) {
 	}
 }
 
+func TestFuture(t *testing.T) {
+	f := Future(ErrorResult(errors.New("any")))
+	<-f.Done()
+	if err := f.Wait(context.Background()).Error(); err.Error() != "any" {
+		t.Fatalf("unexpected value %v", err)
+	}
+	f.OnDone(func(r valkey.ValkeyResult) {
+		if err := r.Error(); err.Error() != "any" {
+			t.Fatalf("unexpected value %v", err)
+		}
+	})
+}
+
+func TestMultiFuture(t *testing.T) {
+	f := MultiFuture(Result(ValkeyString("a")), ErrorResult(errors.New("any")))
+	<-f.Done()
+	rs := f.Wait(context.Background())
+	if v, err := rs[0].ToString(); err != nil || v != "a" {
+		t.Fatalf("unexpected value %v", v)
+	}
+	if err := rs[1].Error(); err.Error() != "any" {
+		t.Fatalf("unexpected value %v", err)
+	}
+}
+
 func TestErrorResultStream(t *testing.T) {
 	s := ValkeyResultStreamError(errors.New("any"))
 	if err := s.Error(); err.Error() != "any" {
@@ -168,6 +194,26 @@ func TestResultStream(t *testing.T) {
 	}
 }
 
+func TestResultStreamElements(t *testing.T) {
+	s := ValkeyResultStream(ValkeyArray(ValkeyString("a"), ValkeyString("b")), ValkeyMap(map[string]valkey.ValkeyMessage{"c": ValkeyString("d")}))
+	var out []string
+	for s.HasNext() {
+		for m, err := range s.Elements() {
+			if err != nil {
+				t.Fatalf("unexpected value %v", err)
+			}
+			v, _ := m.ToString()
+			out = append(out, v)
+		}
+	}
+	if strings.Join(out, "") != "abcd" {
+		t.Fatalf("unexpected value %v", out)
+	}
+	if err := s.Error(); err != io.EOF {
+		t.Fatalf("unexpected value %v", err)
+	}
+}
+
 func TestMultiResultStream(t *testing.T) {
 	type test struct {
 		msg []valkey.ValkeyMessage
@@ -0,0 +1,200 @@
+package valkey
+
+import (
+	"context"
+	"sync/atomic"
+
+	"golang.org/x/sys/cpu"
+)
+
+const (
+	mpscEmpty uint32 = iota
+	mpscQueued
+	mpscWritten
+)
+
+type mpscSlot struct {
+	ch    chan ValkeyResult
+	one   Completed
+	multi []Completed
+	resps []ValkeyResult
+	fn    func(ValkeyResult)
+	mark  atomic.Uint32
+}
+
+// mpsc is a bounded queue that allows multiple producers to enqueue commands with only atomic operations.
+// The single writer drains all published commands without locking, and it only parks on a channel when the queue is empty.
+// Producers block on a channel only when the queue is full, which allows them to be canceled by their contexts.
+type mpsc struct {
+	wake  chan struct{}     // wakes up the parked writer
+	space chan struct{}     // wakes up one of the producers waiting for free slots
+	ach   chan ValkeyResult // receives results of async commands
+	cur   *mpscSlot
+	store []mpscSlot // store's size must be 2^N to work with the mask
+	_     cpu.CacheLinePad
+	tail  atomic.Uint64
+	_     cpu.CacheLinePad
+	free  atomic.Int64
+	_     cpu.CacheLinePad
+	waits atomic.Int32 // number of producers waiting for free slots
+	slept atomic.Bool  // the writer is parked
+	read1 uint64
+	read2 uint64
+	mask  uint64
+}
+
+var _ queue = (*mpsc)(nil)
+
+func newMPSC(factor int) *mpsc {
+	if factor <= 0 {
+		factor = DefaultRingScale
+	}
+	q := &mpsc{
+		wake:  make(chan struct{}, 1),
+		space: make(chan struct{}, 1),
+		ach:   make(chan ValkeyResult, 1),
+		store: make([]mpscSlot, 2<<(factor-1)),
+	}
+	q.mask = uint64(len(q.store) - 1)
+	q.free.Store(int64(len(q.store)))
+	for i := range q.store {
+		q.store[i].ch = make(chan ValkeyResult) // this channel can't be buffered
+	}
+	return q
+}
+
+func (q *mpsc) acquire(ctx context.Context) (*mpscSlot, error) {
+	for {
+		if n := q.free.Load(); n > 0 {
+			if q.free.CompareAndSwap(n, n-1) {
+				break
+			}
+			continue
+		}
+		q.waits.Add(1)
+		if q.free.Load() > 0 { // recheck after announcing the wait to avoid missing the wakeup
+			q.waits.Add(-1)
+			continue
+		}
+		select {
+		case <-q.space:
+			q.waits.Add(-1)
+		case <-ctx.Done():
+			q.waits.Add(-1)
+			return nil, ctx.Err()
+		}
+	}
+	if q.free.Load() > 0 && q.waits.Load() > 0 { // pass the wakeup to other waiting producers
+		q.signal(q.space)
+	}
+	return &q.store[(q.tail.Add(1)-1)&q.mask], nil
+}
+
+func (q *mpsc) publish(n *mpscSlot) {
+	n.mark.Store(mpscQueued)
+	if q.slept.Load() {
+		q.signal(q.wake)
+	}
+}
+
+func (q *mpsc) signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (q *mpsc) PutOne(ctx context.Context, m Completed) (chan ValkeyResult, error) {
+	n, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	n.one = m
+	q.publish(n)
+	return n.ch, nil
+}
+
+func (q *mpsc) PutMulti(ctx context.Context, m []Completed, resps []ValkeyResult) (chan ValkeyResult, error) {
+	n, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	n.multi = m
+	n.resps = resps
+	q.publish(n)
+	return n.ch, nil
+}
+
+func (q *mpsc) PutAsync(ctx context.Context, one Completed, multi []Completed, resps []ValkeyResult, fn func(ValkeyResult)) error {
+	n, err := q.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	n.one = one
+	n.multi = multi
+	n.resps = resps
+	n.fn = fn
+	q.publish(n)
+	return nil
+}
+
+// NextWriteCmd should be only called by one dedicated thread
+func (q *mpsc) NextWriteCmd() (one Completed, multi []Completed, ch chan ValkeyResult) {
+	n := &q.store[q.read1&q.mask]
+	if n.mark.Load() == mpscQueued {
+		one, multi, ch = n.one, n.multi, n.ch
+		n.mark.Store(mpscWritten)
+		q.read1++
+	}
+	return
+}
+
+// WaitForWrite should be only called by one dedicated thread
+func (q *mpsc) WaitForWrite() (one Completed, multi []Completed, ch chan ValkeyResult) {
+	for {
+		if one, multi, ch = q.NextWriteCmd(); ch != nil {
+			return
+		}
+		q.slept.Store(true)
+		if one, multi, ch = q.NextWriteCmd(); ch != nil { // recheck after announcing the sleep to avoid missing the wakeup
+			q.slept.Store(false)
+			return
+		}
+		<-q.wake
+		q.slept.Store(false)
+	}
+}
+
+// NextResultCh should be only called by one dedicated thread
+func (q *mpsc) NextResultCh() (one Completed, multi []Completed, ch chan ValkeyResult, resps []ValkeyResult) {
+	n := &q.store[q.read2&q.mask]
+	if n.mark.Load() == mpscWritten {
+		one, multi, ch, resps = n.one, n.multi, n.ch, n.resps
+		if n.fn != nil {
+			ch = q.ach
+		}
+		q.cur = n
+		q.read2++
+	}
+	return
+}
+
+// FinishResult should be only called by one dedicated thread
+func (q *mpsc) FinishResult() {
+	if n := q.cur; n != nil {
+		fn := n.fn
+		q.cur = nil
+		n.one = Completed{}
+		n.multi = nil
+		n.resps = nil
+		n.fn = nil
+		n.mark.Store(mpscEmpty)
+		q.free.Add(1)
+		if q.waits.Load() > 0 {
+			q.signal(q.space)
+		}
+		if fn != nil {
+			fn(<-q.ach)
+		}
+	}
+}
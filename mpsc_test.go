@@ -0,0 +1,243 @@
+package valkey
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/valkey-io/valkey-go/internal/cmds"
+)
+
+//gocyclo:ignore
+func TestMPSC(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	t.Run("PutOne", func(t *testing.T) {
+		queue := newMPSC(DefaultRingScale)
+		size := 5000
+		fixture := make(map[string]struct{}, size)
+		for i := range size {
+			fixture[strconv.Itoa(i)] = struct{}{}
+		}
+
+		for cmd := range fixture {
+			go queue.PutOne(context.Background(), cmds.NewCompleted([]string{cmd}))
+		}
+
+		for len(fixture) != 0 {
+			cmd1, _, _ := queue.NextWriteCmd()
+			if cmd1.IsEmpty() {
+				runtime.Gosched()
+				continue
+			}
+			cmd2, _, ch, _ := queue.NextResultCh()
+			queue.FinishResult()
+			if cmd1.Commands()[0] != cmd2.Commands()[0] {
+				t.Fatalf("cmds read by NextWriteCmd and NextResultCh is not the same one")
+			}
+			if ch == nil || len(ch) != 0 {
+				t.Fatalf("channel from NextResultCh is broken")
+			}
+			delete(fixture, cmd1.Commands()[0])
+		}
+	})
+
+	t.Run("PutMulti", func(t *testing.T) {
+		queue := newMPSC(DefaultRingScale)
+		size := 5000
+		fixture := make(map[string]struct{}, size)
+		for i := range size {
+			fixture[strconv.Itoa(i)] = struct{}{}
+		}
+
+		base := [][]string{{"a"}, {"b"}, {"c"}, {"d"}}
+		for cmd := range fixture {
+			go queue.PutMulti(context.Background(), cmds.NewMultiCompleted(append([][]string{{cmd}}, base...)), nil)
+		}
+
+		for len(fixture) != 0 {
+			_, cmd1, _ := queue.NextWriteCmd()
+			if cmd1 == nil {
+				runtime.Gosched()
+				continue
+			}
+			_, cmd2, ch, _ := queue.NextResultCh()
+			queue.FinishResult()
+			for j := range cmd1 {
+				if cmd1[j].Commands()[0] != cmd2[j].Commands()[0] {
+					t.Fatalf("cmds read by NextWriteCmd and NextResultCh is not the same one")
+				}
+			}
+			if ch == nil || len(ch) != 0 {
+				t.Fatalf("channel from NextResultCh is broken")
+			}
+			delete(fixture, cmd1[0].Commands()[0])
+		}
+	})
+
+	t.Run("NextWriteCmd & NextResultCh", func(t *testing.T) {
+		queue := newMPSC(DefaultRingScale)
+		if one, multi, _ := queue.NextWriteCmd(); !one.IsEmpty() || multi != nil {
+			t.Fatalf("NextWriteCmd should returns nil if empty")
+		}
+		one, multi, ch, _ := queue.NextResultCh()
+		if !one.IsEmpty() || multi != nil || ch != nil {
+			t.Fatalf("NextResultCh should returns nil if not NextWriteCmd yet")
+		}
+
+		queue.PutOne(context.Background(), cmds.NewCompleted([]string{"0"}))
+		if one, _, _ := queue.NextWriteCmd(); len(one.Commands()) == 0 || one.Commands()[0] != "0" {
+			t.Fatalf("NextWriteCmd should returns next cmd")
+		}
+		one, multi, ch, _ = queue.NextResultCh()
+		if len(one.Commands()) == 0 || one.Commands()[0] != "0" || ch == nil {
+			t.Fatalf("NextResultCh should returns next cmd after NextWriteCmd")
+		} else {
+			queue.FinishResult()
+		}
+
+		queue.PutMulti(context.Background(), cmds.NewMultiCompleted([][]string{{"0"}}), nil)
+		if _, multi, _ := queue.NextWriteCmd(); len(multi) == 0 || multi[0].Commands()[0] != "0" {
+			t.Fatalf("NextWriteCmd should returns next cmd")
+		}
+		_, multi, ch, _ = queue.NextResultCh()
+		if len(multi) == 0 || multi[0].Commands()[0] != "0" || ch == nil {
+			t.Fatalf("NextResultCh should returns next cmd after NextWriteCmd")
+		} else {
+			queue.FinishResult()
+		}
+	})
+
+	t.Run("PutAsync", func(t *testing.T) {
+		q := newMPSC(DefaultRingScale)
+		var got []ValkeyResult
+		fn := func(resp ValkeyResult) { got = append(got, resp) }
+		if err := q.PutAsync(context.Background(), cmds.NewCompleted([]string{"0"}), nil, nil, fn); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if err := q.PutAsync(context.Background(), Completed{}, cmds.NewMultiCompleted([][]string{{"1"}}), nil, fn); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		for i := range 2 {
+			if _, _, ch := q.NextWriteCmd(); ch == nil {
+				t.Fatalf("NextWriteCmd should returns next cmd")
+			}
+			_, _, ch, _ := q.NextResultCh()
+			if ch == nil || cap(ch) == 0 {
+				t.Fatalf("NextResultCh should returns a buffered channel for async cmds")
+			}
+			ch <- newResult(strmsg('+', strconv.Itoa(i)), nil)
+			q.FinishResult()
+			if len(got) != i+1 || got[i].val.string() != strconv.Itoa(i) {
+				t.Fatalf("FinishResult should pass the result to the fn")
+			}
+		}
+	})
+
+	t.Run("PutOne Wakeup WaitForWrite", func(t *testing.T) {
+		queue := newMPSC(DefaultRingScale)
+		if one, _, ch := queue.NextWriteCmd(); ch == nil {
+			go func() {
+				time.Sleep(time.Millisecond * 100)
+				queue.PutOne(context.Background(), cmds.PingCmd)
+			}()
+			if one, _, ch = queue.WaitForWrite(); ch != nil && one.Commands()[0] == cmds.PingCmd.Commands()[0] {
+				return
+			}
+		}
+		t.Fatal("Should sleep")
+	})
+
+	t.Run("PutMulti Wakeup WaitForWrite", func(t *testing.T) {
+		queue := newMPSC(DefaultRingScale)
+		if _, _, ch := queue.NextWriteCmd(); ch == nil {
+			go func() {
+				time.Sleep(time.Millisecond * 100)
+				queue.PutMulti(context.Background(), []Completed{cmds.PingCmd}, nil)
+			}()
+			if _, multi, ch := queue.WaitForWrite(); ch != nil && multi[0].Commands()[0] == cmds.PingCmd.Commands()[0] {
+				return
+			}
+		}
+		t.Fatal("Should sleep")
+	})
+
+	t.Run("PutOne Context Is Done", func(t *testing.T) {
+		queue := newMPSC(1)
+		for i := range 1 << 1 {
+			queue.PutOne(context.Background(), cmds.NewCompleted([]string{strconv.Itoa(i)}))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := queue.PutOne(ctx, cmds.NewCompleted([]string{"should_fail"}))
+		if err != context.DeadlineExceeded {
+			t.Fatalf("Expected context.DeadlineExceeded error, got %v", err)
+		}
+
+		for range 1 << 1 {
+			queue.NextWriteCmd()
+		}
+		for range 1 << 1 {
+			queue.NextResultCh()
+			queue.FinishResult()
+		}
+	})
+
+	t.Run("PutMulti Context Is Done", func(t *testing.T) {
+		queue := newMPSC(1)
+		for i := range 1 << 1 {
+			queue.PutMulti(context.Background(), cmds.NewMultiCompleted([][]string{{strconv.Itoa(i)}}), nil)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := queue.PutMulti(ctx, cmds.NewMultiCompleted([][]string{{"should_fail"}}), nil)
+		if err != context.DeadlineExceeded {
+			t.Fatalf("Expected context.Canceled error, got %v", err)
+		}
+
+		for range 1 << 1 {
+			queue.NextWriteCmd()
+		}
+		for range 1 << 1 {
+			queue.NextResultCh()
+			queue.FinishResult()
+		}
+	})
+
+	t.Run("PutOne Wakeup After FinishResult", func(t *testing.T) {
+		queue := newMPSC(1)
+		for i := range 1 << 1 {
+			queue.PutOne(context.Background(), cmds.NewCompleted([]string{strconv.Itoa(i)}))
+		}
+
+		done := make(chan struct{})
+		for range 2 {
+			go func() {
+				queue.PutOne(context.Background(), cmds.NewCompleted([]string{"wait"}))
+				done <- struct{}{}
+			}()
+		}
+		time.Sleep(100 * time.Millisecond)
+
+		for range 2 {
+			queue.WaitForWrite()
+			queue.NextResultCh()
+			queue.FinishResult()
+		}
+		<-done
+		<-done
+
+		for range 2 {
+			if one, _, ch := queue.WaitForWrite(); ch == nil || one.Commands()[0] != "wait" {
+				t.Fatalf("unexpected cmd %v", one.Commands())
+			}
+			queue.NextResultCh()
+			queue.FinishResult()
+		}
+	})
+}
@@ -28,6 +28,8 @@ type conn interface {
 	DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) ValkeyResult
 	DoMulti(ctx context.Context, multi ...Completed) *valkeyresults
 	DoMultiCache(ctx context.Context, multi ...CacheableTTL) *valkeyresults
+	DoAsync(ctx context.Context, cmd Completed, fn func(ValkeyResult))
+	DoMultiAsync(ctx context.Context, resps []ValkeyResult, fn func(), multi ...Completed)
 	Receive(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error
 	DoStream(ctx context.Context, cmd Completed) ValkeyResultStream
 	DoMultiStream(ctx context.Context, multi ...Completed) MultiValkeyResultStream
@@ -36,6 +38,7 @@ type conn interface {
 	AZ() string
 	Error() error
 	Close()
+	CloseWithContext(ctx context.Context) error
 	Dial() error
 	Override(conn)
 	Acquire(ctx context.Context) wire
@@ -43,14 +46,19 @@ type conn interface {
 	Addr() string
 	SetOnCloseHook(func(error))
 	OptInCmd() cmds.Completed
+	Stats() NodeStats
+	Circuit() CircuitState
+	RTT() time.Duration
 }
 
 var _ conn = (*mux)(nil)
 
 type muxwire struct {
-	wire atomic.Value
-	sc   *singleconnect
-	mu   sync.Mutex
+	wire   atomic.Value
+	sc     *singleconnect
+	lim    *inflight // nil if MaxInflightPerConn is not set
+	mu     sync.Mutex
+	dialed atomic.Bool // whether the wire has been dialed since the muxwire is (re)activated
 }
 
 type mux struct {
@@ -59,11 +67,16 @@ type mux struct {
 	clhks    atomic.Value
 	dpool    *pool
 	spool    *pool
+	cb       *breaker
+	adapt    *adaptive
 	wireFn   wireFn
 	dst      string
-	muxwires []muxwire
+	muxwires []muxwire // the last one is dedicated to priority commands
 	maxp     int
 	maxm     int
+	rcnt     atomic.Uint64 // reconnects
+	rjcnt    atomic.Uint64 // rejected by MaxInflightPerConn
+	wnum     atomic.Int32  // the number of active muxwires
 
 	usePool bool
 	optIn   bool
@@ -74,6 +87,10 @@ func makeMux(dst string, option *ClientOption, dialFn dialFn) *mux {
 	connFn := func(ctx context.Context) (net.Conn, error) {
 		return dialFn(ctx, dst, option)
 	}
+	inv := newR2Inv(connFn, option) // shared by the pipes of the node for the client-side caching over RESP2
+	newPipe := func(ctx context.Context, connFn func(ctx context.Context) (net.Conn, error), option *ClientOption) (*pipe, error) {
+		return _newPipe(ctx, connFn, option, false, false, inv)
+	}
 	wireFn := func(pipeFn pipeFn) func(context.Context) wire {
 		return func(ctx context.Context) (w wire) {
 			w, err := pipeFn(ctx, connFn, option)
@@ -88,32 +105,57 @@ func makeMux(dst string, option *ClientOption, dialFn dialFn) *mux {
 }
 
 func newMux(dst string, option *ClientOption, init, dead wire, wireFn wireFn, wireNoBgFn wireFn) *mux {
-	var multiplex int
+	var multiplex, maximum int
 	if option.PipelineMultiplex >= 0 {
 		multiplex = 1 << option.PipelineMultiplex
 	} else {
 		multiplex = 1
 	}
+	maximum = multiplex
+	if option.AdaptivePipelineMultiplex != nil {
+		maximum = max(multiplex, 1<<adaptiveMaxMultiplex(option.AdaptivePipelineMultiplex))
+	}
 	m := &mux{dst: dst, init: init, dead: dead, wireFn: wireFn,
-		muxwires: make([]muxwire, multiplex),
+		muxwires: make([]muxwire, maximum+1),
 		maxp:     runtime.GOMAXPROCS(0),
 		maxm:     option.BlockingPipeline,
 
 		usePool: option.DisableAutoPipelining,
-		optIn:   isOptIn(option.ClientTrackingOptions),
+		optIn:   isOptIn(option),
 	}
 	m.clhks.Store(emptyclhks)
+	m.wnum.Store(int32(multiplex))
 	for i := 0; i < len(m.muxwires); i++ {
 		m.muxwires[i].wire.Store(init)
+		if option.MaxInflightPerConn > 0 {
+			m.muxwires[i].lim = newInflight(option.MaxInflightPerConn, option.WaitOnOverload)
+		}
+	}
+	if maximum > multiplex {
+		m.adapt = newAdaptive(m, multiplex, maximum, option.AdaptivePipelineMultiplex)
 	}
 
 	m.dpool = newPool(option.BlockingPoolSize, dead, option.BlockingPoolCleanup, option.BlockingPoolMinSize, wireFn)
 	m.spool = newPool(option.BlockingPoolSize, dead, option.BlockingPoolCleanup, option.BlockingPoolMinSize, wireNoBgFn)
+	if option.BlockingPoolMinIdle > 0 {
+		m.dpool.keepIdle(option.BlockingPoolMinIdle, option.BlockingPoolHealthCheck)
+		if m.usePool {
+			m.spool.keepIdle(option.BlockingPoolMinIdle, option.BlockingPoolHealthCheck)
+		}
+	}
+	if option.CircuitBreaker != nil {
+		m.cb = newBreaker(dst, option.CircuitBreaker, func(ctx context.Context) error {
+			return m.pipeline(ctx, cmds.PingCmd).Error()
+		})
+	}
 	return m
 }
 
-func isOptIn(opts []string) bool {
-	for _, opt := range opts {
+func isOptIn(option *ClientOption) bool {
+	if option.Broadcast != nil {
+		return false
+	}
+	for _, opt := range option.ClientTrackingOptions {
 		if opt := strings.ToUpper(opt); opt == "BCAST" || opt == "OPTOUT" {
 			return false
 		}
@@ -158,6 +200,9 @@ func (m *mux) _pipe(ctx context.Context, i uint16) (w wire, err error) {
 	if w = m.muxwires[i].wire.Load().(wire); w != m.init {
 		return w, nil
 	}
+	if m.inactive(i) { // the i is picked before the adaptive multiplex scales down, so it is redirected to an active one.
+		return m._pipe(ctx, i&uint16(m.active()-1))
+	}
 
 	m.muxwires[i].mu.Lock()
 	sc := m.muxwires[i].sc
@@ -174,8 +219,14 @@ func (m *mux) _pipe(ctx context.Context, i uint16) (w wire, err error) {
 
 	if w = m.muxwires[i].wire.Load().(wire); w == m.init {
 		if w = m.wireFn(ctx); w != m.dead {
+			if m.muxwires[i].dialed.Swap(true) {
+				m.rcnt.Add(1)
+			}
 			m.setCloseHookOnWire(i, w)
 			m.muxwires[i].wire.Store(w)
+			if m.inactive(i) { // the adaptive multiplex scales down during the dialing.
+				m.adapt.retire(int(i), int(i)+1)
+			}
 		} else {
 			if err = w.Error(); err != ErrClosing {
 				m.clhks.Load().(func(error))(err)
@@ -221,17 +272,36 @@ func (m *mux) Error() error {
 	return m.pipe(context.Background(), 0).Error()
 }
 
+func (m *mux) Circuit() CircuitState {
+	if m.cb == nil {
+		return CircuitClosed
+	}
+	return m.cb.State()
+}
+
 func (m *mux) DoStream(ctx context.Context, cmd Completed) ValkeyResultStream {
+	if m.cb != nil && !m.cb.allow() {
+		return ValkeyResultStream{e: ErrCircuitOpen}
+	}
 	wire := m.spool.Acquire(ctx)
 	return wire.DoStream(ctx, m.spool, cmd)
 }
 
 func (m *mux) DoMultiStream(ctx context.Context, multi ...Completed) MultiValkeyResultStream {
+	if m.cb != nil && !m.cb.allow() {
+		return ValkeyResultStream{e: ErrCircuitOpen}
+	}
 	wire := m.spool.Acquire(ctx)
 	return wire.DoMultiStream(ctx, m.spool, multi...)
 }
 
 func (m *mux) Do(ctx context.Context, cmd Completed) (resp ValkeyResult) {
+	if m.cb != nil {
+		if !m.cb.allow() {
+			return newErrResult(ErrCircuitOpen)
+		}
+		defer func() { m.cb.record(ctx, resp.NonValkeyError()) }()
+	}
 	if m.usePool && !cmd.IsPipe() {
 		resp = m.blocking(m.spool, ctx, cmd)
 	} else if cmd.IsBlock() {
@@ -243,6 +313,12 @@ func (m *mux) Do(ctx context.Context, cmd Completed) (resp ValkeyResult) {
 }
 
 func (m *mux) DoMulti(ctx context.Context, multi ...Completed) (resp *valkeyresults) {
+	if m.cb != nil {
+		if !m.cb.allow() {
+			return errResults(len(multi), ErrCircuitOpen)
+		}
+		defer func() { m.cb.recordMulti(ctx, resp.s) }()
+	}
 	for _, cmd := range multi {
 		if cmd.IsPipe() {
 			return m.pipelineMulti(ctx, multi)
@@ -263,6 +339,73 @@ block:
 	return m.blockingMulti(m.dpool, ctx, multi)
 }
 
+// DoAsync is similar to Do, but it passes the result to the fn instead of waiting for it.
+// The fn is called by the goroutine reading responses from the connection, so it must not block.
+func (m *mux) DoAsync(ctx context.Context, cmd Completed, fn func(ValkeyResult)) {
+	if m.cb != nil {
+		if !m.cb.allow() {
+			fn(newErrResult(ErrCircuitOpen))
+			return
+		}
+		done := fn
+		fn = func(resp ValkeyResult) {
+			m.cb.record(ctx, resp.NonValkeyError())
+			done(resp)
+		}
+	}
+	if m.usePool && !cmd.IsPipe() {
+		go func() { fn(m.blocking(m.spool, ctx, cmd)) }()
+	} else if cmd.IsBlock() {
+		go func() { fn(m.blocking(m.dpool, ctx, cmd)) }()
+	} else {
+		m.pipelineAsync(ctx, cmd, fn)
+	}
+}
+
+// DoMultiAsync is similar to DoMulti, but it fills the resps and calls the fn instead of waiting for them.
+// The fn is called by the goroutine reading responses from the connection, so it must not block.
+func (m *mux) DoMultiAsync(ctx context.Context, resps []ValkeyResult, fn func(), multi ...Completed) {
+	if m.cb != nil {
+		if !m.cb.allow() {
+			fillResps(resps, ErrCircuitOpen)
+			fn()
+			return
+		}
+		done := fn
+		fn = func() {
+			m.cb.recordMulti(ctx, resps)
+			done()
+		}
+	}
+	for _, cmd := range multi {
+		if cmd.IsPipe() {
+			m.pipelineMultiAsync(ctx, resps, fn, multi)
+			return
+		}
+		if cmd.IsBlock() {
+			cmds.ToBlock(&multi[0]) // mark the first cmd as blocked if one of them is blocked to shortcut later check.
+			goto block
+		}
+	}
+	if m.usePool || (len(multi) >= m.maxm && m.maxm > 0) {
+		goto block // use a dedicated connection if the pipeline is too large
+	}
+	m.pipelineMultiAsync(ctx, resps, fn, multi)
+	return
+block:
+	go func() {
+		var results *valkeyresults
+		if m.usePool {
+			results = m.blockingMulti(m.spool, ctx, multi)
+		} else {
+			results = m.blockingMulti(m.dpool, ctx, multi)
+		}
+		copy(resps, results.s)
+		resultsp.Put(results)
+		fn()
+	}()
+}
+
 func (m *mux) blocking(pool *pool, ctx context.Context, cmd Completed) (resp ValkeyResult) {
 	wire := pool.Acquire(ctx)
 	resp = wire.Do(ctx, cmd)
@@ -287,18 +430,35 @@ func (m *mux) blockingMulti(pool *pool, ctx context.Context, cmd []Completed) (r
 }
 
 func (m *mux) pipeline(ctx context.Context, cmd Completed) (resp ValkeyResult) {
-	slot := slotfn(len(m.muxwires), cmd.Slot(), cmd.NoReply())
+retry:
+	slot := m.slot(cmd)
+	if err := m.admit(ctx, slot, 1); err != nil {
+		return newErrResult(err)
+	}
 	wire := m.pipe(ctx, slot)
-	if resp = wire.Do(ctx, cmd); isBroken(resp.NonValkeyError(), wire) {
+	resp = wire.Do(ctx, cmd)
+	m.finish(slot, 1)
+	if isBroken(resp.NonValkeyError(), wire) {
 		m.muxwires[slot].wire.CompareAndSwap(wire, m.init)
+	} else if m.retired(slot, wire, resp.NonValkeyError()) {
+		goto retry
 	}
 	return resp
 }
 
 func (m *mux) pipelineMulti(ctx context.Context, cmd []Completed) (resp *valkeyresults) {
-	slot := slotfn(len(m.muxwires), cmd[0].Slot(), cmd[0].NoReply())
+retry:
+	slot := m.slotMulti(cmd)
+	if err := m.admit(ctx, slot, len(cmd)); err != nil {
+		return errResults(len(cmd), err)
+	}
 	wire := m.pipe(ctx, slot)
 	resp = wire.DoMulti(ctx, cmd...)
+	m.finish(slot, len(cmd))
+	if m.retired(slot, wire, resp.s[0].NonValkeyError()) {
+		resultsp.Put(resp)
+		goto retry
+	}
 	for _, r := range resp.s {
 		if isBroken(r.NonValkeyError(), wire) {
 			m.muxwires[slot].wire.CompareAndSwap(wire, m.init)
@@ -308,19 +468,86 @@ func (m *mux) pipelineMulti(ctx context.Context, cmd []Completed) (resp *valkeyr
 	return resp
 }
 
-func (m *mux) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) ValkeyResult {
-	slot := cmd.Slot() & uint16(len(m.muxwires)-1)
+func (m *mux) pipelineAsync(ctx context.Context, cmd Completed, fn func(ValkeyResult)) {
+	slot := m.slot(cmd)
+	if err := m.admit(ctx, slot, 1); err != nil {
+		fn(newErrResult(err))
+		return
+	}
+	wire := m.pipe(ctx, slot)
+	wire.DoAsync(ctx, cmd, func(resp ValkeyResult) {
+		m.finish(slot, 1)
+		if isBroken(resp.NonValkeyError(), wire) {
+			m.muxwires[slot].wire.CompareAndSwap(wire, m.init)
+		} else if m.retired(slot, wire, resp.NonValkeyError()) {
+			go func() { fn(m.pipeline(ctx, cmd)) }()
+			return
+		}
+		fn(resp)
+	})
+}
+
+func (m *mux) pipelineMultiAsync(ctx context.Context, resps []ValkeyResult, fn func(), multi []Completed) {
+	slot := m.slotMulti(multi)
+	if err := m.admit(ctx, slot, len(multi)); err != nil {
+		fillResps(resps, err)
+		fn()
+		return
+	}
+	wire := m.pipe(ctx, slot)
+	wire.DoMultiAsync(ctx, resps, func() {
+		m.finish(slot, len(multi))
+		if m.retired(slot, wire, resps[0].NonValkeyError()) {
+			go func() {
+				results := m.pipelineMulti(ctx, multi)
+				copy(resps, results.s)
+				resultsp.Put(results)
+				fn()
+			}()
+			return
+		}
+		for _, r := range resps {
+			if isBroken(r.NonValkeyError(), wire) {
+				m.muxwires[slot].wire.CompareAndSwap(wire, m.init)
+				break
+			}
+		}
+		fn()
+	}, multi...)
+}
+
+func (m *mux) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) (resp ValkeyResult) {
+	if m.cb != nil {
+		if !m.cb.allow() {
+			return newErrResult(ErrCircuitOpen)
+		}
+		defer func() { m.cb.record(ctx, resp.NonValkeyError()) }()
+	}
+retry:
+	slot := cmd.Slot() & uint16(m.active()-1)
+	if err := m.admit(ctx, slot, 1); err != nil {
+		return newErrResult(err)
+	}
 	wire := m.pipe(ctx, slot)
-	resp := wire.DoCache(ctx, cmd, ttl)
+	resp = wire.DoCache(ctx, cmd, ttl)
+	m.finish(slot, 1)
 	if isBroken(resp.NonValkeyError(), wire) {
 		m.muxwires[slot].wire.CompareAndSwap(wire, m.init)
+	} else if m.retired(slot, wire, resp.NonValkeyError()) {
+		goto retry
 	}
 	return resp
 }
 
 func (m *mux) DoMultiCache(ctx context.Context, multi ...CacheableTTL) (results *valkeyresults) {
+	if m.cb != nil {
+		if !m.cb.allow() {
+			return errResults(len(multi), ErrCircuitOpen)
+		}
+		defer func() { m.cb.recordMulti(ctx, results.s) }()
+	}
 	var slots *muxslots
-	var mask = uint16(len(m.muxwires) - 1)
+	var mask = uint16(m.active() - 1)
 
 	if mask == 0 {
 		return m.doMultiCache(ctx, 0, multi)
@@ -368,8 +595,16 @@ func (m *mux) DoMultiCache(ctx context.Context, multi ...CacheableTTL) (results
 }
 
 func (m *mux) doMultiCache(ctx context.Context, slot uint16, multi []CacheableTTL) (resps *valkeyresults) {
+	if err := m.admit(ctx, slot, len(multi)); err != nil {
+		return errResults(len(multi), err)
+	}
 	wire := m.pipe(ctx, slot)
 	resps = wire.DoMultiCache(ctx, multi...)
+	m.finish(slot, len(multi))
+	if len(resps.s) != 0 && m.retired(slot, wire, resps.s[0].NonValkeyError()) {
+		resultsp.Put(resps)
+		return m.doMultiCache(ctx, 0, multi) // the slot 0 is never retired
+	}
 	for _, r := range resps.s {
 		if isBroken(r.NonValkeyError(), wire) {
 			m.muxwires[slot].wire.CompareAndSwap(wire, m.init)
@@ -380,7 +615,7 @@ func (m *mux) doMultiCache(ctx context.Context, slot uint16, multi []CacheableTT
 }
 
 func (m *mux) Receive(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
-	slot := slotfn(len(m.muxwires), subscribe.Slot(), subscribe.NoReply())
+	slot := slotfn(m.active(), subscribe.Slot(), subscribe.NoReply())
 	wire := m.pipe(ctx, slot)
 	err := wire.Receive(ctx, subscribe, fn)
 	if isBroken(err, wire) {
@@ -404,6 +639,9 @@ func (m *mux) Store(w wire) {
 }
 
 func (m *mux) Close() {
+	if m.adapt != nil {
+		m.adapt.stop()
+	}
 	for i := 0; i < len(m.muxwires); i++ {
 		if prev := m.muxwires[i].wire.Swap(m.dead).(wire); prev != m.init && prev != m.dead {
 			prev.Close()
@@ -413,14 +651,253 @@ func (m *mux) Close() {
 	m.spool.Close()
 }
 
+func (m *mux) Stats() (s NodeStats) {
+	for i := 0; i < len(m.muxwires); i++ {
+		if w := m.muxwires[i].wire.Load().(wire); w != m.init && w != m.dead && w.Error() == nil {
+			s.Pipelines = append(s.Pipelines, w.Stats())
+		}
+	}
+	s.BlockingPool = m.dpool.Stats()
+	s.StreamPool = m.spool.Stats()
+	s.Reconnects = m.rcnt.Load()
+	s.Rejected = m.rjcnt.Load()
+	s.Circuit = m.Circuit()
+	return s
+}
+
+// RTT returns the average of the round-trip time EWMAs of the measured auto-pipelining connections.
+func (m *mux) RTT() (rtt time.Duration) {
+	n := 0
+	for i := 0; i < len(m.muxwires); i++ {
+		if w := m.muxwires[i].wire.Load().(wire); w != m.init && w != m.dead {
+			if d := w.RTT(); d > 0 {
+				rtt += d
+				n++
+			}
+		}
+	}
+	if n > 0 {
+		rtt /= time.Duration(n)
+	}
+	return rtt
+}
+
+func (m *mux) CloseWithContext(ctx context.Context) error {
+	if m.adapt != nil {
+		m.adapt.stop()
+	}
+	wires := make([]wire, 0, len(m.muxwires))
+	for i := 0; i < len(m.muxwires); i++ {
+		if prev := m.muxwires[i].wire.Swap(m.dead).(wire); prev != m.init && prev != m.dead {
+			wires = append(wires, prev)
+		}
+	}
+	err := closeWithContext(ctx, wires)
+	if e := closeWithContext(ctx, []*pool{m.dpool, m.spool}); err == nil {
+		err = e
+	}
+	return err
+}
+
 func (m *mux) Addr() string {
 	return m.dst
 }
 
+// closeWithContext closes all the closers concurrently and returns the first error.
+func closeWithContext[T interface{ CloseWithContext(context.Context) error }](ctx context.Context, closers []T) (err error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(closers))
+	for _, c := range closers {
+		go func(c T) {
+			defer wg.Done()
+			if e := c.CloseWithContext(ctx); e != nil {
+				mu.Lock()
+				if err == nil {
+					err = e
+				}
+				mu.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+	return err
+}
+
 func isBroken(err error, w wire) bool {
 	return err != nil && err != ErrClosing && w.Error() != nil
 }
 
+// admit reserves n in-flight commands on the slot if the MaxInflightPerConn is set.
+func (m *mux) admit(ctx context.Context, slot uint16, n int) error {
+	if lim := m.muxwires[slot].lim; lim != nil {
+		if err := lim.acquire(ctx, int64(n)); err != nil {
+			m.rjcnt.Add(uint64(n))
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mux) finish(slot uint16, n int) {
+	if lim := m.muxwires[slot].lim; lim != nil {
+		lim.release(int64(n))
+	}
+}
+
+func errResults(n int, err error) *valkeyresults {
+	results := resultsp.Get(n, n)
+	for i := range results.s {
+		results.s[i] = newErrResult(err)
+	}
+	return results
+}
+
+// active returns the number of muxwires used for pipelining, which is always a power of 2.
+func (m *mux) active() int {
+	return int(m.wnum.Load())
+}
+
+// inactive reports whether the muxwires[i] is out of the active ones because the adaptive multiplex has scaled down.
+func (m *mux) inactive(i uint16) bool {
+	return m.adapt != nil && int(i) >= m.active() && int(i) != len(m.muxwires)-1
+}
+
+// retired reports whether the w is closed because it is removed from the slot by the adaptive multiplex.
+func (m *mux) retired(slot uint16, w wire, err error) bool {
+	if m.adapt == nil || err != ErrClosing {
+		return false
+	}
+	cur := m.muxwires[slot].wire.Load().(wire)
+	return cur != w && cur != m.dead
+}
+
+const (
+	defaultAdaptiveInterval      = time.Second
+	defaultAdaptiveQueueDepth    = 64
+	defaultAdaptiveIdleIntervals = 10
+)
+
+func adaptiveMaxMultiplex(opt *AdaptiveMultiplexOption) int {
+	if opt.MaxPipelineMultiplex <= 0 || opt.MaxPipelineMultiplex > MaxPipelineMultiplex {
+		return MaxPipelineMultiplex
+	}
+	return opt.MaxPipelineMultiplex
+}
+
+// adaptive scales the active muxwires of a mux between min and max periodically.
+type adaptive struct {
+	m        *mux
+	timer    *time.Timer
+	mu       sync.Mutex
+	interval time.Duration
+	latency  time.Duration
+	depth    int
+	idles    int
+	idle     int
+	min      int
+	max      int
+	stopped  bool
+}
+
+func newAdaptive(m *mux, min, max int, opt *AdaptiveMultiplexOption) *adaptive {
+	a := &adaptive{
+		m:        m,
+		min:      min,
+		max:      max,
+		interval: opt.Interval,
+		latency:  opt.WriteLatency,
+		depth:    opt.QueueDepth,
+		idles:    opt.IdleIntervals,
+	}
+	if a.interval <= 0 {
+		a.interval = defaultAdaptiveInterval
+	}
+	if a.depth <= 0 {
+		a.depth = defaultAdaptiveQueueDepth
+	}
+	if a.idles <= 0 {
+		a.idles = defaultAdaptiveIdleIntervals
+	}
+	a.timer = time.AfterFunc(a.interval, a.evaluate)
+	return a
+}
+
+func (a *adaptive) evaluate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stopped {
+		return
+	}
+	a.scale()
+	a.timer.Reset(a.interval)
+}
+
+func (a *adaptive) scale() {
+	n := a.m.active()
+	depth, latency := 0, time.Duration(0)
+	for i := 0; i < n; i++ {
+		if w := a.m.muxwires[i].wire.Load().(wire); w != a.m.init && w != a.m.dead {
+			if s := w.Stats(); s.Inflight > 0 {
+				depth += s.Inflight
+				latency = max(latency, s.WriteLatency) // the latency of an idle wire may be stale
+			}
+		}
+	}
+	switch {
+	case n < a.max && (depth >= n*a.depth || (a.latency > 0 && latency >= a.latency)):
+		a.idle = 0
+		a.m.wnum.Store(int32(n * 2))
+	case n > a.min && depth*4 < n*a.depth && (a.latency == 0 || latency*2 < a.latency):
+		if a.idle++; a.idle >= a.idles {
+			a.idle = 0
+			a.m.wnum.Store(int32(n / 2))
+			a.retire(n/2, n)
+		}
+	default:
+		a.idle = 0
+	}
+}
+
+// retire removes the wires in the range of [from, to) from muxwires and closes them after their in-flight commands are fulfilled.
+func (a *adaptive) retire(from, to int) {
+	for i := from; i < to; i++ {
+		if w := a.m.muxwires[i].wire.Load().(wire); w != a.m.init && w != a.m.dead && a.m.muxwires[i].wire.CompareAndSwap(w, a.m.init) {
+			a.m.muxwires[i].dialed.Store(false) // dialing it again after scaling up is not a reconnect.
+			go func(w wire) {
+				ctx, cancel := context.WithTimeout(context.Background(), a.interval*time.Duration(a.idles))
+				defer cancel()
+				w.CloseWithContext(ctx)
+			}(w)
+		}
+	}
+}
+
+func (a *adaptive) stop() {
+	a.mu.Lock()
+	a.stopped = true
+	a.timer.Stop()
+	a.mu.Unlock()
+}
+
+// slot returns the index of muxwires for the cmd. Priority commands use the last one to avoid queuing behind others.
+func (m *mux) slot(cmd Completed) uint16 {
+	if cmd.IsPriority() && !cmd.NoReply() {
+		return uint16(len(m.muxwires) - 1)
+	}
+	return slotfn(m.active(), cmd.Slot(), cmd.NoReply())
+}
+
+// slotMulti is similar to slot, but it only uses the priority muxwire if all the commands are priority ones.
+func (m *mux) slotMulti(multi []Completed) uint16 {
+	for _, cmd := range multi {
+		if !cmd.IsPriority() {
+			return slotfn(m.active(), multi[0].Slot(), multi[0].NoReply())
+		}
+	}
+	return m.slot(multi[0])
+}
+
 func slotfn(n int, ks uint16, noreply bool) uint16 {
 	if n == 1 || ks == cmds.NoSlot || noreply {
 		return 0
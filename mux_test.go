@@ -140,7 +140,7 @@ func TestNewMuxPipelineMultiplex(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	for _, v := range []int{-1, 0, 1, 2} {
 		m := makeMux("", &ClientOption{PipelineMultiplex: v}, func(_ context.Context, dst string, opt *ClientOption) (net.Conn, error) { return nil, nil })
-		if (v < 0 && len(m.muxwires) != 1) || (v >= 0 && len(m.muxwires) != 1<<v) {
+		if (v < 0 && len(m.muxwires) != 1+1) || (v >= 0 && len(m.muxwires) != 1<<v+1) { // plus the priority one
 			t.Fatalf("unexpected len(m.muxwires): %v", len(m.muxwires))
 		}
 	}
@@ -1169,6 +1169,541 @@ func TestMuxRegisterCloseHook(t *testing.T) {
 	})
 }
 
+func TestMuxCloseWithContext(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var closed int32
+	m, checkClean := setupMux([]*mockWire{
+		{
+			CloseCtxFn: func(ctx context.Context) error {
+				atomic.AddInt32(&closed, 1)
+				return nil
+			},
+		},
+	})
+	defer checkClean(t)
+	if err := m.Dial(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := m.CloseWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if atomic.LoadInt32(&closed) != 1 {
+		t.Fatalf("wire is not closed")
+	}
+
+	t.Run("Timeout", func(t *testing.T) {
+		m, checkClean := setupMux([]*mockWire{
+			{
+				CloseCtxFn: func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+		})
+		defer checkClean(t)
+		if err := m.Dial(); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		if err := m.CloseWithContext(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
+
+func TestMuxStats(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var hook atomic.Value
+	m, checkClean := setupMuxWithOption([]*mockWire{
+		{
+			StatsFn: func() PipelineStats {
+				return PipelineStats{Inflight: 1}
+			},
+			SetOnCloseHookFn: func(fn func(error)) {
+				hook.Store(fn)
+			},
+		},
+		{
+			StatsFn: func() PipelineStats {
+				return PipelineStats{Inflight: 2, CacheEntries: 1}
+			},
+		},
+	}, &ClientOption{})
+	defer checkClean(t)
+	defer m.Close()
+
+	if s := m.Stats(); len(s.Pipelines) != 0 || s.Reconnects != 0 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+	if err := m.Dial(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if s := m.Stats(); len(s.Pipelines) != 1 || s.Inflight() != 1 || s.Reconnects != 0 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+	hook.Load().(func(error))(errors.New("any")) // the first wire is discarded and the next one is counted as a reconnection
+	if err := m.Dial(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if s := m.Stats(); len(s.Pipelines) != 1 || s.Inflight() != 2 || s.Pipelines[0].CacheEntries != 1 || s.Reconnects != 1 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+	if s := m.Stats(); s.BlockingPool != (PoolStats{}) || s.StreamPool != (PoolStats{}) {
+		t.Fatalf("unexpected stats %v", s)
+	}
+}
+
+func TestMuxRTT(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	m, checkClean := setupMuxWithOption([]*mockWire{
+		{RTTFn: func() time.Duration { return time.Millisecond }},
+		{RTTFn: func() time.Duration { return 0 }},
+		{RTTFn: func() time.Duration { return 3 * time.Millisecond }},
+	}, &ClientOption{PipelineMultiplex: 1})
+	defer checkClean(t)
+	defer m.Close()
+
+	if rtt := m.RTT(); rtt != 0 {
+		t.Fatalf("unexpected rtt %v", rtt)
+	}
+	for i := uint16(0); i < 3; i++ {
+		m.pipe(context.Background(), i)
+	}
+	if rtt := m.RTT(); rtt != 2*time.Millisecond { // the unmeasured one is ignored
+		t.Fatalf("unexpected rtt %v", rtt)
+	}
+}
+
+func TestMuxAdaptiveMultiplexRetired(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	wires := []*mockWire{{}, {}}
+	count := -1
+	m := newMux("", &ClientOption{AdaptivePipelineMultiplex: &AdaptiveMultiplexOption{Interval: time.Hour}}, (*mockWire)(nil), &mockWire{}, func(_ context.Context) wire {
+		count++
+		return wires[count]
+	}, nil)
+	defer m.Close()
+
+	m.wnum.Store(2)
+	w0, w1 := m.pipe(context.Background(), 0), m.pipe(context.Background(), 1)
+	m.wnum.Store(1)
+	m.adapt.retire(1, 2)
+	if !m.retired(1, w1, ErrClosing) {
+		t.Fatalf("unexpected not retired")
+	}
+	if m.retired(1, w1, errors.New("any")) || m.retired(0, w0, ErrClosing) {
+		t.Fatalf("unexpected retired")
+	}
+	m.Close()
+	if m.retired(1, w1, ErrClosing) {
+		t.Fatalf("unexpected retired after close")
+	}
+}
+
+func TestMuxMaxInflightPerConn(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	m, checkClean := setupMuxWithOption([]*mockWire{
+		{
+			DoFn: func(cmd Completed) ValkeyResult {
+				blocked <- struct{}{}
+				<-release
+				return newResult(strmsg('+', "OK"), nil)
+			},
+		},
+	}, &ClientOption{MaxInflightPerConn: 1})
+	defer checkClean(t)
+	defer m.Close()
+
+	done := make(chan struct{})
+	go func() {
+		m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"}))
+		close(done)
+	}()
+	<-blocked
+
+	if err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != ErrOverloaded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	for _, resp := range m.DoMulti(context.Background(), cmds.NewCompleted([]string{"GET", "a"}), cmds.NewCompleted([]string{"GET", "b"})).s {
+		if err := resp.Error(); err != ErrOverloaded {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+	if err := m.DoCache(context.Background(), Cacheable(cmds.NewCompleted([]string{"GET", "a"})), time.Second).Error(); err != ErrOverloaded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if s := m.Stats(); s.Rejected != 4 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+	close(release)
+	<-done
+	go func() { <-blocked }()
+	if err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestMuxDoAsync(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	t.Run("pipeline", func(t *testing.T) {
+		m, checkClean := setupMux([]*mockWire{
+			{
+				DoFn: func(cmd Completed) ValkeyResult {
+					return newResult(strmsg('+', cmd.Commands()[1]), nil)
+				},
+				DoMultiFn: func(multi ...Completed) *valkeyresults {
+					resps := make([]ValkeyResult, len(multi))
+					for i, cmd := range multi {
+						resps[i] = newResult(strmsg('+', cmd.Commands()[1]), nil)
+					}
+					return &valkeyresults{s: resps}
+				},
+			},
+		})
+		defer checkClean(t)
+		defer m.Close()
+
+		ch := make(chan ValkeyResult, 1)
+		m.DoAsync(context.Background(), cmds.NewCompleted([]string{"GET", "a"}), func(resp ValkeyResult) { ch <- resp })
+		if v, err := (<-ch).ToString(); err != nil || v != "a" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		resps := make([]ValkeyResult, 2)
+		done := make(chan struct{})
+		m.DoMultiAsync(context.Background(), resps, func() { close(done) }, cmds.NewCompleted([]string{"GET", "b"}), cmds.NewCompleted([]string{"GET", "c"}))
+		<-done
+		for i, want := range []string{"b", "c"} {
+			if v, err := resps[i].ToString(); err != nil || v != want {
+				t.Fatalf("unexpected response %v %v", v, err)
+			}
+		}
+	})
+
+	t.Run("blocking", func(t *testing.T) {
+		m, checkClean := setupMux([]*mockWire{
+			{
+				// leave first wire for pipeline calls
+			},
+			{
+				DoFn: func(cmd Completed) ValkeyResult {
+					return newResult(strmsg('+', "BLOCK_COMMANDS_RESPONSE"), nil)
+				},
+				DoMultiFn: func(multi ...Completed) *valkeyresults {
+					return &valkeyresults{s: []ValkeyResult{newResult(strmsg('+', "BLOCK_COMMANDS_RESPONSE"), nil)}}
+				},
+			},
+		})
+		defer checkClean(t)
+		defer m.Close()
+		if err := m.Dial(); err != nil {
+			t.Fatalf("unexpected dial error %v", err)
+		}
+
+		ch := make(chan ValkeyResult, 1)
+		m.DoAsync(context.Background(), cmds.NewBlockingCompleted([]string{"BLOCK"}), func(resp ValkeyResult) { ch <- resp })
+		if v, err := (<-ch).ToString(); err != nil || v != "BLOCK_COMMANDS_RESPONSE" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		resps := make([]ValkeyResult, 1)
+		done := make(chan struct{})
+		m.DoMultiAsync(context.Background(), resps, func() { close(done) }, cmds.NewBlockingCompleted([]string{"BLOCK"}))
+		<-done
+		if v, err := resps[0].ToString(); err != nil || v != "BLOCK_COMMANDS_RESPONSE" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("overloaded", func(t *testing.T) {
+		blocked := make(chan struct{})
+		release := make(chan struct{})
+		m, checkClean := setupMuxWithOption([]*mockWire{
+			{
+				DoFn: func(cmd Completed) ValkeyResult {
+					blocked <- struct{}{}
+					<-release
+					return newResult(strmsg('+', "OK"), nil)
+				},
+			},
+		}, &ClientOption{MaxInflightPerConn: 1})
+		defer checkClean(t)
+		defer m.Close()
+
+		done := make(chan struct{})
+		go m.DoAsync(context.Background(), cmds.NewCompleted([]string{"GET", "a"}), func(resp ValkeyResult) { close(done) })
+		<-blocked
+		ch := make(chan ValkeyResult, 1)
+		m.DoAsync(context.Background(), cmds.NewCompleted([]string{"GET", "a"}), func(resp ValkeyResult) { ch <- resp })
+		if err := (<-ch).Error(); err != ErrOverloaded {
+			t.Fatalf("unexpected err %v", err)
+		}
+		resps := make([]ValkeyResult, 2)
+		m.DoMultiAsync(context.Background(), resps, func() {}, cmds.NewCompleted([]string{"GET", "a"}), cmds.NewCompleted([]string{"GET", "b"}))
+		for _, resp := range resps {
+			if err := resp.Error(); err != ErrOverloaded {
+				t.Fatalf("unexpected err %v", err)
+			}
+		}
+		close(release)
+		<-done
+	})
+}
+
+func TestMuxPriority(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var bulk, high int
+	m, checkClean := setupMux([]*mockWire{
+		{
+			DoFn: func(cmd Completed) ValkeyResult {
+				bulk++
+				return newResult(strmsg('+', "bulk"), nil)
+			},
+			DoMultiFn: func(multi ...Completed) *valkeyresults {
+				bulk++
+				return &valkeyresults{s: []ValkeyResult{newResult(strmsg('+', "bulk"), nil)}}
+			},
+		},
+		{
+			DoFn: func(cmd Completed) ValkeyResult {
+				high++
+				return newResult(strmsg('+', "high"), nil)
+			},
+			DoMultiFn: func(multi ...Completed) *valkeyresults {
+				high++
+				return &valkeyresults{s: []ValkeyResult{newResult(strmsg('+', "high"), nil)}}
+			},
+		},
+	})
+	defer checkClean(t)
+	defer m.Close()
+	if err := m.Dial(); err != nil {
+		t.Fatalf("unexpected dial error %v", err)
+	}
+
+	if v, err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"}).ToPriority()).ToString(); err != nil || v != "high" {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+	if v, err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).ToString(); err != nil || v != "bulk" {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+	if v, err := m.DoMulti(context.Background(), cmds.NewCompleted([]string{"GET", "a"}).ToPriority(), cmds.NewCompleted([]string{"GET", "b"}).ToPriority()).s[0].ToString(); err != nil || v != "high" {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+	if v, err := m.DoMulti(context.Background(), cmds.NewCompleted([]string{"GET", "a"}).ToPriority(), cmds.NewCompleted([]string{"GET", "b"})).s[0].ToString(); err != nil || v != "bulk" {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+	if bulk != 2 || high != 2 {
+		t.Fatalf("unexpected counts %v %v", bulk, high)
+	}
+	if s := m.Stats(); len(s.Pipelines) != 2 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+}
+
+func TestMuxBlockingPoolMinIdle(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	m, checkClean := setupMuxWithOption([]*mockWire{
+		{
+			DoFn: func(cmd Completed) ValkeyResult {
+				return newResult(strmsg('+', "BLOCK_COMMANDS_RESPONSE"), nil)
+			},
+		},
+		{},
+	}, &ClientOption{BlockingPoolMinIdle: 1, BlockingPoolHealthCheck: time.Hour})
+	defer checkClean(t)
+	defer m.Close()
+
+	for m.Stats().BlockingPool.Idle != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	w := m.Acquire(context.Background())
+	if v, err := w.Do(context.Background(), cmds.NewBlockingCompleted([]string{"BLOCK"})).ToString(); err != nil || v != "BLOCK_COMMANDS_RESPONSE" {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+	for m.Stats().BlockingPool.Idle != 1 { // refilled
+		time.Sleep(time.Millisecond)
+	}
+	m.Store(w)
+	if s := m.Stats(); s.BlockingPool.Idle != 2 || s.StreamPool.Size != 0 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+}
+
+func TestMuxCircuitBreaker(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var fail, probeFail atomic.Bool
+	changes := make(chan [2]CircuitState, 10)
+	m, checkClean := setupMuxWithOption([]*mockWire{
+		{
+			DoFn: func(cmd Completed) ValkeyResult {
+				if cmd.Commands()[0] == "PING" {
+					if probeFail.Load() {
+						return newErrResult(context.DeadlineExceeded)
+					}
+					return newResult(strmsg('+', "PONG"), nil)
+				}
+				if fail.Load() {
+					return newErrResult(context.DeadlineExceeded)
+				}
+				return newResult(strmsg('+', "OK"), nil)
+			},
+			DoMultiFn: func(multi ...Completed) *valkeyresults {
+				return &valkeyresults{s: []ValkeyResult{newErrResult(context.DeadlineExceeded)}}
+			},
+		},
+	}, &ClientOption{CircuitBreaker: &CircuitBreakerOption{
+		MinRequests: 4,
+		ErrorRate:   0.5,
+		OpenTimeout: 10 * time.Millisecond,
+		OnStateChange: func(addr string, from, to CircuitState) {
+			changes <- [2]CircuitState{from, to}
+		},
+	}})
+	defer checkClean(t)
+	defer m.Close()
+
+	expectChange := func(from, to CircuitState) {
+		t.Helper()
+		if c := <-changes; c != [2]CircuitState{from, to} {
+			t.Fatalf("unexpected state change %v -> %v", c[0], c[1])
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+	fail.Store(true)
+	if err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if m.Circuit() != CircuitClosed { // 1 failure out of 4 requests
+		t.Fatalf("unexpected state %v", m.Circuit())
+	}
+	if err := m.DoMulti(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).s[0].Error(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	expectChange(CircuitClosed, CircuitOpen)
+	if s := m.Stats(); s.Circuit != CircuitOpen {
+		t.Fatalf("unexpected stats %v", s)
+	}
+
+	if err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != ErrCircuitOpen {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := m.DoMulti(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).s[0].Error(); err != ErrCircuitOpen {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := m.DoCache(context.Background(), Cacheable(cmds.NewCompleted([]string{"GET", "a"})), time.Second).Error(); err != ErrCircuitOpen {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := m.DoMultiCache(context.Background(), CT(Cacheable(cmds.NewCompleted([]string{"GET", "a"})), time.Second)).s[0].Error(); err != ErrCircuitOpen {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if s := m.DoStream(context.Background(), cmds.NewCompleted([]string{"GET", "a"})); s.Error() != ErrCircuitOpen {
+		t.Fatalf("unexpected err %v", s.Error())
+	}
+
+	probeFail.Store(true)
+	time.Sleep(20 * time.Millisecond)
+	if err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != ErrCircuitOpen {
+		t.Fatalf("unexpected err %v", err)
+	}
+	expectChange(CircuitOpen, CircuitHalfOpen)
+	expectChange(CircuitHalfOpen, CircuitOpen)
+
+	probeFail.Store(false)
+	fail.Store(false)
+	time.Sleep(20 * time.Millisecond)
+	if err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != ErrCircuitOpen {
+		t.Fatalf("unexpected err %v", err)
+	}
+	expectChange(CircuitOpen, CircuitHalfOpen)
+	expectChange(CircuitHalfOpen, CircuitClosed)
+	if err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestMuxAdaptiveMultiplex(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var inflight atomic.Int64
+	var latency atomic.Int64
+	retired := make(chan struct{})
+	stats := func() PipelineStats {
+		return PipelineStats{Inflight: int(inflight.Load()), WriteLatency: time.Duration(latency.Load())}
+	}
+	m, checkClean := setupMuxWithOption([]*mockWire{
+		{StatsFn: stats},
+		{StatsFn: stats, CloseCtxFn: func(ctx context.Context) error {
+			close(retired)
+			return nil
+		}},
+	}, &ClientOption{AdaptivePipelineMultiplex: &AdaptiveMultiplexOption{
+		Interval:             time.Hour,
+		QueueDepth:           10,
+		WriteLatency:         time.Millisecond,
+		IdleIntervals:        2,
+		MaxPipelineMultiplex: 1,
+	}})
+	defer checkClean(t)
+	defer m.Close()
+
+	if len(m.muxwires) != 3 || m.active() != 1 {
+		t.Fatalf("unexpected multiplex %v %v", len(m.muxwires), m.active())
+	}
+	if err := m.Dial(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	inflight.Store(9)
+	m.adapt.scale()
+	if m.active() != 1 {
+		t.Fatalf("unexpected active %v", m.active())
+	}
+	latency.Store(int64(time.Millisecond))
+	m.adapt.scale()
+	if m.active() != 2 {
+		t.Fatalf("unexpected active %v", m.active())
+	}
+	w1 := m.pipe(context.Background(), 1)
+	inflight.Store(20)
+	m.adapt.scale() // already at the maximum
+	if m.active() != 2 {
+		t.Fatalf("unexpected active %v", m.active())
+	}
+
+	inflight.Store(2)
+	latency.Store(0)
+	m.adapt.scale()
+	if m.active() != 2 {
+		t.Fatalf("unexpected active %v", m.active())
+	}
+	m.adapt.scale()
+	if m.active() != 1 {
+		t.Fatalf("unexpected active %v", m.active())
+	}
+	<-retired
+	if w := m.muxwires[1].wire.Load(); w != m.init || w1 == m.init {
+		t.Fatalf("unexpected wire %v", w)
+	}
+	if s := m.Stats(); len(s.Pipelines) != 1 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+	if w := m.pipe(context.Background(), 1); w != m.pipe(context.Background(), 0) || m.muxwires[1].wire.Load() != m.init {
+		t.Fatalf("the retired muxwire should be redirected to an active one instead of being dialed again %v", w)
+	}
+	if m.muxwires[1].dialed.Load() || m.Stats().Reconnects != 0 {
+		t.Fatalf("the retired muxwire should not be counted as a reconnect")
+	}
+}
+
 func BenchmarkClientSideCaching(b *testing.B) {
 	setup := func(b *testing.B) *mux {
 		c := makeMux("127.0.0.1:6379", &ClientOption{CacheSizeEachConn: DefaultCacheBytes}, func(_ context.Context, dst string, opt *ClientOption) (conn net.Conn, err error) {
@@ -1318,8 +1853,11 @@ type mockWire struct {
 	VersionFn       func() int
 	ErrorFn         func() error
 	CloseFn         func()
+	CloseCtxFn      func(ctx context.Context) error
 	StopTimerFn     func() bool
 	ResetTimerFn    func() bool
+	StatsFn         func() PipelineStats
+	RTTFn           func() time.Duration
 
 	CleanSubscriptionsFn func()
 	SetPubSubHooksFn     func(hooks PubSubHooks) <-chan error
@@ -1355,6 +1893,17 @@ func (m *mockWire) DoMulti(ctx context.Context, multi ...Completed) *valkeyresul
 	return nil
 }
 
+func (m *mockWire) DoAsync(ctx context.Context, cmd Completed, fn func(ValkeyResult)) {
+	fn(m.Do(ctx, cmd))
+}
+
+func (m *mockWire) DoMultiAsync(ctx context.Context, resps []ValkeyResult, fn func(), multi ...Completed) {
+	if results := m.DoMulti(ctx, multi...); results != nil {
+		copy(resps, results.s)
+	}
+	fn()
+}
+
 func (m *mockWire) Receive(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
 	if m.ReceiveFn != nil {
 		return m.ReceiveFn(ctx, subscribe, fn)
@@ -1416,6 +1965,20 @@ func (m *mockWire) ResetTimer() bool {
 	return true
 }
 
+func (m *mockWire) Stats() PipelineStats {
+	if m.StatsFn != nil {
+		return m.StatsFn()
+	}
+	return PipelineStats{}
+}
+
+func (m *mockWire) RTT() time.Duration {
+	if m.RTTFn != nil {
+		return m.RTTFn()
+	}
+	return 0
+}
+
 func (m *mockWire) Info() map[string]ValkeyMessage {
 	if m.InfoFn != nil {
 		return m.InfoFn()
@@ -1455,3 +2018,14 @@ func (m *mockWire) Close() {
 		m.CloseFn()
 	}
 }
+
+func (m *mockWire) CloseWithContext(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	if m.CloseCtxFn != nil {
+		return m.CloseCtxFn(ctx)
+	}
+	m.Close()
+	return nil
+}
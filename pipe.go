@@ -7,10 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net"
 	"os"
 	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -46,6 +48,8 @@ type wire interface {
 	DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) ValkeyResult
 	DoMulti(ctx context.Context, multi ...Completed) *valkeyresults
 	DoMultiCache(ctx context.Context, multi ...CacheableTTL) *valkeyresults
+	DoAsync(ctx context.Context, cmd Completed, fn func(ValkeyResult))
+	DoMultiAsync(ctx context.Context, resps []ValkeyResult, fn func(), multi ...Completed)
 	Receive(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error
 	DoStream(ctx context.Context, pool *pool, cmd Completed) ValkeyResultStream
 	DoMultiStream(ctx context.Context, pool *pool, multi ...Completed) MultiValkeyResultStream
@@ -54,6 +58,7 @@ type wire interface {
 	AZ() string
 	Error() error
 	Close()
+	CloseWithContext(ctx context.Context) error
 
 	CleanSubscriptions()
 	SetPubSubHooks(hooks PubSubHooks) <-chan error
@@ -61,6 +66,8 @@ type wire interface {
 	SetOnCloseHook(fn func(error))
 	StopTimer() bool
 	ResetTimer() bool
+	Stats() PipelineStats
+	RTT() time.Duration
 }
 
 var _ wire = (*pipe)(nil)
@@ -76,10 +83,12 @@ type pipe struct {
 	w               *bufio.Writer
 	close           chan struct{}
 	onInvalidations func([]ValkeyMessage)
+	bcast           *BroadcastTracking
 	ssubs           *subs // pubsub smessage subscriptions
 	nsubs           *subs // pubsub  message subscriptions
 	psubs           *subs // pubsub pmessage subscriptions
 	r2p             *r2p
+	r2inv           *r2inv      // the pipe receiving the invalidations redirected from this pipe over RESP2
 	pingTimer       *time.Timer // timer for background ping
 	lftmTimer       *time.Timer // lifetime timer
 	info            map[string]ValkeyMessage
@@ -88,6 +97,9 @@ type pipe struct {
 	maxFlushDelay   time.Duration
 	lftm            time.Duration // lifetime
 	wrCounter       atomic.Uint64
+	wlat            atomic.Int64 // the duration of the last flush
+	rtt             atomic.Int64 // the EWMA of round-trip times
+	r2id            int64        // the client id of the r2inv
 	version         int32
 	blcksig         int32
 	state           int32
@@ -100,14 +112,16 @@ type pipe struct {
 type pipeFn func(ctx context.Context, connFn func(ctx context.Context) (net.Conn, error), option *ClientOption) (p *pipe, err error)
 
 func newPipe(ctx context.Context, connFn func(ctx context.Context) (net.Conn, error), option *ClientOption) (p *pipe, err error) {
-	return _newPipe(ctx, connFn, option, false, false)
+	return _newPipe(ctx, connFn, option, false, false, nil)
 }
 
 func newPipeNoBg(ctx context.Context, connFn func(context.Context) (net.Conn, error), option *ClientOption) (p *pipe, err error) {
-	return _newPipe(ctx, connFn, option, false, true)
+	return _newPipe(ctx, connFn, option, false, true, nil)
 }
 
-func _newPipe(ctx context.Context, connFn func(context.Context) (net.Conn, error), option *ClientOption, r2ps, nobg bool) (p *pipe, err error) {
+// _newPipe creates a pipe. Over RESP2, the invalidations of its client-side cache are redirected to the inv,
+// or to its own r2inv if the inv is nil.
+func _newPipe(ctx context.Context, connFn func(context.Context) (net.Conn, error), option *ClientOption, r2ps, nobg bool, inv *r2inv) (p *pipe, err error) {
 	conn, err := connFn(ctx)
 	if err != nil {
 		return nil, err
@@ -123,12 +137,18 @@ func _newPipe(ctx context.Context, connFn func(context.Context) (net.Conn, error
 		noNoDelay:     option.DisableTCPNoDelay,
 
 		r2ps:  r2ps,
-		optIn: isOptIn(option.ClientTrackingOptions),
+		optIn: isOptIn(option),
 	}
 	if !nobg {
-		switch queueTypeFromEnv {
-		case queueTypeFlowBuffer:
+		queueType := option.QueueType
+		if queueType == "" {
+			queueType = queueTypeFromEnv
+		}
+		switch queueType {
+		case QueueTypeFlowBuffer:
 			p.queue = newFlowBuffer(option.RingScaleEachConn)
+		case QueueTypeMPSC:
+			p.queue = newMPSC(option.RingScaleEachConn)
 		default:
 			p.queue = newRing(option.RingScaleEachConn)
 		}
@@ -137,12 +157,24 @@ func _newPipe(ctx context.Context, connFn func(context.Context) (net.Conn, error
 		p.ssubs = newSubs()
 		p.close = make(chan struct{})
 	}
-	if !nobg && !option.DisableCache {
+	if !nobg && !r2ps && !option.DisableCache {
 		cacheStoreFn := option.NewCacheStoreFn
 		if cacheStoreFn == nil {
 			cacheStoreFn = newLRU
 		}
-		p.cache = cacheStoreFn(CacheStoreOption{CacheSizeEachConn: option.CacheSizeEachConn})
+		cacheStoreOpt := CacheStoreOption{CacheSizeEachConn: option.CacheSizeEachConn, node: strconv.Itoa(option.SelectDB)}
+		if addr := conn.RemoteAddr(); addr != nil {
+			cacheStoreOpt.node = addr.String() + "/" + cacheStoreOpt.node
+		}
+		if option.NewCachePolicyFn != nil {
+			cacheStoreOpt.CachePolicy = option.NewCachePolicyFn()
+		}
+		p.cache = cacheStoreFn(cacheStoreOpt)
+		p.bcast = option.Broadcast
+	}
+	var prefixes []string
+	if p.bcast != nil {
+		prefixes = p.bcast.load()
 	}
 	p.pshks.Store(emptypshks)
 	p.clhks.Store(emptyclhks)
@@ -180,12 +212,14 @@ func _newPipe(ctx context.Context, connFn func(context.Context) (net.Conn, error
 		init = append(init, []string{"INFO", "SERVER"})
 	}
 
-	if !option.DisableCache {
+	if !option.DisableCache && option.Broadcast == nil {
 		if option.ClientTrackingOptions == nil {
 			init = append(init, []string{"CLIENT", "TRACKING", "ON", "OPTIN"})
 		} else {
 			init = append(init, append([]string{"CLIENT", "TRACKING", "ON"}, option.ClientTrackingOptions...))
 		}
+	} else if p.bcast != nil && len(prefixes) != 0 {
+		init = append(init, p.bcast.trackingCmd(prefixes, 0))
 	}
 
 	if option.SelectDB != 0 {
@@ -281,10 +315,6 @@ func _newPipe(ctx context.Context, connFn func(context.Context) (net.Conn, error
 		}
 		p.onInvalidations = option.OnInvalidations
 	} else {
-		if !option.DisableCache {
-			p.Close()
-			return nil, ErrNoCache
-		}
 		init = init[:0]
 		if password != "" && username == "" {
 			init = append(init, []string{"AUTH", password})
@@ -363,16 +393,31 @@ func _newPipe(ctx context.Context, connFn func(context.Context) (net.Conn, error
 		if !r2ps {
 			p.r2p = &r2p{
 				f: func(ctx context.Context) (p *pipe, err error) {
-					return _newPipe(ctx, connFn, option, true, nobg)
+					return _newPipe(ctx, connFn, option, true, nobg, nil)
 				},
 			}
 		}
+		if p.cache != nil {
+			if inv == nil {
+				inv = newR2Inv(connFn, option)
+			}
+			if err = p.redirectTracking(ctx, inv, option, prefixes); err != nil {
+				p.Close()
+				return nil, err
+			}
+		}
+	}
+	if p.bcast != nil {
+		if err = p.bcast.register(ctx, p, prefixes); err != nil {
+			p.Close()
+			return nil, err
+		}
 	}
 	if !nobg {
 		if p.timeout > 0 && p.pinggap > 0 {
 			p.backgroundPing()
 		}
-		if p.onInvalidations != nil || option.AlwaysPipelining {
+		if p.onInvalidations != nil || p.r2inv != nil || option.AlwaysPipelining {
 			p.background()
 		}
 	}
@@ -383,6 +428,37 @@ func _newPipe(ctx context.Context, connFn func(context.Context) (net.Conn, error
 	return p, nil
 }
 
+// redirectTracking enables the client-side caching over RESP2, which can't deliver invalidations along with replies,
+// by redirecting the invalidations of the pipe to the r2inv subscribing to the __redis__:invalidate channel.
+// The pipe is closed once the r2inv is broken, because its cache can't be invalidated anymore.
+func (p *pipe) redirectTracking(ctx context.Context, inv *r2inv, option *ClientOption, prefixes []string) error {
+	id, err := inv.attach(ctx, p)
+	if err == nil {
+		tracking := []string{"CLIENT", "TRACKING", "ON", "REDIRECT", strconv.FormatInt(id, 10)}
+		if p.bcast != nil {
+			tracking = p.bcast.trackingCmd(prefixes, id)
+		} else if option.ClientTrackingOptions == nil {
+			tracking = append(tracking, "OPTIN")
+		} else {
+			tracking = append(tracking, option.ClientTrackingOptions...)
+		}
+		if tracking != nil { // tracking is nil if there is no prefix to be broadcast
+			if err = p.Do(ctx, cmds.NewCompleted(tracking)).Error(); err != nil {
+				if re, ok := err.(*ValkeyError); ok {
+					err = fmt.Errorf("%s: %v\n%w", re.string(), tracking, ErrNoCache)
+				}
+			}
+		}
+	}
+	if err != nil {
+		inv.detach(p)
+		return err
+	}
+	p.r2inv = inv
+	p.r2id = id
+	return nil
+}
+
 func (p *pipe) background() {
 	if p.queue != nil {
 		atomic.CompareAndSwapInt32(&p.state, 0, 1)
@@ -453,6 +529,9 @@ func (p *pipe) _background() {
 	if p.cache != nil {
 		p.cache.Close(ErrDoCacheAborted)
 	}
+	if p.r2inv != nil {
+		p.r2inv.detach(p)
+	}
 	if p.onInvalidations != nil {
 		p.onInvalidations(nil)
 	}
@@ -498,9 +577,11 @@ func (p *pipe) _backgroundWrite() (err error) {
 				flushStart = time.Now()
 			}
 			if p.w.Buffered() != 0 {
+				start := time.Now()
 				if err = p.w.Flush(); err != nil {
 					break
 				}
+				p.wlat.Store(int64(time.Since(start)))
 			}
 			ones[0], multi, ch = p.queue.WaitForWrite()
 			if flushDelay != 0 && p.loadWaits() > 1 { // do not delay for sequential usage
@@ -745,6 +826,30 @@ func (p *pipe) backgroundPing() {
 	})
 }
 
+func (p *pipe) invalidate(keys ValkeyMessage) {
+	if p.cache != nil {
+		if keys.IsNil() {
+			p.cache.Delete(nil)
+		} else {
+			p.cache.Delete(keys.values())
+		}
+	}
+	if p.onInvalidations != nil {
+		if keys.IsNil() {
+			p.onInvalidations(nil)
+		} else {
+			p.onInvalidations(keys.values())
+		}
+	}
+	if fn := p.pshks.Load().hooks.onInvalidations; fn != nil {
+		if keys.IsNil() {
+			fn(nil)
+		} else {
+			fn(keys.values())
+		}
+	}
+}
+
 func (p *pipe) handlePush(values []ValkeyMessage) (reply bool, unsubscribe bool) {
 	if len(values) < 2 {
 		return
@@ -754,29 +859,11 @@ func (p *pipe) handlePush(values []ValkeyMessage) (reply bool, unsubscribe bool)
 	// server-cpu-usage
 	switch values[0].string() {
 	case "invalidate":
-		if p.cache != nil {
-			if values[1].IsNil() {
-				p.cache.Delete(nil)
-			} else {
-				p.cache.Delete(values[1].values())
-			}
-		}
-		if p.onInvalidations != nil {
-			if values[1].IsNil() {
-				p.onInvalidations(nil)
-			} else {
-				p.onInvalidations(values[1].values())
-			}
-		}
-		if fn := p.pshks.Load().hooks.onInvalidations; fn != nil {
-			if values[1].IsNil() {
-				fn(nil)
-			} else {
-				fn(values[1].values())
-			}
-		}
+		p.invalidate(values[1])
 	case "message":
-		if len(values) >= 3 {
+		if p.r2ps && len(values) >= 3 && values[1].string() == invalidateChannel {
+			p.invalidate(values[2]) // the invalidations redirected from another pipe over RESP2
+		} else if len(values) >= 3 {
 			m := PubSubMessage{Channel: values[1].string(), Message: values[2].string()}
 			p.nsubs.Publish(values[1].string(), m)
 			if fn := p.pshks.Load().hooks.OnMessage; fn != nil {
@@ -1031,6 +1118,9 @@ func (p *pipe) Do(ctx context.Context, cmd Completed) (resp ValkeyResult) {
 				atomic.AddInt32(&p.blcksig, -1)
 			}
 		}()
+	} else if !cmd.NoReply() {
+		start := time.Now()
+		defer func() { p.observeRTT(start, resp.NonValkeyError()) }()
 	}
 
 	if cmd.NoReply() {
@@ -1126,6 +1216,7 @@ func (p *pipe) DoMulti(ctx context.Context, multi ...Completed) *valkeyresults {
 		}
 	}
 
+	blocked := false
 	for _, cmd := range multi {
 		if cmd.IsBlock() {
 			if noReply != 0 {
@@ -1143,9 +1234,14 @@ func (p *pipe) DoMulti(ctx context.Context, multi ...Completed) *valkeyresults {
 				}
 				atomic.AddInt32(&p.blcksig, -1)
 			}()
+			blocked = true
 			break
 		}
 	}
+	if !blocked && noReply == 0 {
+		start := time.Now()
+		defer func() { p.observeRTT(start, resp.s[0].NonValkeyError()) }()
+	}
 
 	waits := p.incrWaits() // if this is 1, and the background worker is not started, no need to queue
 	state := atomic.LoadInt32(&p.state)
@@ -1215,6 +1311,79 @@ abort:
 	return resp
 }
 
+// DoAsync queues the cmd like Do, but it passes the result to the fn instead of waiting for it.
+// The fn is called by the goroutine reading responses from the connection, so it must not block.
+// Blocking and pubsub commands are not pipelined asynchronously and fall back to Do in a new goroutine.
+func (p *pipe) DoAsync(ctx context.Context, cmd Completed, fn func(ValkeyResult)) {
+	if err := ctx.Err(); err != nil {
+		fn(newErrResult(err))
+		return
+	}
+	if p.queue == nil || cmd.IsBlock() || cmd.NoReply() {
+		go func() { fn(p.Do(ctx, cmd)) }()
+		return
+	}
+	cmds.CompletedCS(cmd).Verify()
+	if err := p.enqueueAsync(ctx, cmd, nil, nil, fn); err != nil {
+		fn(newErrResult(err))
+	}
+}
+
+// DoMultiAsync queues the multi like DoMulti, but it fills the resps and calls the fn instead of waiting for them.
+// The fn is called by the goroutine reading responses from the connection, so it must not block.
+// Blocking, pubsub and opt-in commands are not pipelined asynchronously and fall back to DoMulti in a new goroutine.
+func (p *pipe) DoMultiAsync(ctx context.Context, resps []ValkeyResult, fn func(), multi ...Completed) {
+	if err := ctx.Err(); err != nil {
+		fillResps(resps, err)
+		fn()
+		return
+	}
+	fallback := p.queue == nil || multi[0].IsOptIn()
+	for i := 0; i < len(multi) && !fallback; i++ {
+		fallback = multi[i].IsBlock() || multi[i].NoReply()
+	}
+	if fallback {
+		go func() {
+			results := p.DoMulti(ctx, multi...)
+			copy(resps, results.s)
+			resultsp.Put(results)
+			fn()
+		}()
+		return
+	}
+	cmds.CompletedCS(multi[0]).Verify()
+	if err := p.enqueueAsync(ctx, Completed{}, multi, resps, func(ValkeyResult) { fn() }); err != nil {
+		fillResps(resps, err)
+		fn()
+	}
+}
+
+func (p *pipe) enqueueAsync(ctx context.Context, one Completed, multi []Completed, resps []ValkeyResult, fn func(ValkeyResult)) error {
+	waits := p.incrWaits()
+	switch state := atomic.LoadInt32(&p.state); {
+	case state == 0 && waits == 1:
+		p.background() // otherwise, the background worker will be started by the one who is doing syncDo.
+	case state > 1:
+		p.decrWaitsAndIncrRecvs()
+		return p.Error()
+	}
+	err := p.queue.PutAsync(ctx, one, multi, resps, func(resp ValkeyResult) {
+		p.decrWaitsAndIncrRecvs()
+		fn(resp)
+	})
+	if err != nil {
+		p.decrWaits()
+	}
+	return err
+}
+
+func fillResps(resps []ValkeyResult, err error) {
+	errResult := newErrResult(err)
+	for i := range resps {
+		resps[i] = errResult
+	}
+}
+
 type MultiValkeyResultStream = ValkeyResultStream
 
 type ValkeyResultStream struct {
@@ -1241,24 +1410,52 @@ func (s *ValkeyResultStream) Error() error {
 func (s *ValkeyResultStream) WriteTo(w io.Writer) (n int64, err error) {
 	if err = s.e; err == nil && s.n > 0 {
 		var clean bool
-		if n, err, clean = streamTo(s.w.r, w); !clean {
-			s.e = err // err must not be nil in case of !clean
-			s.n = 1
-		}
-		if s.n--; s.n == 0 {
-			atomic.AddInt32(&s.w.blcksig, -1)
-			s.w.decrWaits()
-			if s.e == nil {
-				s.e = io.EOF
-			} else {
-				s.w.Close()
-			}
-			s.p.Store(s.w)
-		}
+		n, err, clean = streamTo(s.w.r, w)
+		s.advance(err, clean)
 	}
 	return n, err
 }
 
+// Elements reads a valkey array, set or map response from valkey and yields its elements one by one
+// without holding the whole response in memory. Keys and values of a map are yielded alternately.
+// Like the WriteTo, it should be called sequentially to read multiple responses, and an io.EOF error will be yielded if all responses are read.
+// Stopping the iteration early closes the underlying connection and discards the rest of the responses.
+func (s *ValkeyResultStream) Elements() iter.Seq2[ValkeyMessage, error] {
+	return func(yield func(ValkeyMessage, error) bool) {
+		if s.e != nil {
+			yield(ValkeyMessage{}, s.e)
+			return
+		}
+		if s.n <= 0 {
+			return
+		}
+		err, clean := streamElems(s.w.r, func(m ValkeyMessage) bool {
+			return yield(m, nil)
+		})
+		s.advance(err, clean)
+		if err != nil && err != errStreamStopped {
+			yield(ValkeyMessage{}, err)
+		}
+	}
+}
+
+func (s *ValkeyResultStream) advance(err error, clean bool) {
+	if !clean {
+		s.e = err // err must not be nil in case of !clean
+		s.n = 1
+	}
+	if s.n--; s.n == 0 {
+		atomic.AddInt32(&s.w.blcksig, -1)
+		s.w.decrWaits()
+		if s.e == nil {
+			s.e = io.EOF
+		} else {
+			s.w.Close()
+		}
+		s.p.Store(s.w)
+	}
+}
+
 func (p *pipe) DoStream(ctx context.Context, pool *pool, cmd Completed) ValkeyResultStream {
 	cmds.CompletedCS(cmd).Verify()
 
@@ -1484,16 +1681,28 @@ func (p *pipe) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) Va
 
 	cmds.CacheableCS(cmd).Verify()
 
+	if p.uncached(cmd) {
+		return p.Do(ctx, Completed(cmd))
+	}
 	if cmd.IsMGet() {
 		return p.doCacheMGet(ctx, cmd, ttl)
 	}
 	ck, cc := cmds.CacheKey(cmd)
-	now := time.Now()
-	if v, entry := p.cache.Flight(ck, cc, ttl, now); v.typ != 0 {
+	if o, ok := ctx.Value(staleCtxKey{}).(CacheStaleOption); ok {
+		if cache, ok := p.cache.(lruStore); ok {
+			return p.doCacheStale(ctx, cache, cmd, ck, cc, ttl, o)
+		}
+	}
+	if v, entry := p.cache.Flight(ck, cc, ttl, time.Now()); v.typ != 0 {
 		return newResult(v, nil)
 	} else if entry != nil {
 		return newResult(entry.Wait(ctx))
 	}
+	return p.fetchCache(ctx, cmd, ck, cc)
+}
+
+// fetchCache fetches the cmd to resolve its pending entry made by the Flight.
+func (p *pipe) fetchCache(ctx context.Context, cmd Cacheable, ck, cc string) ValkeyResult {
 	if cmds.IsStaticTTL(Completed(cmd)) {
 		// Wire: [OPT_IN, cmd]. The read goroutine resolves the Flight
 		// slot via the IsStaticTTL gate.
@@ -1648,10 +1857,22 @@ func (p *pipe) DoMultiCache(ctx context.Context, multi ...CacheableTTL) *valkeyr
 
 	cmds.CacheableCS(multi[0].Cmd).Verify()
 
+	if p.bcast != nil && slices.ContainsFunc(multi, func(ct CacheableTTL) bool { return p.uncached(ct.Cmd) }) {
+		return p.doMultiCacheBroadcast(ctx, multi)
+	}
+	if o, ok := ctx.Value(staleCtxKey{}).(CacheStaleOption); ok {
+		if _, ok := p.cache.(lruStore); ok {
+			return p.doMultiCacheStale(ctx, multi, o)
+		}
+	}
+	return p.doMultiCache(ctx, multi, nil)
+}
+
+func (p *pipe) doMultiCache(ctx context.Context, multi []CacheableTTL, sr *staleReads) *valkeyresults {
 	results := resultsp.Get(len(multi), len(multi))
 	entries := entriesp.Get(len(multi), len(multi))
 	defer entriesp.Put(entries)
-	var missing []Completed
+	var missing, revalidating []Completed
 	now := time.Now()
 	for _, ct := range multi {
 		if ct.Cmd.IsMGet() {
@@ -1669,16 +1890,26 @@ func (p *pipe) DoMultiCache(ctx context.Context, multi ...CacheableTTL) *valkeyr
 		}
 	}
 	// stride-2 [OPT_IN, cmd] vs. stride-5 [OPT_IN, MULTI, PTTL, cmd, EXEC].
-	if cache, ok := p.cache.(*lru); ok {
-		missed := cache.Flights(now, multi, results.s, entries.e)
+	if cache, ok := p.cache.(lruStore); ok {
+		var missed []int
+		if sr != nil {
+			missed = cache.flightsStale(now, multi, results.s, entries.e, sr.window(), sr.stales)
+		} else {
+			missed = cache.Flights(now, multi, results.s, entries.e)
+		}
 		for _, i := range missed {
 			ct := multi[i]
+			target := &missing
+			if sr != nil && sr.revalidating(sr.stales[i], now) {
+				results.s[i] = newResult(sr.stales[i], nil)
+				target = &revalidating
+			}
 			if skipMultiExec {
-				missing = append(missing, p.optInCmd(), Completed(ct.Cmd))
+				*target = append(*target, p.optInCmd(), Completed(ct.Cmd))
 			} else {
 				ck, _ := cmds.CacheKey(ct.Cmd)
 				cmds.ClearStaticTTL(&ct.Cmd)
-				missing = append(missing, p.optInCmd(), cmds.MultiCmd, cmds.NewCompleted([]string{"PTTL", ck}), Completed(ct.Cmd), cmds.ExecCmd)
+				*target = append(*target, p.optInCmd(), cmds.MultiCmd, cmds.NewCompleted([]string{"PTTL", ck}), Completed(ct.Cmd), cmds.ExecCmd)
 			}
 		}
 	} else {
@@ -1702,38 +1933,27 @@ func (p *pipe) DoMultiCache(ctx context.Context, multi ...CacheableTTL) *valkeyr
 		}
 	}
 
+	if sr != nil {
+		for i := range entries.e {
+			if sr.revalidating(sr.stales[i], now) {
+				results.s[i] = newResult(sr.stales[i], nil)
+				delete(entries.e, i)
+			}
+		}
+		if len(revalidating) > 0 {
+			go func(ctx context.Context) {
+				resp := p.DoMulti(ctx, revalidating...)
+				p.cancelMissing(revalidating, resp, skipMultiExec)
+				resultsp.Put(resp)
+			}(context.WithoutCancel(ctx))
+		}
+	}
+
 	var resp *valkeyresults
 	if len(missing) > 0 {
 		resp = p.DoMulti(ctx, missing...)
 		defer resultsp.Put(resp)
-		if !skipMultiExec {
-			// EXEC-level cancel on transaction abort; EXEC reply at offset 4 of each stride-5.
-			const stride, offset = 5, 4
-			for i := offset; i < len(resp.s); i += stride {
-				if err := resp.s[i].Error(); err != nil {
-					if _, ok := err.(*ValkeyError); ok {
-						err = ErrDoCacheAborted
-						if preErr := resp.s[i-1].Error(); preErr != nil { // if {cmd} get a ValkeyError
-							if _, ok := preErr.(*ValkeyError); ok {
-								err = preErr
-							}
-						}
-					}
-					ck, cc := cmds.CacheKey(Cacheable(missing[i-1]))
-					p.cache.Cancel(ck, cc, err)
-				}
-			}
-		} else {
-			// Static-TTL path: wire replies are resolved by the read
-			// loop, so only Cancel transport-error slots here.
-			const stride, offset = 2, 1
-			for i := offset; i < len(resp.s); i += stride {
-				if resp.s[i].err != nil {
-					ck, cc := cmds.CacheKey(Cacheable(missing[i]))
-					p.cache.Cancel(ck, cc, resp.s[i].err)
-				}
-			}
-		}
+		p.cancelMissing(missing, resp, skipMultiExec)
 	}
 
 	for i, entry := range entries.e {
@@ -1785,6 +2005,38 @@ func (p *pipe) DoMultiCache(ctx context.Context, multi ...CacheableTTL) *valkeyr
 	return results
 }
 
+// cancelMissing cancels the pending entries of the missing commands failed to be fetched by the DoMultiCache.
+func (p *pipe) cancelMissing(missing []Completed, resp *valkeyresults, skipMultiExec bool) {
+	if !skipMultiExec {
+		// EXEC-level cancel on transaction abort; EXEC reply at offset 4 of each stride-5.
+		const stride, offset = 5, 4
+		for i := offset; i < len(resp.s); i += stride {
+			if err := resp.s[i].Error(); err != nil {
+				if _, ok := err.(*ValkeyError); ok {
+					err = ErrDoCacheAborted
+					if preErr := resp.s[i-1].Error(); preErr != nil { // if {cmd} get a ValkeyError
+						if _, ok := preErr.(*ValkeyError); ok {
+							err = preErr
+						}
+					}
+				}
+				ck, cc := cmds.CacheKey(Cacheable(missing[i-1]))
+				p.cache.Cancel(ck, cc, err)
+			}
+		}
+	} else {
+		// Static-TTL path: wire replies are resolved by the read
+		// loop, so only Cancel transport-error slots here.
+		const stride, offset = 2, 1
+		for i := offset; i < len(resp.s); i += stride {
+			if resp.s[i].err != nil {
+				ck, cc := cmds.CacheKey(Cacheable(missing[i]))
+				p.cache.Cancel(ck, cc, resp.s[i].err)
+			}
+		}
+	}
+}
+
 // incrWaits increments the lower 32 bits (waits).
 func (p *pipe) incrWaits() uint32 {
 	// Increment the lower 32 bits (waits)
@@ -1820,6 +2072,38 @@ func (p *pipe) loadWaits() uint32 {
 	return uint32(p.wrCounter.Load())
 }
 
+func (p *pipe) Stats() (s PipelineStats) {
+	s.Inflight = int(p.loadWaits())
+	s.WriteLatency = time.Duration(p.wlat.Load())
+	s.RTT = p.RTT()
+	if c, ok := p.cache.(lruStore); ok {
+		c.stats(&s)
+	}
+	return s
+}
+
+// rttDecay is the inverse weight of a new sample in the EWMA of round-trip times.
+const rttDecay = 5
+
+// RTT returns the EWMA of round-trip times of commands, including the background PING, sent over the pipe.
+// Only the Do and DoMulti are measured, excluding blocking and no-reply commands. It is 0 if nothing has been measured yet.
+func (p *pipe) RTT() time.Duration {
+	return time.Duration(p.rtt.Load())
+}
+
+// observeRTT folds the duration since the start into the EWMA of round-trip times unless the command failed.
+// Concurrent updates may overwrite each other, which is fine for a moving average.
+func (p *pipe) observeRTT(start time.Time, err error) {
+	if err != nil {
+		return
+	}
+	sample := int64(time.Since(start))
+	if prev := p.rtt.Load(); prev != 0 {
+		sample = prev + (sample-prev)/rttDecay
+	}
+	p.rtt.Store(sample)
+}
+
 func (p *pipe) Error() error {
 	if err := p.error.Load(); err != nil {
 		return err.error
@@ -1828,6 +2112,19 @@ func (p *pipe) Error() error {
 }
 
 func (p *pipe) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	p.shutdown(ctx, false)
+	cancel()
+}
+
+// CloseWithContext stops accepting new commands, unsubscribes all active subscriptions,
+// and waits for in-flight commands to be fulfilled before closing the connection.
+// The connection is closed immediately once the ctx is done, and the ctx.Err() is returned.
+func (p *pipe) CloseWithContext(ctx context.Context) error {
+	return p.shutdown(ctx, true)
+}
+
+func (p *pipe) shutdown(ctx context.Context, unsub bool) (err error) {
 	p.error.CompareAndSwap(nil, errClosing)
 	block := atomic.AddInt32(&p.blcksig, 1)
 	waits := p.incrWaits()
@@ -1839,11 +2136,18 @@ func (p *pipe) Close() {
 		}
 		if block == 1 && (stopping1 || stopping2) { // make sure there is no block cmd
 			p.incrWaits()
-			ch, _ := p.queue.PutOne(context.Background(), cmds.PingCmd)
+			var ch chan ValkeyResult
+			if unsub && p.subscribed() { // the last UNSUBSCRIBE is followed by a PING, which also drains the queue.
+				multi := p.unsubCmds()
+				ch, _ = p.queue.PutMulti(context.Background(), multi, make([]ValkeyResult, len(multi)))
+			} else {
+				ch, _ = p.queue.PutOne(context.Background(), cmds.PingCmd)
+			}
 			select {
 			case <-ch:
 				p.decrWaits()
-			case <-time.After(time.Second):
+			case <-ctx.Done():
+				err = ctx.Err()
 				go func(ch chan ValkeyResult) {
 					<-ch
 					p.decrWaits()
@@ -1860,8 +2164,22 @@ func (p *pipe) Close() {
 		p.conn.Close()
 	}
 	if p.r2p != nil {
-		p.r2p.Close()
+		if e := p.r2p.shutdown(ctx, unsub); err == nil {
+			err = e
+		}
 	}
+	return err
+}
+
+func (p *pipe) subscribed() bool {
+	return p.nsubs.Len() != 0 || p.psubs.Len() != 0 || p.ssubs.Len() != 0 || p.pshks.Load() != emptypshks
+}
+
+func (p *pipe) unsubCmds() []Completed {
+	if p.version >= 7 {
+		return []Completed{cmds.UnsubscribeCmd, cmds.PUnsubscribeCmd, cmds.SUnsubscribeCmd}
+	}
+	return []Completed{cmds.UnsubscribeCmd, cmds.PUnsubscribeCmd}
 }
 
 func (p *pipe) StopTimer() bool {
@@ -1918,6 +2236,131 @@ func (r *r2p) Close() {
 	r.m.RUnlock()
 }
 
+func (r *r2p) shutdown(ctx context.Context, unsub bool) (err error) {
+	r.m.RLock()
+	if r.p != nil {
+		err = r.p.shutdown(ctx, unsub)
+	}
+	r.m.RUnlock()
+	return err
+}
+
+// r2inv is the pipe receiving the invalidations redirected from the pipes of a node over RESP2.
+// It is shared by the pipes and closed once all of them are detached.
+type r2inv struct {
+	f     func(context.Context) (*pipe, error) // func to build the pipe subscribing to the __redis__:invalidate
+	fn    func([]ValkeyMessage)                // the ClientOption.OnInvalidations
+	p     *pipe
+	pipes map[*pipe]*pipe // the attached pipes and the r2inv pipes they redirect to
+	id    int64           // the client id of the p
+	mu    sync.Mutex
+}
+
+func newR2Inv(connFn func(context.Context) (net.Conn, error), option *ClientOption) *r2inv {
+	return &r2inv{
+		f: func(ctx context.Context) (*pipe, error) {
+			return _newPipe(ctx, connFn, option, true, false, nil)
+		},
+		fn:    option.OnInvalidations,
+		pipes: make(map[*pipe]*pipe),
+	}
+}
+
+// attach makes the r2inv deliver invalidations to the cache of the p and returns the client id for the CLIENT TRACKING REDIRECT.
+// The r2inv pipe is established if there is no healthy one. The p is closed once the r2inv pipe is broken.
+func (r *r2inv) attach(ctx context.Context, p *pipe) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.p == nil || r.p.Error() != nil {
+		inv, err := r.f(ctx)
+		if err != nil {
+			return 0, err
+		}
+		inv.onInvalidations = func(keys []ValkeyMessage) { r.invalidate(inv, keys) }
+		id, err := inv.Do(ctx, cmds.ClientIDCmd).AsInt64()
+		if err == nil {
+			err = inv.Do(ctx, cmds.InvalidateSubscribe).Error()
+		}
+		if err == nil {
+			inv.SetOnCloseHook(func(err error) { r.broken(inv, err) })
+			err = inv.Error() // the hook is missed if it was broken before
+		}
+		if err != nil {
+			inv.Close()
+			return 0, err
+		}
+		r.p, r.id = inv, id
+	}
+	r.pipes[p] = r.p
+	return r.id, nil
+}
+
+// detach stops delivering invalidations to the p. The r2inv pipe is closed if no pipe is attached to it.
+func (r *r2inv) detach(p *pipe) {
+	r.mu.Lock()
+	inv, ok := r.pipes[p]
+	delete(r.pipes, p)
+	for _, i := range r.pipes {
+		if i == inv {
+			ok = false
+			break
+		}
+	}
+	if ok && r.p == inv {
+		r.p = nil
+	}
+	r.mu.Unlock()
+	if ok {
+		inv.Close()
+	}
+}
+
+// invalidate delivers the invalidations received by the inv to the caches of the pipes attached to it.
+// Pending entries are invalidated too, because an invalidation can arrive before the reply of the pipe.
+func (r *r2inv) invalidate(inv *pipe, keys []ValkeyMessage) {
+	r.mu.Lock()
+	for p, i := range r.pipes {
+		if i == inv {
+			p.invalidateRedirected(keys)
+		}
+	}
+	r.mu.Unlock()
+	if r.fn != nil {
+		r.fn(keys)
+	}
+}
+
+// broken closes the pipes attached to the broken inv.
+func (r *r2inv) broken(inv *pipe, err error) {
+	var pipes []*pipe
+	r.mu.Lock()
+	for p, i := range r.pipes {
+		if i == inv {
+			pipes = append(pipes, p)
+			delete(r.pipes, p)
+		}
+	}
+	if r.p == inv {
+		r.p = nil
+	}
+	r.mu.Unlock()
+	for _, p := range pipes {
+		if p.Error() == nil {
+			p._exit(err)
+		}
+		p.invalidateRedirected(nil) // flush the cache before the p is cleaned up asynchronously
+	}
+}
+
+// invalidateRedirected deletes the keys from the cache of the p, including pending entries if the cache supports. See cacheEntry.invalid.
+func (p *pipe) invalidateRedirected(keys []ValkeyMessage) {
+	if c, ok := p.cache.(lruStore); ok {
+		c.invalidate(keys)
+	} else if p.cache != nil {
+		p.cache.Delete(keys)
+	}
+}
+
 type pshks struct {
 	hooks PubSubHooks
 	close chan error
@@ -1951,6 +2394,9 @@ const (
 )
 
 var cacheMark = &(ValkeyMessage{})
+
+const invalidateChannel = "__redis__:invalidate"
+
 var (
 	errClosing = &errs{error: ErrClosing}
 	errExpired = &errs{error: errConnExpired}
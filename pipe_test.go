@@ -10,6 +10,7 @@ import (
 	"net"
 	"os"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -165,7 +166,12 @@ func setup(t *testing.T, option ClientOption) (*pipe, *valkeyMock, func(), func(
 					{typ: ':', intlen: 3},
 				},
 			))
-		if option.ClientTrackingOptions != nil {
+		if option.Broadcast != nil {
+			if prefixes := option.Broadcast.load(); len(prefixes) != 0 {
+				mock.Expect(option.Broadcast.trackingCmd(prefixes, 0)...).
+					ReplyString("OK")
+			}
+		} else if option.ClientTrackingOptions != nil {
 			mock.Expect(append([]string{"CLIENT", "TRACKING", "ON"}, option.ClientTrackingOptions...)...).
 				ReplyString("OK")
 		} else if !option.DisableCache {
@@ -804,7 +810,9 @@ func TestNewRESP2Pipe(t *testing.T) {
 	})
 	t.Run("Without DisableCache 2", func(t *testing.T) {
 		n1, n2 := net.Pipe()
+		n3, n4 := net.Pipe()
 		mock := &valkeyMock{buf: bufio.NewReader(n2), conn: n2, t: t}
+		mock2 := &valkeyMock{buf: bufio.NewReader(n4), conn: n4, t: t}
 		go func() {
 			mock.Expect("HELLO", "3").
 				ReplyError("ERR unknown command `HELLO`")
@@ -814,14 +822,42 @@ func TestNewRESP2Pipe(t *testing.T) {
 				ReplyError("UNKNOWN COMMAND")
 			mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
 				ReplyError("UNKNOWN COMMAND")
+			mock.Expect("HELLO", "2").
+				ReplyError("ERR unknown command `HELLO`")
+			mock.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).
+				ReplyError("UNKNOWN COMMAND")
+			mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
+				ReplyError("UNKNOWN COMMAND")
+			mock.Expect("CLIENT", "TRACKING", "ON", "REDIRECT", "42", "OPTIN").
+				ReplyError("ERR unknown subcommand 'REDIRECT'")
 			mock.Expect("PING").ReplyString("OK")
 		}()
-		if _, err := newPipe(context.Background(), func(ctx context.Context) (net.Conn, error) { return n1, nil }, &ClientOption{}); !errors.Is(err, ErrNoCache) {
+		go func() {
+			mock2.Expect("HELLO", "2").
+				ReplyError("ERR unknown command `HELLO`")
+			mock2.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).
+				ReplyError("UNKNOWN COMMAND")
+			mock2.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
+				ReplyError("UNKNOWN COMMAND")
+			mock2.Expect("CLIENT", "ID").ReplyInteger(42)
+			mock2.Expect("SUBSCRIBE", "__redis__:invalidate").
+				Reply(slicemsg('*', []ValkeyMessage{strmsg('+', "subscribe"), strmsg('+', "__redis__:invalidate"), {typ: ':', intlen: 1}}))
+			mock2.Expect("PING").ReplyString("OK")
+		}()
+		conns := []net.Conn{n1, n3}
+		if _, err := newPipe(context.Background(), func(ctx context.Context) (net.Conn, error) {
+			conn := conns[0]
+			conns = conns[1:]
+			return conn, nil
+		}, &ClientOption{}); !errors.Is(err, ErrNoCache) {
 			t.Fatalf("unexpected err: %v", err)
 		}
 		mock.Close()
+		mock2.Close()
 		n1.Close()
 		n2.Close()
+		n3.Close()
+		n4.Close()
 	})
 	t.Run("With Hello Proto 2", func(t *testing.T) { // kvrocks version 2.2.0
 		n1, n2 := net.Pipe()
@@ -1162,6 +1198,36 @@ func TestWriteSingleFlush(t *testing.T) {
 	ExpectOK(t, p.Do(context.Background(), cmds.NewCompleted([]string{"PING"})))
 }
 
+func TestPipeRTT(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+	go func() {
+		mock.Expect("PING").ReplyString("OK")
+		mock.Expect("GET", "a").Expect("GET", "b").ReplyString("1").ReplyString("2")
+	}()
+	ExpectOK(t, p.Do(context.Background(), cmds.NewCompleted([]string{"PING"})))
+	rtt := p.RTT()
+	if rtt <= 0 {
+		t.Fatalf("unexpected rtt %v", rtt)
+	}
+	p.DoMulti(context.Background(), cmds.NewCompleted([]string{"GET", "a"}), cmds.NewCompleted([]string{"GET", "b"}))
+	if s := p.Stats(); s.RTT == rtt || s.RTT != p.RTT() {
+		t.Fatalf("unexpected stats %v", s)
+	}
+
+	ewma := &pipe{}
+	ewma.observeRTT(time.Now().Add(-100*time.Millisecond), nil)
+	if rtt := ewma.RTT(); rtt < 100*time.Millisecond || rtt > 150*time.Millisecond {
+		t.Fatalf("unexpected rtt %v", rtt)
+	}
+	ewma.observeRTT(time.Now().Add(-time.Second), errors.New("any")) // failures are not counted
+	ewma.observeRTT(time.Now().Add(-600*time.Millisecond), nil)
+	if rtt := ewma.RTT(); rtt < 200*time.Millisecond || rtt > 250*time.Millisecond {
+		t.Fatalf("unexpected rtt %v", rtt)
+	}
+}
+
 func TestIgnoreOutOfBandDataDuringSyncMode(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	p, mock, cancel, _ := setup(t, ClientOption{})
@@ -1269,6 +1335,109 @@ func TestWriteMultiPipelineFlush(t *testing.T) {
 	}
 }
 
+func TestDoAsync(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+	go func() { mock.Expect("PING").ReplyString("OK") }()
+	ch := make(chan ValkeyResult, 1)
+	p.DoAsync(context.Background(), cmds.NewCompleted([]string{"PING"}), func(resp ValkeyResult) { ch <- resp })
+	ExpectOK(t, <-ch)
+}
+
+func TestDoMultiAsync(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+	go func() {
+		mock.Expect("PING").Expect("PING").ReplyString("OK").ReplyString("OK")
+	}()
+	ch := make(chan struct{})
+	resps := make([]ValkeyResult, 2)
+	p.DoMultiAsync(context.Background(), resps, func() { close(ch) }, cmds.NewCompleted([]string{"PING"}), cmds.NewCompleted([]string{"PING"}))
+	<-ch
+	for _, resp := range resps {
+		ExpectOK(t, resp)
+	}
+}
+
+func TestDoAsyncPipeline(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	for _, queueType := range []QueueType{QueueTypeRing, QueueTypeFlowBuffer, QueueTypeMPSC} {
+		t.Run(string(queueType), func(t *testing.T) {
+			p, mock, cancel, _ := setup(t, ClientOption{QueueType: queueType})
+			defer cancel()
+			times := 2000
+			wg := sync.WaitGroup{}
+			wg.Add(times * 2)
+
+			go func() {
+				for i := range times {
+					p.DoAsync(context.Background(), cmds.NewCompleted([]string{"GET", strconv.Itoa(i)}), func(resp ValkeyResult) {
+						if v, _ := resp.ToString(); v != strconv.Itoa(i) {
+							t.Errorf("unexpected response %v", v)
+						}
+						wg.Done()
+					})
+					resps := make([]ValkeyResult, 1)
+					p.DoMultiAsync(context.Background(), resps, func() {
+						if v, _ := resps[0].ToString(); v != strconv.Itoa(i) {
+							t.Errorf("unexpected response %v", v)
+						}
+						wg.Done()
+					}, cmds.NewCompleted([]string{"GET", strconv.Itoa(i)}))
+				}
+			}()
+			for i := range times {
+				mock.Expect("GET", strconv.Itoa(i)).ReplyString(strconv.Itoa(i))
+				mock.Expect("GET", strconv.Itoa(i)).ReplyString(strconv.Itoa(i))
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestDoAsyncBlockFallback(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+	go func() { mock.Expect("BLPOP", "a", "0").ReplyString("OK") }()
+	ch := make(chan ValkeyResult, 1)
+	p.DoAsync(context.Background(), cmds.NewBlockingCompleted([]string{"BLPOP", "a", "0"}), func(resp ValkeyResult) { ch <- resp })
+	ExpectOK(t, <-ch)
+}
+
+func TestDoAsyncContextCanceled(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, _, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+	ctx, cancel2 := context.WithCancel(context.Background())
+	cancel2()
+	p.DoAsync(ctx, cmds.NewCompleted([]string{"PING"}), func(resp ValkeyResult) {
+		if resp.Error() != context.Canceled {
+			t.Fatalf("unexpected err %v", resp.Error())
+		}
+	})
+	resps := make([]ValkeyResult, 1)
+	p.DoMultiAsync(ctx, resps, func() {
+		if resps[0].Error() != context.Canceled {
+			t.Fatalf("unexpected err %v", resps[0].Error())
+		}
+	}, cmds.NewCompleted([]string{"PING"}))
+}
+
+func TestDoAsyncOnClosedPipe(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, _, _, closeConn := setup(t, ClientOption{})
+	closeConn()
+	p.Close()
+	ch := make(chan ValkeyResult, 1)
+	p.DoAsync(context.Background(), cmds.NewCompleted([]string{"PING"}), func(resp ValkeyResult) { ch <- resp })
+	if err := (<-ch).Error(); err == nil {
+		t.Fatalf("unexpected nil err")
+	}
+}
+
 func TestDoStreamAutoPipelinePanic(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	p, _, _, shutdown := setup(t, ClientOption{})
@@ -1380,6 +1549,60 @@ func (b *limitedbuffer) ReadFrom(r io.Reader) (n int64, err error) {
 	}
 }
 
+func TestDoStreamElements(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+	go func() {
+		mock.Expect("LRANGE", "a", "0", "-1").Reply(slicemsg('*', []ValkeyMessage{strmsg('$', "1"), strmsg('$', "2"), strmsg('$', "3")}))
+	}()
+	conns := newPool(1, nil, 0, 0, nil)
+	s := p.DoStream(context.Background(), conns, cmds.NewCompleted([]string{"LRANGE", "a", "0", "-1"}))
+	var elems []string
+	for s.HasNext() {
+		for m, err := range s.Elements() {
+			if err != nil {
+				t.Fatalf("unexpected err %v\n", err)
+			}
+			elems = append(elems, m.string())
+		}
+	}
+	if strings.Join(elems, ",") != "1,2,3" {
+		t.Errorf("unexpected result %v\n", elems)
+	}
+	for _, err := range s.Elements() {
+		if err != io.EOF {
+			t.Errorf("unexpected err %v\n", err)
+		}
+	}
+	if w := conns.Acquire(context.Background()); w != p {
+		t.Errorf("pipe is not recycled\n")
+	}
+}
+
+func TestDoStreamElementsStopped(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+	go func() {
+		mock.Expect("LRANGE", "a", "0", "-1").Reply(slicemsg('*', []ValkeyMessage{strmsg('$', "1"), strmsg('$', "2"), strmsg('$', "3")}))
+	}()
+	conns := newPool(1, nil, 0, 0, nil)
+	s := p.DoStream(context.Background(), conns, cmds.NewCompleted([]string{"LRANGE", "a", "0", "-1"}))
+	for range s.Elements() {
+		break
+	}
+	if err := s.Error(); err != errStreamStopped {
+		t.Errorf("unexpected err %v\n", err)
+	}
+	if s.HasNext() {
+		t.Errorf("unexpected HasNext\n")
+	}
+	if p.Error() == nil {
+		t.Errorf("pipe is not closed\n")
+	}
+}
+
 func TestDoStreamRecycleDestinationFull(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	p, mock, cancel, _ := setup(t, ClientOption{})
@@ -1547,6 +1770,253 @@ func TestResponseSequenceWithPushMessageInjected(t *testing.T) {
 	wg.Wait()
 }
 
+func TestClientSideCachingRESP2(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	n1, n2 := net.Pipe()
+	n3, n4 := net.Pipe()
+	mock := &valkeyMock{buf: bufio.NewReader(n2), conn: n2, t: t}
+	mock2 := &valkeyMock{buf: bufio.NewReader(n4), conn: n4, t: t}
+	go func() {
+		mock.Expect("HELLO", "2").
+			ReplyError("ERR unknown command `HELLO`")
+		mock.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).
+			ReplyError("UNKNOWN COMMAND")
+		mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
+			ReplyError("UNKNOWN COMMAND")
+		mock.Expect("CLIENT", "TRACKING", "ON", "REDIRECT", "42", "OPTIN").
+			ReplyString("OK")
+	}()
+	go func() {
+		mock2.Expect("HELLO", "2").
+			ReplyError("ERR unknown command `HELLO`")
+		mock2.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).
+			ReplyError("UNKNOWN COMMAND")
+		mock2.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
+			ReplyError("UNKNOWN COMMAND")
+		mock2.Expect("CLIENT", "ID").ReplyInteger(42)
+		mock2.Expect("SUBSCRIBE", "__redis__:invalidate").
+			Reply(slicemsg('*', []ValkeyMessage{strmsg('+', "subscribe"), strmsg('+', "__redis__:invalidate"), {typ: ':', intlen: 1}}))
+	}()
+	conns := []net.Conn{n1, n3}
+	var invalidations atomic.Int64
+	p, err := newPipe(context.Background(), func(ctx context.Context) (net.Conn, error) {
+		conn := conns[0]
+		conns = conns[1:]
+		return conn, nil
+	}, &ClientOption{
+		AlwaysRESP2:       true,
+		CacheSizeEachConn: DefaultCacheBytes,
+		OnInvalidations:   func(keys []ValkeyMessage) { invalidations.Add(1) },
+	})
+	if err != nil {
+		t.Fatalf("pipe setup failed: %v", err)
+	}
+	closed := make(chan error, 1)
+	p.SetOnCloseHook(func(err error) {
+		select {
+		case closed <- err:
+		default:
+		}
+	})
+
+	expectCSC := func(resp string) {
+		mock.Expect("CLIENT", "CACHING", "YES").
+			Expect("MULTI").
+			Expect("PTTL", "a").
+			Expect("GET", "a").
+			Expect("EXEC").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("QUEUED").
+			ReplyString("QUEUED").
+			Reply(slicemsg('*', []ValkeyMessage{
+				{typ: ':', intlen: -1},
+				strmsg('+', resp),
+			}))
+	}
+	get := func() ValkeyMessage {
+		v, err := p.DoCache(context.Background(), Cacheable(cmds.NewCompleted([]string{"GET", "a"})), 10*time.Second).ToMessage()
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return v
+	}
+
+	go expectCSC("1")
+	if v := get(); v.string() != "1" || v.IsCacheHit() {
+		t.Fatalf("unexpected resp %v", v)
+	}
+	if v := get(); v.string() != "1" || !v.IsCacheHit() {
+		t.Fatalf("unexpected resp %v", v)
+	}
+
+	mock2.Expect().Reply(slicemsg('*', []ValkeyMessage{
+		strmsg('+', "message"),
+		strmsg('+', "__redis__:invalidate"),
+		slicemsg('*', []ValkeyMessage{strmsg('+', "a")}),
+	}))
+	for invalidations.Load() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	go expectCSC("2")
+	if v := get(); v.string() != "2" || v.IsCacheHit() {
+		t.Fatalf("the invalidation should be redirected to the cache %v", v)
+	}
+
+	mock2.Expect().Reply(slicemsg('*', []ValkeyMessage{
+		strmsg('+', "message"),
+		strmsg('+', "__redis__:invalidate"),
+		slicemsg('*', []ValkeyMessage{strmsg('+', "a")}),
+	}))
+	for invalidations.Load() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	// the invalidation of a pending entry can arrive before its reply, which should not be cached then.
+	go func() {
+		m := mock.Expect("CLIENT", "CACHING", "YES").
+			Expect("MULTI").
+			Expect("PTTL", "a").
+			Expect("GET", "a").
+			Expect("EXEC")
+		mock2.Expect().Reply(slicemsg('*', []ValkeyMessage{
+			strmsg('+', "message"),
+			strmsg('+', "__redis__:invalidate"),
+			slicemsg('*', []ValkeyMessage{strmsg('+', "a")}),
+		}))
+		for invalidations.Load() != 3 {
+			time.Sleep(time.Millisecond)
+		}
+		m.ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("QUEUED").
+			ReplyString("QUEUED").
+			Reply(slicemsg('*', []ValkeyMessage{
+				{typ: ':', intlen: -1},
+				strmsg('+', "3"),
+			}))
+	}()
+	if v := get(); v.string() != "3" || v.IsCacheHit() {
+		t.Fatalf("unexpected resp %v", v)
+	}
+	go expectCSC("4")
+	if v := get(); v.string() != "4" || v.IsCacheHit() {
+		t.Fatalf("the reply invalidated before its arrival should not be cached %v", v)
+	}
+
+	mock2.Close() // the pipe should be closed together with its invalidation pipe.
+	if err := <-closed; err == nil {
+		t.Fatalf("the pipe should be closed with an error")
+	}
+	if _, err := p.DoCache(context.Background(), Cacheable(cmds.NewCompleted([]string{"GET", "a"})), 10*time.Second).ToMessage(); err == nil {
+		t.Fatalf("the cache should not be used after the invalidation pipe is closed")
+	}
+	p.Close()
+	mock.Close()
+	n1.Close()
+	n2.Close()
+	n3.Close()
+	n4.Close()
+}
+
+func TestClientSideCachingRESP2SharedInvalidations(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	n1, n2 := net.Pipe()
+	n3, n4 := net.Pipe()
+	n5, n6 := net.Pipe()
+	mock1 := &valkeyMock{buf: bufio.NewReader(n2), conn: n2, t: t}
+	mock2 := &valkeyMock{buf: bufio.NewReader(n4), conn: n4, t: t}
+	mock3 := &valkeyMock{buf: bufio.NewReader(n6), conn: n6, t: t}
+	handshake := func(mock *valkeyMock, cmds ...[]string) {
+		mock.Expect("HELLO", "2").
+			ReplyError("ERR unknown command `HELLO`")
+		mock.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).
+			ReplyError("UNKNOWN COMMAND")
+		mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
+			ReplyError("UNKNOWN COMMAND")
+		for _, cmd := range cmds {
+			mock.Expect(cmd...).ReplyString("OK")
+		}
+	}
+	tracking := []string{"CLIENT", "TRACKING", "ON", "REDIRECT", "42", "OPTIN"}
+	go handshake(mock1, tracking)
+	go func() {
+		handshake(mock2)
+		mock2.Expect("CLIENT", "ID").ReplyInteger(42)
+		mock2.Expect("SUBSCRIBE", "__redis__:invalidate").
+			Reply(slicemsg('*', []ValkeyMessage{strmsg('+', "subscribe"), strmsg('+', "__redis__:invalidate"), {typ: ':', intlen: 1}}))
+	}()
+	go handshake(mock3, tracking)
+	conns := []net.Conn{n1, n3, n5}
+	connFn := func(ctx context.Context) (net.Conn, error) {
+		conn := conns[0]
+		conns = conns[1:]
+		return conn, nil
+	}
+	var invalidations atomic.Int64
+	option := &ClientOption{
+		AlwaysRESP2:       true,
+		CacheSizeEachConn: DefaultCacheBytes,
+		OnInvalidations:   func(keys []ValkeyMessage) { invalidations.Add(1) },
+	}
+	inv := newR2Inv(connFn, option)
+	p1, err := _newPipe(context.Background(), connFn, option, false, false, inv)
+	if err != nil {
+		t.Fatalf("pipe setup failed: %v", err)
+	}
+	p2, err := _newPipe(context.Background(), connFn, option, false, false, inv)
+	if err != nil {
+		t.Fatalf("pipe setup failed: %v", err)
+	}
+	attached := func() int {
+		inv.mu.Lock()
+		defer inv.mu.Unlock()
+		return len(inv.pipes)
+	}
+	if attached() != 2 || inv.pipes[p1] != inv.p || inv.pipes[p2] != inv.p || p1.r2id != 42 || p2.r2id != 42 {
+		t.Fatalf("the pipes should share one invalidation pipe")
+	}
+	for _, p := range []*pipe{p1, p2} {
+		p.cache.Flight("a", "GET", time.Second, time.Now())
+		p.cache.Update("a", "GET", strmsg('+', "a"))
+	}
+	mock2.Expect().Reply(slicemsg('*', []ValkeyMessage{
+		strmsg('+', "message"),
+		strmsg('+', "__redis__:invalidate"),
+		slicemsg('*', []ValkeyMessage{strmsg('+', "a")}),
+	}))
+	for invalidations.Load() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	for _, p := range []*pipe{p1, p2} {
+		if v, _ := p.cache.Flight("a", "GET", time.Second, time.Now()); v.typ != 0 {
+			t.Fatalf("the invalidation should be delivered to all pipes %v", v)
+		}
+		p.cache.Cancel("a", "GET", errors.New("done"))
+	}
+
+	go func() { mock1.Expect("PING").ReplyString("OK") }()
+	p1.Close()
+	for attached() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if inv.p == nil || inv.p.Error() != nil {
+		t.Fatalf("the invalidation pipe should be kept for the other pipe")
+	}
+	mock2.Close() // the other pipe should be closed together with the invalidation pipe.
+	for p2.Error() == nil {
+		time.Sleep(time.Millisecond)
+	}
+	p2.Close()
+	if attached() != 0 || inv.p != nil {
+		t.Fatalf("the invalidation pipe should be detached")
+	}
+	mock1.Close()
+	mock3.Close()
+	for _, n := range []net.Conn{n1, n2, n3, n4, n5, n6} {
+		n.Close()
+	}
+}
+
 func TestClientSideCaching(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	p, mock, cancel, _ := setup(t, ClientOption{})
@@ -1610,6 +2080,10 @@ func TestClientSideCaching(t *testing.T) {
 		t.Fatalf("unexpected cache hits count %v", v)
 	}
 
+	if s := p.Stats(); s.Inflight != 0 || s.CacheEntries != 1 || s.CacheBytes == 0 || s.CacheHits != uint64(times-1) || s.CacheMisses != 1 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+
 	// cache invalidation
 	invalidateCSC(slicemsg('*', []ValkeyMessage{strmsg('+', "a")}))
 	go func() {
@@ -1729,6 +2203,199 @@ func TestClientSideCachingBCAST(t *testing.T) {
 	}
 }
 
+func TestClientSideCachingStale(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+
+	expectCSC := func(key string, reply func(m *valkeyExpect)) {
+		reply(mock.Expect("CLIENT", "CACHING", "YES").
+			Expect("MULTI").
+			Expect("PTTL", key).
+			Expect("GET", key).
+			Expect("EXEC").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("OK"))
+	}
+	value := func(v string) func(m *valkeyExpect) {
+		return func(m *valkeyExpect) {
+			m.Reply(slicemsg('*', []ValkeyMessage{{typ: ':', intlen: -1}, strmsg('+', v)}))
+		}
+	}
+	ttl := 50 * time.Millisecond
+	get := func(ctx context.Context, key string) ValkeyResult {
+		return p.DoCache(ctx, Cacheable(cmds.NewCompleted([]string{"GET", key})), ttl)
+	}
+	multiGet := func(ctx context.Context, key string) ValkeyResult {
+		return p.DoMultiCache(ctx, CT(Cacheable(cmds.NewCompleted([]string{"GET", key})), ttl)).s[0]
+	}
+	swr := WithCacheStale(context.Background(), CacheStaleOption{WhileRevalidate: time.Minute})
+	sie := WithCacheStale(context.Background(), CacheStaleOption{IfError: time.Minute})
+
+	for name, do := range map[string]func(ctx context.Context, key string) ValkeyResult{"DoCache": get, "DoMultiCache": multiGet} {
+		t.Run(name, func(t *testing.T) {
+			key := name
+			go expectCSC(key, value("1"))
+			if v, _ := do(swr, key).ToString(); v != "1" {
+				t.Fatalf("unexpected value %v", v)
+			}
+			time.Sleep(ttl * 2)
+
+			// stale while revalidate
+			go expectCSC(key, value("2"))
+			if v, _ := do(swr, key).ToString(); v != "1" {
+				t.Fatalf("the stale value should be returned %v", v)
+			}
+			for {
+				if v, _ := do(swr, key).ToString(); v == "2" {
+					break
+				}
+				t.Logf("waiting for revalidating")
+				time.Sleep(10 * time.Millisecond)
+			}
+			time.Sleep(ttl * 2)
+
+			// stale if error
+			go expectCSC(key, func(m *valkeyExpect) { m.ReplyError("EXECABORT") })
+			if v, err := do(sie, key).ToString(); err != nil || v != "2" {
+				t.Fatalf("the stale value should be returned %v %v", v, err)
+			}
+			go expectCSC(key, func(m *valkeyExpect) { m.ReplyError("EXECABORT") })
+			if err := do(context.Background(), key).Error(); err != ErrDoCacheAborted {
+				t.Fatalf("unexpected err %v", err)
+			}
+		})
+	}
+}
+
+func TestBroadcastTracking(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	if _, err := NewBroadcastTracking("a", "ab"); err != ErrBroadcastPrefixOverlap {
+		t.Fatalf("unexpected err %v", err)
+	}
+	b, err := NewBroadcastTracking("a:")
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	p, mock, cancel, _ := setup(t, ClientOption{Broadcast: b})
+	defer cancel()
+
+	expectCSC := func(key, resp string) {
+		mock.Expect("ECHO", "").
+			Expect("MULTI").
+			Expect("PTTL", key).
+			Expect("GET", key).
+			Expect("EXEC").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("OK").
+			Reply(slicemsg('*', []ValkeyMessage{
+				{typ: ':', intlen: -1},
+				strmsg('+', resp),
+			}))
+	}
+	get := func(key string) ValkeyMessage {
+		t.Helper()
+		v, err := p.DoCache(context.Background(), Cacheable(cmds.NewCompleted([]string{"GET", key})), time.Second).ToMessage()
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return v
+	}
+
+	t.Run("Cache Prefixes Only", func(t *testing.T) {
+		go expectCSC("a:1", "1")
+		if v := get("a:1"); v.IsCacheHit() || v.string() != "1" {
+			t.Fatalf("unexpected resp %v", v)
+		}
+		if v := get("a:1"); !v.IsCacheHit() || v.string() != "1" {
+			t.Fatalf("unexpected resp %v", v)
+		}
+		for range 2 {
+			go func() {
+				mock.Expect("GET", "b:1").ReplyString("2")
+			}()
+			if v := get("b:1"); v.IsCacheHit() || v.string() != "2" {
+				t.Fatalf("unexpected resp %v", v)
+			}
+		}
+		go func() {
+			mock.Expect("GET", "b:1").ReplyString("2")
+		}()
+		resps := p.DoMultiCache(context.Background(),
+			CT(Cacheable(cmds.NewCompleted([]string{"GET", "b:1"})), time.Second),
+			CT(Cacheable(cmds.NewCompleted([]string{"GET", "a:1"})), time.Second),
+		)
+		if v, _ := resps.s[0].ToMessage(); v.IsCacheHit() || v.string() != "2" {
+			t.Fatalf("unexpected resp %v", v)
+		}
+		if v, _ := resps.s[1].ToMessage(); !v.IsCacheHit() || v.string() != "1" {
+			t.Fatalf("unexpected resp %v", v)
+		}
+	})
+
+	t.Run("Add", func(t *testing.T) {
+		if err := b.Add(context.Background(), "a:x"); err != ErrBroadcastPrefixOverlap {
+			t.Fatalf("unexpected err %v", err)
+		}
+		go func() {
+			mock.Expect("CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "b:").ReplyString("OK")
+		}()
+		if err := b.Add(context.Background(), "a:", "b:"); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if prefixes := b.Prefixes(); !slices.Equal(prefixes, []string{"a:", "b:"}) {
+			t.Fatalf("unexpected prefixes %v", prefixes)
+		}
+		go expectCSC("b:1", "2")
+		if v := get("b:1"); v.IsCacheHit() || v.string() != "2" {
+			t.Fatalf("unexpected resp %v", v)
+		}
+		if v := get("b:1"); !v.IsCacheHit() {
+			t.Fatalf("unexpected resp %v", v)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		go func() {
+			mock.Expect("CLIENT", "TRACKING", "OFF").
+				Expect("CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "b:").
+				ReplyString("OK").
+				ReplyString("OK")
+		}()
+		if err := b.Remove(context.Background(), "a:"); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		go func() {
+			mock.Expect("GET", "a:1").ReplyString("1")
+		}()
+		if v := get("a:1"); v.IsCacheHit() {
+			t.Fatalf("unexpected resp %v", v)
+		}
+		go expectCSC("b:1", "2") // flushed
+		if v := get("b:1"); v.IsCacheHit() {
+			t.Fatalf("unexpected resp %v", v)
+		}
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		go func() {
+			mock.Expect("CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "c:").ReplyError("ERR")
+		}()
+		if err := b.Add(context.Background(), "c:"); err == nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if p.Error() == nil {
+			t.Fatalf("the pipe should be closed")
+		}
+		if prefixes := b.Prefixes(); !slices.Equal(prefixes, []string{"b:", "c:"}) {
+			t.Fatalf("unexpected prefixes %v", prefixes)
+		}
+	})
+}
 func TestClientSideCachingOPTOUT(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	p, mock, cancel, _ := setup(t, ClientOption{
@@ -2454,6 +3121,9 @@ func TestClientSideCachingDoMultiCache(t *testing.T) {
 	t.Run("LRU", func(t *testing.T) {
 		testfn(t, ClientOption{})
 	})
+	t.Run("TinyLFU", func(t *testing.T) {
+		testfn(t, ClientOption{NewCachePolicyFn: NewTinyLFUPolicyFn(TinyLFUOption{Window: 0.01})})
+	})
 	t.Run("Simple", func(t *testing.T) {
 		testfn(t, ClientOption{
 			NewCacheStoreFn: func(option CacheStoreOption) CacheStore {
@@ -4816,6 +5486,71 @@ func TestExitOnFlowBufferFullAndPingTimeout(t *testing.T) {
 	}
 }
 
+func TestExitOnMPSCFullAndConnError(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, mock, _, closeConn := setup(t, ClientOption{
+		QueueType:         QueueTypeMPSC,
+		RingScaleEachConn: 1,
+	})
+	p.background()
+
+	// fill the buffer
+	for range 2 {
+		go func() {
+			if err := p.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != io.EOF && !strings.HasPrefix(err.Error(), "io:") {
+				t.Errorf("unexpected result, expected io err, got %v", err)
+			}
+		}()
+	}
+	// let writer loop over the buffer
+	for range 2 {
+		mock.Expect("GET", "a")
+	}
+
+	time.Sleep(time.Second) // make sure the writer is waiting for the next write
+	closeConn()
+
+	if err := p.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != io.EOF && !strings.HasPrefix(err.Error(), "io:") {
+		t.Errorf("unexpected result, expected io err, got %v", err)
+	}
+}
+
+func TestMPSCFullAndContextCanceled(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{
+		QueueType:         QueueTypeMPSC,
+		RingScaleEachConn: 1,
+	})
+	p.background()
+
+	// fill the buffer
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := p.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).ToString(); err != nil || v != "b" {
+				t.Errorf("unexpected result %v %v", v, err)
+			}
+		}()
+	}
+	for range 2 {
+		mock.Expect("GET", "a")
+	}
+
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer ctxCancel()
+	if err := p.Do(ctx, cmds.NewCompleted([]string{"GET", "a"})).Error(); err != context.DeadlineExceeded {
+		t.Errorf("unexpected result, expected context.DeadlineExceeded, got %v", err)
+	}
+
+	for range 2 {
+		mock.Expect().ReplyString("b")
+	}
+	wg.Wait()
+	cancel()
+}
+
 func TestExitAllGoroutineOnWriteError(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	conn, mock, _, closeConn := setup(t, ClientOption{})
@@ -5783,6 +6518,91 @@ func TestPipe_CleanSubscriptions_7(t *testing.T) {
 	)
 }
 
+func TestPipe_CloseWithContext(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	t.Run("Drain", func(t *testing.T) {
+		p, mock, _, closeConn := setup(t, ClientOption{})
+		defer closeConn()
+
+		done := make(chan ValkeyResult)
+		go func() {
+			done <- p.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"}))
+		}()
+		for p.loadWaits() != 1 {
+			runtime.Gosched()
+		}
+		go func() {
+			mock.Expect("GET", "a").ReplyString("a")
+			mock.Expect("PING").ReplyString("OK")
+		}()
+		if err := p.CloseWithContext(context.Background()); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if v, _ := (<-done).ToString(); v != "a" {
+			t.Fatalf("unexpected result %v", v)
+		}
+		if err := p.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).Error(); err != ErrClosing {
+			t.Fatalf("unexpected err %v", err)
+		}
+		mock.Close()
+	})
+	t.Run("Unsubscribe", func(t *testing.T) {
+		p, mock, _, closeConn := setup(t, ClientOption{})
+		defer closeConn()
+
+		done := make(chan error)
+		go func() {
+			done <- p.Receive(context.Background(), cmds.NewBuilder(cmds.NoSlot).Subscribe().Channel("ch").Build(), func(msg PubSubMessage) {})
+		}()
+		mock.Expect("SUBSCRIBE", "ch").Reply(slicemsg('>', []ValkeyMessage{
+			strmsg('+', "subscribe"),
+			strmsg('+', "ch"),
+			{typ: ':', intlen: 1},
+		}))
+		go func() {
+			mock.Expect("UNSUBSCRIBE").Expect(cmds.PingCmd.Commands()...).Expect("PUNSUBSCRIBE").Expect(cmds.PingCmd.Commands()...).Reply(
+				slicemsg('>', []ValkeyMessage{
+					strmsg('+', "unsubscribe"),
+					strmsg('+', "ch"),
+					{typ: ':', intlen: 0},
+				}),
+				strmsg('+', "PONG"),
+				slicemsg('>', []ValkeyMessage{
+					strmsg('+', "punsubscribe"),
+					{typ: '_'},
+					{typ: ':', intlen: 0},
+				}),
+				strmsg('+', "PONG"),
+			)
+		}()
+		if err := p.CloseWithContext(context.Background()); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if err := <-done; err != ErrClosing {
+			t.Fatalf("unexpected err %v", err)
+		}
+		mock.Close()
+	})
+	t.Run("Force", func(t *testing.T) {
+		p, mock, _, closeConn := setup(t, ClientOption{})
+		defer closeConn()
+
+		go func() {
+			mock.Expect("PING") // no reply
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		if err := p.CloseWithContext(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected err %v", err)
+		}
+		mock.Close()
+		for atomic.LoadInt32(&p.state) != 4 {
+			t.Log("wait the pipe to be closed")
+			time.Sleep(time.Millisecond * 100)
+		}
+	})
+}
+
 func TestPingOnConnError(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	t.Run("sync", func(t *testing.T) {
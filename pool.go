@@ -5,6 +5,8 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"github.com/valkey-io/valkey-go/internal/cmds"
 )
 
 // errAcquireComplete is a special error used to indicate that the Acquire operation has completed successfully
@@ -22,23 +24,32 @@ func newPool(cap int, dead wire, cleanup time.Duration, minSize int, makeFn func
 		dead:    dead,
 		make:    makeFn,
 		list:    make([]wire, 0, 4),
+		inuse:   make(map[wire]struct{}),
 		cond:    sync.NewCond(&sync.Mutex{}),
 		cleanup: cleanup,
 	}
 }
 
 type pool struct {
-	dead    wire
-	cond    *sync.Cond
-	timer   *time.Timer
-	make    func(ctx context.Context) wire
-	list    []wire
-	cleanup time.Duration
-	size    int
-	minSize int
-	cap     int
-	down    bool
-	timerOn bool
+	dead     wire
+	cond     *sync.Cond
+	timer    *time.Timer
+	htimer   *time.Timer // health check timer for the minIdle
+	make     func(ctx context.Context) wire
+	list     []wire
+	inuse    map[wire]struct{} // the acquired ones, which are closed if the CloseWithContext is forced
+	cleanup  time.Duration
+	check    time.Duration
+	size     int
+	minSize  int
+	minIdle  int
+	cap      int
+	waiters  int
+	down     bool
+	timerOn  bool
+	filling  bool
+	checking bool // the idle ones are taken out by the healthCheck
+	forced   bool // the CloseWithContext is forced by its ctx
 }
 
 func (p *pool) Acquire(ctx context.Context) (v wire) {
@@ -59,7 +70,9 @@ func (p *pool) Acquire(ctx context.Context) (v wire) {
 
 retry:
 	for len(p.list) == 0 && p.size == p.cap && !p.down && ctx.Err() == nil {
+		p.waiters++
 		p.cond.Wait()
+		p.waiters--
 	}
 
 	if ctx.Err() != nil {
@@ -77,11 +90,19 @@ retry:
 	}
 	if len(p.list) == 0 {
 		p.size++
+		p.refill()
 		// unlock before start to make a new wire
 		// allowing others to make wires concurrently instead of waiting in line
 		p.cond.L.Unlock()
 		v = p.make(ctx)
 		v.StopTimer()
+		p.cond.L.Lock()
+		p.inuse[v] = struct{}{}
+		forced := p.forced
+		p.cond.L.Unlock()
+		if forced {
+			v.Close()
+		}
 		return v
 	}
 
@@ -94,12 +115,15 @@ retry:
 		v.Close()
 		goto retry
 	}
+	p.inuse[v] = struct{}{}
+	p.refill()
 	p.cond.L.Unlock()
 	return v
 }
 
 func (p *pool) Store(v wire) {
 	p.cond.L.Lock()
+	delete(p.inuse, v)
 	if !p.down && v.Error() == nil {
 		p.list = append(p.list, v)
 		p.startTimerIfNeeded()
@@ -107,15 +131,31 @@ func (p *pool) Store(v wire) {
 	} else {
 		p.size--
 		v.Close()
+		p.refill()
 	}
+	down := p.down
 	p.cond.L.Unlock()
-	p.cond.Signal()
+	if down {
+		p.cond.Broadcast() // wake up all CloseWithContext callers
+	} else {
+		p.cond.Signal()
+	}
+}
+
+func (p *pool) Stats() PoolStats {
+	p.cond.L.Lock()
+	s := PoolStats{Size: p.size, Idle: len(p.list), Waiters: p.waiters}
+	p.cond.L.Unlock()
+	return s
 }
 
 func (p *pool) Close() {
 	p.cond.L.Lock()
 	p.down = true
 	p.stopTimer()
+	if p.htimer != nil {
+		p.htimer.Stop()
+	}
 	for _, w := range p.list {
 		w.Close()
 	}
@@ -123,6 +163,43 @@ func (p *pool) Close() {
 	p.cond.Broadcast()
 }
 
+// CloseWithContext closes the pool and waits for all acquired connections to be stored back.
+// Connections that are still in use when the ctx is done are closed immediately, which interrupts their blocking commands.
+func (p *pool) CloseWithContext(ctx context.Context) error {
+	p.Close()
+	p.cond.L.Lock()
+
+	if p.size > len(p.list) && ctx.Err() == nil && ctx.Done() != nil {
+		poolCtx, cancel := context.WithCancelCause(ctx)
+		defer cancel(errAcquireComplete)
+
+		go func() {
+			<-poolCtx.Done()
+			if context.Cause(poolCtx) != errAcquireComplete {
+				p.cond.Broadcast()
+			}
+		}()
+	}
+
+	for p.size > len(p.list) && ctx.Err() == nil { // the p.list is not touched after the pool is down
+		p.cond.Wait()
+	}
+	if p.size <= len(p.list) {
+		p.cond.L.Unlock()
+		return nil
+	}
+	p.forced = true
+	inuse := make([]wire, 0, len(p.inuse))
+	for w := range p.inuse {
+		inuse = append(inuse, w)
+	}
+	p.cond.L.Unlock()
+	for _, w := range inuse {
+		w.Close()
+	}
+	return ctx.Err()
+}
+
 func (p *pool) startTimerIfNeeded() {
 	if p.cleanup == 0 || p.timerOn || len(p.list) <= p.minSize {
 		return
@@ -151,6 +228,109 @@ func (p *pool) removeIdleConns() {
 	p.timerOn = false
 }
 
+// keepIdle makes the pool dial n connections in advance and keep them idle.
+// The idle ones are checked with PING every interval, and broken ones are replaced in the background.
+func (p *pool) keepIdle(n int, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultBlockingPoolHealthCheck
+	}
+	p.cond.L.Lock()
+	p.minIdle = n
+	p.minSize = max(p.minSize, n) // don't let the cleanup remove them
+	p.check = interval
+	p.htimer = time.AfterFunc(interval, p.healthCheck)
+	p.refill()
+	p.cond.L.Unlock()
+}
+
+// refill starts dialing idle connections in the background if there are fewer than minIdle. It must be called with the lock.
+func (p *pool) refill() {
+	if p.filling || p.checking || p.down || len(p.list) >= p.minIdle || p.size >= p.cap {
+		return
+	}
+	p.filling = true
+	go p.fill()
+}
+
+func (p *pool) fill() {
+	for {
+		p.cond.L.Lock()
+		if p.down || len(p.list) >= p.minIdle || p.size >= p.cap {
+			p.filling = false
+			p.cond.L.Unlock()
+			return
+		}
+		p.size++
+		p.cond.L.Unlock()
+
+		w := p.make(context.Background())
+		if w.Error() != nil { // stop dialing until the next health check
+			p.cond.L.Lock()
+			p.size--
+			p.filling = false
+			p.cond.L.Unlock()
+			w.Close()
+			p.cond.Signal()
+			return
+		}
+		p.Store(w)
+	}
+}
+
+// healthCheck checks the idle ones with PING one at a time from the least recently used one,
+// so that the others are still available to Acquire during the check.
+func (p *pool) healthCheck() {
+	p.cond.L.Lock()
+	if p.down {
+		p.cond.L.Unlock()
+		return
+	}
+	n := len(p.list)
+	p.checking = true
+	p.cond.L.Unlock()
+
+	for i := 0; i < n; i++ {
+		p.cond.L.Lock()
+		if p.down || len(p.list) == 0 {
+			p.cond.L.Unlock()
+			break
+		}
+		w := p.list[0]
+		copy(p.list, p.list[1:])
+		p.list[len(p.list)-1] = nil
+		p.list = p.list[:len(p.list)-1]
+		p.cond.L.Unlock()
+
+		if w.StopTimer() && w.Error() == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), p.check)
+			err := w.Do(ctx, cmds.PingCmd).NonValkeyError()
+			cancel()
+			if err == nil {
+				p.Store(w) // the healthy one is put back as the most recently used one
+				continue
+			}
+		}
+		p.discard(w)
+	}
+
+	p.cond.L.Lock()
+	p.checking = false
+	if !p.down {
+		p.refill()
+		p.htimer.Reset(p.check)
+	}
+	p.cond.L.Unlock()
+}
+
+// discard closes the broken one taken out of the pool and frees its slot.
+func (p *pool) discard(w wire) {
+	p.cond.L.Lock()
+	p.size--
+	p.cond.L.Unlock()
+	w.Close()
+	p.cond.Broadcast() // wake up waiters of Acquire and CloseWithContext callers
+}
+
 func (p *pool) stopTimer() {
 	p.timerOn = false
 	if p.timer != nil {
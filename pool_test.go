@@ -120,6 +120,61 @@ func TestPool(t *testing.T) {
 		}
 	})
 
+	t.Run("Stats", func(t *testing.T) {
+		pool, _ := setup(1)
+		w1 := pool.Acquire(context.Background())
+		if s := pool.Stats(); s != (PoolStats{Size: 1}) {
+			t.Fatalf("unexpected stats %v", s)
+		}
+		done := make(chan struct{})
+		go func() {
+			pool.Store(pool.Acquire(context.Background()))
+			close(done)
+		}()
+		for pool.Stats().Waiters != 1 {
+			runtime.Gosched()
+		}
+		pool.Store(w1)
+		<-done
+		if s := pool.Stats(); s != (PoolStats{Size: 1, Idle: 1}) {
+			t.Fatalf("unexpected stats %v", s)
+		}
+	})
+
+	t.Run("CloseWithContext", func(t *testing.T) {
+		pool, _ := setup(2)
+		w1 := pool.Acquire(context.Background())
+		w2 := pool.Acquire(context.Background())
+		pool.Store(w1)
+		go func() {
+			time.Sleep(time.Millisecond * 10)
+			pool.Store(w2)
+		}()
+		if err := pool.CloseWithContext(context.Background()); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if w1.Error() != ErrClosing || w2.Error() != ErrClosing {
+			t.Fatalf("pool does not close wires after CloseWithContext()")
+		}
+	})
+
+	t.Run("CloseWithContext Timeout", func(t *testing.T) {
+		pool, _ := setup(2)
+		w1 := pool.Acquire(context.Background())
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		if err := pool.CloseWithContext(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if w1.Error() != ErrClosing {
+			t.Fatalf("pool does not close acquired wire when CloseWithContext() is forced")
+		}
+		pool.Store(w1)
+		if w1.Error() != ErrClosing {
+			t.Fatalf("pool does not close stored wire after CloseWithContext()")
+		}
+	})
+
 	t.Run("Close", func(t *testing.T) {
 		pool, count := setup(2)
 		w1 := pool.Acquire(context.Background())
@@ -332,6 +387,171 @@ func TestPoolWithIdleTTL(t *testing.T) {
 	})
 }
 
+func TestPoolWithMinIdle(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var count, pings int32
+	var broken atomic.Value
+	broken.Store((*mockWire)(nil))
+	p := newPool(10, dead, 0, 0, func(_ context.Context) wire {
+		atomic.AddInt32(&count, 1)
+		var closed atomic.Bool
+		w := &mockWire{
+			CloseFn: func() {
+				closed.Store(true)
+			},
+			ErrorFn: func() error {
+				if closed.Load() {
+					return ErrClosing
+				}
+				return nil
+			},
+		}
+		w.DoFn = func(cmd Completed) ValkeyResult {
+			atomic.AddInt32(&pings, 1)
+			if broken.Load().(*mockWire) == w {
+				return newErrResult(context.DeadlineExceeded)
+			}
+			return newResult(strmsg('+', "PONG"), nil)
+		}
+		return w
+	})
+	defer p.Close()
+
+	waitIdle := func(idle int) {
+		for p.Stats().Idle != idle {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	p.keepIdle(2, time.Hour)
+	waitIdle(2)
+	if s := p.Stats(); s.Size != 2 || atomic.LoadInt32(&count) != 2 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+
+	w := p.Acquire(context.Background())
+	waitIdle(2)
+	if s := p.Stats(); s.Size != 3 || atomic.LoadInt32(&count) != 3 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+	p.Store(w)
+
+	p.cond.L.Lock()
+	broken.Store(p.list[0].(*mockWire))
+	p.cond.L.Unlock()
+	p.healthCheck()
+	waitIdle(2)
+	if s := p.Stats(); s.Size != 2 || atomic.LoadInt32(&count) != 3 || atomic.LoadInt32(&pings) != 3 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+	if w := broken.Load().(*mockWire); w.Error() == nil {
+		t.Fatalf("broken wire is not closed")
+	}
+
+	p.cond.L.Lock()
+	for _, w := range p.list[1:] {
+		w.Close()
+	}
+	p.list = p.list[:1]
+	p.size = 1
+	p.cond.L.Unlock()
+	p.healthCheck()
+	waitIdle(2)
+	if s := p.Stats(); s.Size != 2 || atomic.LoadInt32(&count) != 4 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+}
+
+func TestPoolHealthCheckOneByOne(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var count, closes int32
+	pinging := make(chan struct{})
+	release := make(chan struct{})
+	var first atomic.Bool
+	p := newPool(10, dead, 0, 0, func(_ context.Context) wire {
+		atomic.AddInt32(&count, 1)
+		var closed atomic.Bool
+		return &mockWire{
+			DoFn: func(cmd Completed) ValkeyResult {
+				if first.CompareAndSwap(false, true) {
+					pinging <- struct{}{}
+					<-release
+					return newErrResult(context.DeadlineExceeded)
+				}
+				return newResult(strmsg('+', "PONG"), nil)
+			},
+			CloseFn: func() {
+				atomic.AddInt32(&closes, 1)
+				closed.Store(true)
+			},
+			ErrorFn: func() error {
+				if closed.Load() {
+					return ErrClosing
+				}
+				return nil
+			},
+		}
+	})
+	defer p.Close()
+	p.keepIdle(2, time.Hour)
+	for p.Stats().Idle != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	go p.healthCheck()
+	<-pinging
+	if s := p.Stats(); s.Idle != 1 || s.Size != 2 {
+		t.Fatalf("the other idle one should be kept in the pool during the check %v", s)
+	}
+	w := p.Acquire(context.Background())
+	if atomic.LoadInt32(&count) != 2 {
+		t.Fatalf("the idle one should be acquired without dialing")
+	}
+	p.Store(w)
+	close(release)
+	for {
+		p.cond.L.Lock()
+		checking := p.checking
+		p.cond.L.Unlock()
+		if !checking {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if c := atomic.LoadInt32(&closes); c != 1 {
+		t.Fatalf("the broken one should be closed once, got %v", c)
+	}
+	for p.Stats().Idle != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if s := p.Stats(); s.Size != 2 || atomic.LoadInt32(&count) != 3 {
+		t.Fatalf("the broken one should be replaced %v", s)
+	}
+}
+
+func TestPoolWithMinIdleDialError(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	var count int32
+	p := newPool(10, dead, 0, 0, func(_ context.Context) wire {
+		atomic.AddInt32(&count, 1)
+		return dead
+	})
+	p.keepIdle(2, time.Hour)
+	for {
+		p.cond.L.Lock()
+		filling := p.filling
+		p.cond.L.Unlock()
+		if !filling {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if s := p.Stats(); s.Size != 0 || s.Idle != 0 || atomic.LoadInt32(&count) != 1 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+	p.Close()
+}
+
 func TestPoolWithConnLifetime(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	setup := func(wires []wire) *pool {
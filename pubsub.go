@@ -139,6 +139,13 @@ func (s *subs) Unsubscribe(sub PubSubSubscription) {
 	}
 }
 
+func (s *subs) Len() (n int) {
+	s.mu.RLock()
+	n = len(s.sub)
+	s.mu.RUnlock()
+	return n
+}
+
 func (s *subs) Close() {
 	var sbs map[uint64]*sub
 	s.mu.Lock()
@@ -14,6 +14,7 @@ import (
 
 var errChunked = errors.New("unbounded valkey message")
 var errOldNull = errors.New("RESP2 null")
+var errStreamStopped = errors.New("valkey stream is stopped before reading all elements")
 
 const (
 	typeBlobString     = byte('$')
@@ -370,6 +371,64 @@ next:
 	}
 }
 
+// streamElems reads an array, set or map message and passes its elements to the fn one by one until the fn returns false.
+func streamElems(i *bufio.Reader, fn func(ValkeyMessage) bool) (err error, clean bool) {
+next:
+	var typ byte
+	if typ, err = i.ReadByte(); err != nil {
+		return err, false
+	}
+	switch typ {
+	case typeArray, typeSet, typeMap:
+		length, err := readI(i)
+		chunked := err == errChunked
+		if err != nil && !chunked {
+			return err, false
+		}
+		if length == -1 {
+			return Nil, true
+		}
+		if typ == typeMap {
+			length *= 2
+		}
+		for n := int64(0); chunked || n < length; n++ {
+			m, err := readNextMessage(i)
+			if err != nil {
+				return err, false
+			}
+			if chunked && m.typ == typeEnd {
+				break
+			}
+			if !fn(m) {
+				return errStreamStopped, false
+			}
+		}
+		return nil, true
+	case typeAttribute:
+		if _, err = readMap(i); err != nil {
+			return err, false
+		}
+		goto next
+	default:
+		_ = i.UnreadByte()
+		m, err := readNextMessage(i)
+		if err != nil {
+			return err, false
+		}
+		switch m.typ {
+		case typeNull:
+			return Nil, true
+		case typeSimpleErr, typeBlobErr:
+			mm := m
+			return (*ValkeyError)(&mm), true
+		case typePush:
+			goto next
+		default:
+			return fmt.Errorf("unsupported valkey %q response for streaming elements", typeNames[m.typ]), true
+		}
+	}
+}
+
 func writeCmd(o *bufio.Writer, cmd []string) (err error) {
 	err = writeN(o, '*', len(cmd))
 	for _, m := range cmd {
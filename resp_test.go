@@ -334,6 +334,82 @@ func TestReadComplexTypesStream(t *testing.T) {
 	}
 }
 
+func TestReadElemsStream(t *testing.T) {
+	for _, data := range []string{
+		"*3\r\n:1\r\n:2\r\n:3\r\n",
+		"~3\r\n:1\r\n:2\r\n:3\r\n",
+		"*?\r\n:1\r\n:2\r\n:3\r\n.\r\n",
+		">2\r\n+ignore\r\n+ignore\r\n|1\r\n+key\r\n+value\r\n*3\r\n:1\r\n:2\r\n:3\r\n",
+	} {
+		for i := 1; i <= len(data); i++ {
+			var elems []int64
+			err, clean := streamElems(bufio.NewReader(io.LimitReader(strings.NewReader(data), int64(i))), func(m ValkeyMessage) bool {
+				elems = append(elems, m.intlen)
+				return true
+			})
+			if i < len(data) {
+				if err == nil || clean {
+					t.Fatalf("unexpected no error: %v", i)
+				}
+			} else {
+				if err != nil || !clean {
+					t.Fatal(err)
+				}
+				if len(elems) != 3 || elems[0] != 1 || elems[1] != 2 || elems[2] != 3 {
+					t.Fatalf("unexpected elems %v", elems)
+				}
+			}
+		}
+	}
+}
+
+func TestReadMapElemsStream(t *testing.T) {
+	data := "%2\r\n:1\r\n:2\r\n:3\r\n*1\r\n:4\r\n"
+	var elems []ValkeyMessage
+	err, clean := streamElems(bufio.NewReader(strings.NewReader(data)), func(m ValkeyMessage) bool {
+		elems = append(elems, m)
+		return true
+	})
+	if err != nil || !clean {
+		t.Fatal(err)
+	}
+	if len(elems) != 4 || elems[0].intlen != 1 || elems[2].intlen != 3 || len(elems[3].values()) != 1 {
+		t.Fatalf("unexpected elems %v", elems)
+	}
+}
+
+func TestReadElemsStreamStopped(t *testing.T) {
+	data := "*3\r\n:1\r\n:2\r\n:3\r\n"
+	var elems int
+	err, clean := streamElems(bufio.NewReader(strings.NewReader(data)), func(m ValkeyMessage) bool {
+		elems++
+		return false
+	})
+	if err != errStreamStopped || clean || elems != 1 {
+		t.Fatalf("unexpected result %v %v %v", err, clean, elems)
+	}
+}
+
+func TestReadNonElemsStream(t *testing.T) {
+	for _, c := range []struct {
+		data string
+		err  string
+	}{
+		{data: "_\r\n", err: Nil.Error()},
+		{data: "*-1\r\n", err: Nil.Error()},
+		{data: "-ERR\r\n", err: "ERR"},
+		{data: "+OK\r\n", err: "unsupported"},
+	} {
+		err, clean := streamElems(bufio.NewReader(strings.NewReader(c.data)), func(m ValkeyMessage) bool {
+			t.Fatalf("unexpected elem %v", m)
+			return true
+		})
+		if err == nil || !strings.HasPrefix(err.Error(), c.err) || !clean {
+			t.Fatalf("unexpected result %v %v", err, clean)
+		}
+	}
+}
+
 func TestReadStringCRLFErr(t *testing.T) {
 	data := "+\n"
 	if _, err := readNextMessage(bufio.NewReader(strings.NewReader(data))); err.Error() != unexpectedNoCRLF {
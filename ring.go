@@ -11,6 +11,9 @@ import (
 type queue interface {
 	PutOne(ctx context.Context, m Completed) (chan ValkeyResult, error)
 	PutMulti(ctx context.Context, m []Completed, resps []ValkeyResult) (chan ValkeyResult, error)
+	// PutAsync queues the one or the multi like PutOne and PutMulti, but nobody waits on the returned channel of NextResultCh.
+	// Instead, the result sent to the channel is passed to the fn by FinishResult.
+	PutAsync(ctx context.Context, one Completed, multi []Completed, resps []ValkeyResult, fn func(ValkeyResult)) error
 	NextWriteCmd() (Completed, []Completed, chan ValkeyResult)
 	WaitForWrite() (Completed, []Completed, chan ValkeyResult)
 	NextResultCh() (Completed, []Completed, chan ValkeyResult, []ValkeyResult)
@@ -23,7 +26,7 @@ func newRing(factor int) *ring {
 	if factor <= 0 {
 		factor = DefaultRingScale
 	}
-	r := &ring{store: make([]node, 2<<(factor-1))}
+	r := &ring{store: make([]node, 2<<(factor-1)), ach: make(chan ValkeyResult, 1)}
 	r.mask = uint32(len(r.store) - 1)
 	for i := range r.store {
 		m := &sync.Mutex{}
@@ -36,7 +39,9 @@ func newRing(factor int) *ring {
 
 type ring struct {
 	resc  *sync.Cond
-	store []node // store's size must be 2^N to work with the mask
+	resf  func(ValkeyResult)
+	ach   chan ValkeyResult // receives results of async commands
+	store []node            // store's size must be 2^N to work with the mask
 	_     cpu.CacheLinePad
 	write uint32
 	_     cpu.CacheLinePad
@@ -52,6 +57,7 @@ type node struct {
 	one   Completed
 	multi []Completed
 	resps []ValkeyResult
+	fn    func(ValkeyResult)
 	mark  uint32
 	slept bool
 }
@@ -89,6 +95,25 @@ func (r *ring) PutMulti(_ context.Context, m []Completed, resps []ValkeyResult)
 	return n.ch, nil
 }
 
+func (r *ring) PutAsync(_ context.Context, one Completed, multi []Completed, resps []ValkeyResult, fn func(ValkeyResult)) error {
+	n := &r.store[atomic.AddUint32(&r.write, 1)&r.mask]
+	n.c1.L.Lock()
+	for n.mark != 0 {
+		n.c1.Wait()
+	}
+	n.one = one
+	n.multi = multi
+	n.resps = resps
+	n.fn = fn
+	n.mark = 1
+	s := n.slept
+	n.c1.L.Unlock()
+	if s {
+		n.c2.Broadcast()
+	}
+	return nil
+}
+
 // NextWriteCmd should be only called by one dedicated thread
 func (r *ring) NextWriteCmd() (one Completed, multi []Completed, ch chan ValkeyResult) {
 	r.read1++
@@ -131,10 +156,14 @@ func (r *ring) NextResultCh() (one Completed, multi []Completed, ch chan ValkeyR
 	n.c1.L.Lock()
 	if n.mark == 2 {
 		one, multi, ch, resps = n.one, n.multi, n.ch, n.resps
+		if n.fn != nil {
+			ch, r.resf = r.ach, n.fn
+		}
 		n.mark = 0
 		n.one = Completed{}
 		n.multi = nil
 		n.resps = nil
+		n.fn = nil
 	} else {
 		r.read2--
 	}
@@ -148,4 +177,8 @@ func (r *ring) FinishResult() {
 		r.resc.Signal()
 		r.resc = nil
 	}
+	if fn := r.resf; fn != nil {
+		r.resf = nil
+		fn(<-r.ach)
+	}
 }
@@ -108,6 +108,32 @@ func TestRing(t *testing.T) {
 		}
 	})
 
+	t.Run("PutAsync", func(t *testing.T) {
+		q := newRing(DefaultRingScale)
+		var got []ValkeyResult
+		fn := func(resp ValkeyResult) { got = append(got, resp) }
+		if err := q.PutAsync(context.Background(), cmds.NewCompleted([]string{"0"}), nil, nil, fn); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if err := q.PutAsync(context.Background(), Completed{}, cmds.NewMultiCompleted([][]string{{"1"}}), nil, fn); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		for i := range 2 {
+			if _, _, ch := q.NextWriteCmd(); ch == nil {
+				t.Fatalf("NextWriteCmd should returns next cmd")
+			}
+			_, _, ch, _ := q.NextResultCh()
+			if ch == nil || cap(ch) == 0 {
+				t.Fatalf("NextResultCh should returns a buffered channel for async cmds")
+			}
+			ch <- newResult(strmsg('+', strconv.Itoa(i)), nil)
+			q.FinishResult()
+			if len(got) != i+1 || got[i].val.string() != strconv.Itoa(i) {
+				t.Fatalf("FinishResult should pass the result to the fn")
+			}
+		}
+	})
+
 	t.Run("PutOne Wakeup WaitForWrite", func(t *testing.T) {
 		ring := newRing(DefaultRingScale)
 		if one, _, ch := ring.NextWriteCmd(); ch == nil {
@@ -136,3 +162,48 @@ func TestRing(t *testing.T) {
 		t.Fatal("Should sleep")
 	})
 }
+
+func benchmarkQueue(b *testing.B, q queue) {
+	stop := cmds.NewCompleted([]string{"stop"})
+	done := make(chan struct{})
+	go func() { // act as the writer and the reader of a pipe
+		defer close(done)
+		for {
+			one, _, ch := q.NextWriteCmd()
+			if ch == nil {
+				one, _, ch = q.WaitForWrite()
+			}
+			_, _, ch, _ = q.NextResultCh()
+			ch <- ValkeyResult{}
+			q.FinishResult()
+			if one.Commands()[0] == "stop" {
+				return
+			}
+		}
+	}()
+	cmd := cmds.NewCompleted([]string{"GET", "a"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ch, _ := q.PutOne(context.Background(), cmd)
+			<-ch
+		}
+	})
+	b.StopTimer()
+	ch, _ := q.PutOne(context.Background(), stop)
+	<-ch
+	<-done
+}
+
+func BenchmarkQueue(b *testing.B) {
+	b.Run("Ring", func(b *testing.B) {
+		benchmarkQueue(b, newRing(DefaultRingScale))
+	})
+	b.Run("FlowBuffer", func(b *testing.B) {
+		benchmarkQueue(b, newFlowBuffer(DefaultRingScale))
+	})
+	b.Run("MPSC", func(b *testing.B) {
+		benchmarkQueue(b, newMPSC(DefaultRingScale))
+	})
+}
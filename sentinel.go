@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"strings"
 	"sync"
@@ -48,6 +49,10 @@ func newSentinelClient(opt *ClientOption, connFn connFn, retryer retryHandler) (
 		return nil, err
 	}
 
+	if opt.ReplicaLag != nil && opt.SendToReplicas != nil {
+		client.lags = newLagSampler(opt.ReplicaLag, client.sampleLags)
+	}
+
 	return client, nil
 }
 
@@ -65,6 +70,10 @@ type sentinelClient struct {
 	rAddr        atomic.Value
 	sAddr        string
 	sc           call
+	topo         *topology
+	replicas     []string // the ready replicas in the last SENTINEL REPLICAS reply, only fetched for the OnTopologyChange or replica reads
+	lags         *sampler
+	rLag         atomic.Int64
 	mu           sync.Mutex
 	stop         uint32
 	cmd          Builder
@@ -78,18 +87,23 @@ func (c *sentinelClient) B() Builder {
 }
 
 func (c *sentinelClient) Do(ctx context.Context, cmd Completed) (resp ValkeyResult) {
+	return c.settle(ctx, cmd, c.pick(cmd).Do(ctx, cmd))
+}
+
+// settle resends the cmd until its resp is not retryable, and then recycles the cmd.
+func (c *sentinelClient) settle(ctx context.Context, cmd Completed, resp ValkeyResult) ValkeyResult {
 	attempts := 1
-retry:
-	cc := c.pick(cmd)
-	resp = cc.Do(ctx, cmd)
+process:
 	if err := resp.Error(); err != nil {
 		if err == errConnExpired {
-			goto retry
+			resp = c.pick(cmd).Do(ctx, cmd)
+			goto process
 		}
 		if c.retry && cmd.IsRetryable() && c.isRetryable(err, ctx) {
 			if c.retryHandler.WaitOrSkipRetry(ctx, attempts, cmd, err) {
 				attempts++
-				goto retry
+				resp = c.pick(cmd).Do(ctx, cmd)
+				goto process
 			}
 		}
 	}
@@ -99,22 +113,67 @@ retry:
 	return resp
 }
 
+// settled reports whether the settle of the resp can be done without resending the cmd.
+func (c *sentinelClient) settled(ctx context.Context, cmd Completed, resp ValkeyResult) bool {
+	err := resp.Error()
+	return err == nil || (err != errConnExpired && !(c.retry && cmd.IsRetryable() && c.isRetryable(err, ctx)))
+}
+
+func (c *sentinelClient) DoAsync(ctx context.Context, cmd Completed) *ValkeyFuture {
+	f := newFuture()
+	c.pick(cmd).DoAsync(ctx, cmd, func(resp ValkeyResult) {
+		if c.settled(ctx, cmd, resp) {
+			f.resolve(c.settle(ctx, cmd, resp))
+		} else {
+			go func() { f.resolve(c.settle(ctx, cmd, resp)) }()
+		}
+	})
+	return f
+}
+
+func (c *sentinelClient) DoMultiAsync(ctx context.Context, multi ...Completed) *ValkeyMultiFuture {
+	f := newMultiFuture(len(multi))
+	f.add(1)
+	if len(multi) == 0 {
+		f.resolve()
+		return f
+	}
+	sendToReplica := c.sendAllToReplica(multi)
+	cc := c.pickMulti(sendToReplica)
+	cc.DoMultiAsync(ctx, f.resps, func() {
+		if c.settledMulti(ctx, multi, f.resps) {
+			c.settleMulti(ctx, cc, sendToReplica, multi, f.resps)
+			f.resolve()
+		} else {
+			go func() {
+				copy(f.resps, c.settleMulti(ctx, cc, sendToReplica, multi, f.resps))
+				f.resolve()
+			}()
+		}
+	}, multi...)
+	return f
+}
+
 func (c *sentinelClient) DoMulti(ctx context.Context, multi ...Completed) []ValkeyResult {
 	if len(multi) == 0 {
 		return nil
 	}
 
-	attempts := 1
 	sendToReplica := c.sendAllToReplica(multi)
-retry:
 	cc := c.pickMulti(sendToReplica)
-	resps := cc.DoMulti(ctx, multi...)
+	return c.settleMulti(ctx, cc, sendToReplica, multi, cc.DoMulti(ctx, multi...).s)
+}
+
+// settleMulti resends the multi until its resps are not retryable, and then recycles the multi.
+func (c *sentinelClient) settleMulti(ctx context.Context, cc conn, sendToReplica bool, multi []Completed, resps []ValkeyResult) []ValkeyResult {
+	attempts := 1
+process:
 	if c.hasLftm {
 		var ml []Completed
 	recover:
 		ml = ml[:0]
 		var txIdx int // check transaction block, if zero, then not in transaction
-		for i, resp := range resps.s {
+		for i, resp := range resps {
 			if resp.NonValkeyError() == errConnExpired {
 				if txIdx > 0 {
 					ml = multi[txIdx:]
@@ -132,30 +191,42 @@ retry:
 		}
 		if len(ml) > 0 {
 			rs := cc.DoMulti(ctx, ml...).s
-			resps.s = append(resps.s[:len(resps.s)-len(rs)], rs...)
+			resps = append(resps[:len(resps)-len(rs)], rs...)
 			goto recover
 		}
 	}
 	if c.retry && allRetryable(multi) {
-		for i, resp := range resps.s {
+		for i, resp := range resps {
 			if c.isRetryable(resp.Error(), ctx) {
 				shouldRetry := c.retryHandler.WaitOrSkipRetry(
 					ctx, attempts, multi[i], resp.Error(),
 				)
 				if shouldRetry {
-					resultsp.Put(resps)
 					attempts++
-					goto retry
+					cc = c.pickMulti(sendToReplica)
+					resps = cc.DoMulti(ctx, multi...).s
+					goto process
 				}
 			}
 		}
 	}
 	for i, cmd := range multi {
-		if resps.s[i].NonValkeyError() == nil {
+		if resps[i].NonValkeyError() == nil {
 			cmds.PutCompleted(cmd)
 		}
 	}
-	return resps.s
+	return resps
+}
+
+// settledMulti reports whether the settleMulti of the resps can be done without resending any of the multi.
+func (c *sentinelClient) settledMulti(ctx context.Context, multi []Completed, resps []ValkeyResult) bool {
+	retry := c.retry && allRetryable(multi)
+	for _, resp := range resps {
+		if (c.hasLftm && resp.NonValkeyError() == errConnExpired) || (retry && c.isRetryable(resp.Error(), ctx)) {
+			return false
+		}
+	}
+	return true
 }
 
 func (c *sentinelClient) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) (resp ValkeyResult) {
@@ -319,12 +390,31 @@ func (c *sentinelClient) Nodes() map[string]Client {
 	}
 }
 
+func (c *sentinelClient) Stats() map[string]NodeStats {
+	switch {
+	case c.replica:
+		cc := c.rConn.Load().(conn)
+		return map[string]NodeStats{cc.Addr(): cc.Stats()}
+	case c.mOpt != nil && c.mOpt.SendToReplicas != nil:
+		master := c.mConn.Load().(conn)
+		replica := c.rConn.Load().(conn)
+		return map[string]NodeStats{
+			master.Addr():  master.Stats(),
+			replica.Addr(): replica.Stats(),
+		}
+	default:
+		cc := c.mConn.Load().(conn)
+		return map[string]NodeStats{cc.Addr(): cc.Stats()}
+	}
+}
+
 func (c *sentinelClient) Mode() ClientMode {
 	return ClientModeSentinel
 }
 
 func (c *sentinelClient) Close() {
 	atomic.StoreUint32(&c.stop, 1)
+	c.lags.Close()
 	c.mu.Lock()
 	if c.sConn != nil {
 		c.sConn.Close()
@@ -338,8 +428,26 @@ func (c *sentinelClient) Close() {
 	c.mu.Unlock()
 }
 
+func (c *sentinelClient) CloseWithContext(ctx context.Context) error {
+	atomic.StoreUint32(&c.stop, 1)
+	c.lags.Close()
+	conns := make([]conn, 0, 2)
+	c.mu.Lock()
+	if c.sConn != nil {
+		c.sConn.Close()
+	}
+	if master := c.mConn.Load(); master != nil {
+		conns = append(conns, master.(conn))
+	}
+	if replica := c.rConn.Load(); replica != nil {
+		conns = append(conns, replica.(conn))
+	}
+	c.mu.Unlock()
+	return closeWithContext(ctx, conns)
+}
+
 func (c *sentinelClient) isRetryable(err error, ctx context.Context) (should bool) {
-	if err == nil || err == Nil || err == ErrDoCacheAborted || atomic.LoadUint32(&c.stop) != 0 || ctx.Err() != nil {
+	if err == nil || err == Nil || err == ErrDoCacheAborted || err == ErrCircuitOpen || err == ErrOverloaded || atomic.LoadUint32(&c.stop) != 0 || ctx.Err() != nil {
 		return false
 	}
 	if err, ok := err.(*ValkeyError); ok {
@@ -368,7 +476,7 @@ func (c *sentinelClient) pick(cmd Completed) (cc conn) {
 	case c.replica:
 		cc = c.rConn.Load().(conn)
 	case c.mOpt.SendToReplicas != nil:
-		if c.mOpt.SendToReplicas(cmd) {
+		if c.mOpt.SendToReplicas(cmd) && c.fresh() {
 			cc = c.rConn.Load().(conn)
 		} else {
 			cc = c.mConn.Load().(conn)
@@ -384,7 +492,7 @@ func (c *sentinelClient) pickMulti(sendToReplica bool) (cc conn) {
 	case c.replica:
 		cc = c.rConn.Load().(conn)
 	case c.mOpt.SendToReplicas != nil:
-		if sendToReplica {
+		if sendToReplica && c.fresh() {
 			cc = c.rConn.Load().(conn)
 		} else {
 			cc = c.mConn.Load().(conn)
@@ -396,6 +504,18 @@ func (c *sentinelClient) pickMulti(sendToReplica bool) (cc conn) {
 	return cc
 }
 
+// fresh reports whether the replica lags behind no more than the ClientOption.ReplicaLag.MaxLag.
+func (c *sentinelClient) fresh() bool {
+	return c.mOpt.ReplicaLag == nil || c.rLag.Load() <= c.mOpt.ReplicaLag.MaxLag
+}
+
+func (c *sentinelClient) sampleLags(ctx context.Context) {
+	master, replica := c.mConn.Load(), c.rConn.Load()
+	if master != nil && replica != nil {
+		sampleLags(ctx, master.(conn), []NodeInfo{{conn: replica.(conn), lag: &c.rLag}})
+	}
+}
+
 func (c *sentinelClient) sendAllToReplica(cmds []Completed) bool {
 	if c.mOpt.SendToReplicas == nil {
 		return false
@@ -427,9 +547,12 @@ func (c *sentinelClient) sendAllToReplicaCache(cmds []CacheableTTL) bool {
 func (c *sentinelClient) switchTargetRetry(addr string, isMaster bool) {
 	c.mu.Lock()
 	err := c._switchTarget(addr, isMaster)
+	change := c.observe(err)
 	c.mu.Unlock()
 	if err != nil {
 		go c.refreshRetry()
+	} else if !change.IsEmpty() {
+		c.mOpt.OnTopologyChange(change)
 	}
 }
 
@@ -493,7 +616,9 @@ func (c *sentinelClient) _switchTarget(addr string, isMaster bool) (err error) {
 			return errNotSlave
 		}
 
-		c.rAddr.Store(addr)
+		if prev, _ := c.rAddr.Swap(addr).(string); prev != addr {
+			c.rLag.Store(math.MaxInt64) // not sampled yet
+		}
 
 		if old := c.rConn.Swap(target); old != nil {
 			if prev := old.(conn); prev != target {
@@ -505,6 +630,29 @@ func (c *sentinelClient) _switchTarget(addr string, isMaster bool) (err error) {
 	return nil
 }
 
+// observe diffs the current primary and replicas with the last observed ones if the switching err is nil.
+// The replicas are the ones listed by the sentinel in the last refresh, so a replica promoted by a +switch-master is
+// reported as promoted, while the old primary is reported as removed until the sentinel lists it as a replica again.
+// It must be called with the c.mu held.
+func (c *sentinelClient) observe(err error) (change TopologyChange) {
+	if err != nil || c.mOpt.OnTopologyChange == nil {
+		return change
+	}
+	topo := &topology{nodes: make(map[string]TopologyNode, len(c.replicas)+2)}
+	for _, addr := range c.replicas {
+		topo.nodes[addr] = TopologyNode{Addr: addr}
+	}
+	if addr, ok := c.rAddr.Load().(string); ok {
+		topo.nodes[addr] = TopologyNode{Addr: addr}
+	}
+	if addr, ok := c.mAddr.Load().(string); ok {
+		topo.nodes[addr] = TopologyNode{Addr: addr, Primary: true}
+	}
+	change = diffTopology(c.topo, topo)
+	c.topo = topo
+	return change
+}
+
 func (c *sentinelClient) refreshRetry() {
 retry:
 	if err := c.refresh(); err != nil {
@@ -581,8 +729,13 @@ func (c *sentinelClient) _refresh() (err error) {
 			break
 		}
 	}
+	change := c.observe(err)
 	c.mu.Unlock()
 
+	if !change.IsEmpty() {
+		c.mOpt.OnTopologyChange(change)
+	}
+
 	if err == nil {
 		if c.replica {
 			if replica := c.rConn.Load(); replica == nil {
@@ -654,7 +807,7 @@ func (c *sentinelClient) listWatch(cc conn) (master string, replica string, sent
 	var commands Commands
 	if c.replica {
 		commands = Commands{sentinelsCMD, replicasCMD}
-	} else if c.mOpt.SendToReplicas != nil {
+	} else if c.mOpt.SendToReplicas != nil || c.mOpt.OnTopologyChange != nil {
 		commands = Commands{sentinelsCMD, getMasterCMD, replicasCMD}
 	} else {
 		commands = Commands{sentinelsCMD, getMasterCMD}
@@ -674,6 +827,7 @@ func (c *sentinelClient) listWatch(cc conn) (master string, replica string, sent
 
 	// we return a random slave address instead of master
 	if c.replica {
+		c.replicas, _ = readyReplicas(resp.s[1])
 		addr, err := pickReplica(resp.s[1])
 		if err != nil {
 			return "", "", nil, err
@@ -683,6 +837,9 @@ func (c *sentinelClient) listWatch(cc conn) (master string, replica string, sent
 	}
 
 	var r string
+	if len(commands) == 3 {
+		c.replicas, _ = readyReplicas(resp.s[2])
+	}
 	if c.mOpt.SendToReplicas != nil {
 		addr, err := pickReplica(resp.s[2])
 		if err != nil {
@@ -700,30 +857,37 @@ func (c *sentinelClient) listWatch(cc conn) (master string, replica string, sent
 }
 
 func pickReplica(resp ValkeyResult) (string, error) {
-	replicas, err := resp.ToArray()
+	eligible, err := readyReplicas(resp)
 	if err != nil {
 		return "", err
 	}
 
-	eligible := make([]map[string]string, 0, len(replicas))
-	// eliminate replicas with the s_down condition
+	if len(eligible) == 0 {
+		return "", fmt.Errorf("not enough ready replicas")
+	}
+
+	// choose a replica randomly
+	return eligible[util.FastRand(len(eligible))], nil
+}
+
+// readyReplicas returns the addresses of the replicas in the SENTINEL REPLICAS reply without the s_down condition.
+func readyReplicas(resp ValkeyResult) ([]string, error) {
+	replicas, err := resp.ToArray()
+	if err != nil {
+		return nil, err
+	}
+
+	eligible := make([]string, 0, len(replicas))
 	for i := range replicas {
 		replica, err := replicas[i].AsStrMap()
 		if err != nil {
 			continue
 		}
 		if _, ok := replica["s-down-time"]; !ok {
-			eligible = append(eligible, replica)
+			eligible = append(eligible, net.JoinHostPort(replica["ip"], replica["port"]))
 		}
 	}
-
-	if len(eligible) == 0 {
-		return "", fmt.Errorf("not enough ready replicas")
-	}
-
-	// choose a replica randomly
-	m := eligible[util.FastRand(len(eligible))]
-	return net.JoinHostPort(m["ip"], m["port"]), nil
+	return eligible, nil
 }
 
 func newSentinelOpt(opt *ClientOption) *ClientOption {
@@ -733,7 +897,8 @@ func newSentinelOpt(opt *ClientOption) *ClientOption {
 	o.ClientName = o.Sentinel.ClientName
 	o.Dialer = o.Sentinel.Dialer
 	o.TLSConfig = o.Sentinel.TLSConfig
-	o.SelectDB = 0 // https://github.com/redis/rueidis/issues/138
+	o.SelectDB = 0            // https://github.com/redis/rueidis/issues/138
+	o.BlockingPoolMinIdle = 0 // blocking commands are never sent to sentinels
 	return &o
 }
 
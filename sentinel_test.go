@@ -1143,6 +1143,12 @@ func TestSentinelClientDelegate(t *testing.T) {
 		}
 	})
 
+	t.Run("Stats", func(t *testing.T) {
+		if stats := client.Stats(); len(stats) != 1 {
+			t.Fatalf("unexpected stats %v", stats)
+		}
+	})
+
 	t.Run("Mode", func(t *testing.T) {
 		if mode := client.Mode(); mode != ClientModeSentinel {
 			t.Fatalf("unexpected mode %v", mode)
@@ -1255,6 +1261,15 @@ func TestSentinelClientDelegate(t *testing.T) {
 		}
 	})
 
+	t.Run("Delegate CloseWithContext", func(t *testing.T) {
+		e := errors.New("ctx")
+		m.CloseCtxFn = func(ctx context.Context) error { return e }
+		if err := client.CloseWithContext(context.Background()); err != e {
+			t.Fatalf("CloseWithContext is not delegated")
+		}
+		m.CloseCtxFn = nil
+	})
+
 	t.Run("Delegate Close", func(t *testing.T) {
 		called := false
 		m.CloseFn = func() { called = true }
@@ -2565,6 +2580,20 @@ func TestSendToReplicasSentinelClientDelegate(t *testing.T) {
 		}
 	})
 
+	t.Run("Stats", func(t *testing.T) {
+		client, m, r := setup()
+		defer client.Close()
+
+		m.AddrFn = func() string { return "127.0.1.0:10" }
+		r.AddrFn = func() string { return "127.0.1.1:11" }
+		r.StatsFn = func() NodeStats { return NodeStats{Reconnects: 1} }
+
+		stats := client.Stats()
+		if _, ok := stats["127.0.1.0:10"]; len(stats) != 2 || !ok || stats["127.0.1.1:11"].Reconnects != 1 {
+			t.Fatalf("unexpected stats %v", stats)
+		}
+	})
+
 	t.Run("Delegate Do to master", func(t *testing.T) {
 		client, m, _ := setup()
 		defer client.Close()
@@ -3576,3 +3605,81 @@ func TestSentinelSendToReplicasClientPubSub(t *testing.T) {
 		time.Sleep(time.Millisecond * 100)
 	}
 }
+
+func TestSentinelClientOnTopologyChange(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+
+	messages := make(chan PubSubMessage)
+	changes := make(chan TopologyChange, 2)
+
+	s0 := &mockConn{
+		DoMultiFn: func(multi ...Completed) *valkeyresults {
+			return &valkeyresults{s: []ValkeyResult{
+				{val: slicemsg('*', []ValkeyMessage{})},
+				{val: slicemsg('*', []ValkeyMessage{
+					strmsg('+', ""), strmsg('+', "1"),
+				})},
+				{val: slicemsg('*', []ValkeyMessage{
+					slicemsg('%', []ValkeyMessage{
+						strmsg('+', "ip"), strmsg('+', ""),
+						strmsg('+', "port"), strmsg('+', "4"),
+					}),
+				})},
+			}}
+		},
+		ReceiveFn: func(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
+			for msg := range messages {
+				fn(msg)
+			}
+			return ErrClosing
+		},
+	}
+	master := func() *mockConn {
+		return &mockConn{
+			DoFn: func(cmd Completed) ValkeyResult {
+				return ValkeyResult{val: slicemsg('*', []ValkeyMessage{strmsg('+', "master")})}
+			},
+		}
+	}
+	m1, m4 := master(), master()
+
+	client, err := newSentinelClient(
+		&ClientOption{
+			InitAddress:      []string{":0"},
+			Sentinel:         SentinelOption{MasterSet: "test"},
+			OnTopologyChange: func(change TopologyChange) { changes <- change },
+		},
+		func(dst string, opt *ClientOption) conn {
+			switch dst {
+			case ":0":
+				return s0
+			case ":1":
+				return m1
+			case ":4":
+				return m4
+			}
+			return nil
+		},
+		newRetryer(defaultRetryDelayFn),
+	)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+
+	if change := <-changes; !reflect.DeepEqual(change, TopologyChange{
+		Added: []TopologyNode{{Addr: ":1", Primary: true}, {Addr: ":4"}},
+	}) {
+		t.Fatalf("unexpected change %v", change)
+	}
+
+	messages <- PubSubMessage{Channel: "+switch-master", Message: "test  1  4"}
+
+	if change := <-changes; !reflect.DeepEqual(change, TopologyChange{
+		Removed:  []TopologyNode{{Addr: ":1", Primary: true}},
+		Promoted: []TopologyNode{{Addr: ":4", Primary: true}},
+	}) {
+		t.Fatalf("unexpected change %v", change)
+	}
+	close(messages)
+}
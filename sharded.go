@@ -0,0 +1,685 @@
+package valkey
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"io"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valkey-io/valkey-go/internal/cmds"
+)
+
+const (
+	defaultShardHealthCheckInterval = time.Second
+	defaultShardEjectAfter          = 3
+)
+
+var (
+	errCrossShard      = errors.New("cross shard command in Dedicated is prohibited")
+	errCrossShardMulti = errors.New("cross shard transaction is prohibited")
+	errKeylessStream   = errors.New("keyless command in DoStream of a sharded client is not supported")
+)
+
+const (
+	keylessGroup    = -1 // the group of the keyless commands sent to all shards by the fanout
+	crossShardGroup = -2 // the group of the transactions across shards
+)
+
+type shard struct {
+	client Client
+	seed   uint64 // the hash of the primary address for the rendezvous hashing
+	fails  int    // the number of consecutive failed health checks
+	down   bool
+}
+
+type shardedClient struct {
+	cmd    Builder
+	health *sampler
+	ring   atomic.Pointer[[16384]uint16] // the index of the shard owning each slot
+	shards []*shard
+	mu     sync.Mutex // serializes health checks
+	eject  int
+}
+
+func newShardedClient(opt *ClientOption, connFn connFn, retryer retryHandler) (*shardedClient, error) {
+	c := &shardedClient{cmd: cmds.NewBuilder(cmds.InitSlot), shards: make([]*shard, 0, len(opt.Sharded.Shards)), eject: opt.Sharded.EjectAfter}
+	if c.eject <= 0 {
+		c.eject = defaultShardEjectAfter
+	}
+	for _, addrs := range opt.Sharded.Shards {
+		client, err := newShard(opt, addrs, connFn, retryer)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.shards = append(c.shards, &shard{client: client, seed: shardSeed(addrs[0])})
+	}
+	c.ring.Store(c.build())
+
+	interval := opt.Sharded.HealthCheckInterval
+	if interval <= 0 {
+		interval = defaultShardHealthCheckInterval
+	}
+	c.health = newSampler(interval, false, c.check)
+	return c, nil
+}
+
+// newShard creates the client of a shard. The first one of the addrs is the primary, and the rest are replicas.
+func newShard(opt *ClientOption, addrs []string, connFn connFn, retryer retryHandler) (Client, error) {
+	if len(addrs) == 0 {
+		return nil, ErrNoAddr
+	}
+	o := *opt
+	o.Sharded = ShardedOption{}
+	o.InitAddress = addrs[:1]
+	o.Standalone.ReplicaAddress = addrs[1:]
+	o.PipelineMultiplex = singleClientMultiplex(o.PipelineMultiplex)
+	if len(o.Standalone.ReplicaAddress) > 0 {
+		if o.SendToReplicas == nil {
+			return nil, ErrNoSendToReplicas
+		}
+		return newStandaloneClient(&o, connFn, retryer)
+	}
+	return newSingleClient(&o, nil, connFn, retryer)
+}
+
+func shardSeed(addr string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(addr))
+	return h.Sum64()
+}
+
+// rendezvous returns the score of the slot on the shard. The slot is owned by the shard with the highest score.
+func rendezvous(seed uint64, slot uint16) uint64 {
+	h := seed ^ (uint64(slot)+1)*0x9e3779b97f4a7c15
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// build assigns each slot to the shard with the highest rendezvous score among the shards not ejected.
+// All the shards are considered if all of them are ejected.
+func (c *shardedClient) build() *[16384]uint16 {
+	live := make([]int, 0, len(c.shards))
+	for i, s := range c.shards {
+		if !s.down {
+			live = append(live, i)
+		}
+	}
+	if len(live) == 0 {
+		for i := range c.shards {
+			live = append(live, i)
+		}
+	}
+	ring := &[16384]uint16{}
+	for slot := range ring {
+		var best uint64
+		for j, i := range live {
+			if score := rendezvous(c.shards[i].seed, uint16(slot)); j == 0 || score > best {
+				best, ring[slot] = score, uint16(i)
+			}
+		}
+	}
+	return ring
+}
+
+// check pings all the shards and rebuilds the ring if any of them is ejected or put back.
+func (c *shardedClient) check(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	errs := make([]error, len(c.shards))
+	var wg sync.WaitGroup
+	wg.Add(len(c.shards))
+	for i, s := range c.shards {
+		go func(i int, cc Client) {
+			defer wg.Done()
+			errs[i] = cc.Do(ctx, cc.B().Ping().Build()).Error()
+		}(i, s.client)
+	}
+	wg.Wait()
+	changed := false
+	for i, s := range c.shards {
+		if errs[i] == nil {
+			s.fails = 0
+		} else {
+			s.fails++
+		}
+		if down := s.fails >= c.eject; down != s.down {
+			s.down = down
+			changed = true
+		}
+	}
+	if changed {
+		c.ring.Store(c.build())
+	}
+}
+
+// index returns the index of the shard owning the slot. Keyless slots are mapped to the owner of slot 0.
+func (c *shardedClient) index(slot uint16) int {
+	if slot >= 16384 {
+		slot = 0
+	}
+	return int(c.ring.Load()[slot])
+}
+
+func (c *shardedClient) pick(slot uint16) Client {
+	return c.shards[c.index(slot)].client
+}
+
+// groupShards returns the indexes of the multi grouped by the shards owning their slots.
+func groupShards[T any](c *shardedClient, multi []T, slot func(T) uint16) map[int][]int {
+	groups := make(map[int][]int, len(c.shards))
+	for i, cmd := range multi {
+		s := c.index(slot(cmd))
+		groups[s] = append(groups[s], i)
+	}
+	return groups
+}
+
+// groupMulti is the groupShards for the Completed commands. Keyless commands are grouped under the keylessGroup,
+// except in a transaction, whose commands all follow the shard of its first keyed command.
+// A transaction with keys in different shards is grouped under the crossShardGroup.
+func (c *shardedClient) groupMulti(multi []Completed) map[int][]int {
+	groups := make(map[int][]int, len(c.shards))
+	for i := 0; i < len(multi); i++ {
+		if !isMulti(multi[i]) {
+			s := keylessGroup
+			if slot := multi[i].Slot(); slot < 16384 {
+				s = c.index(slot)
+			}
+			groups[s] = append(groups[s], i)
+			continue
+		}
+		end := i
+		for end < len(multi)-1 && !isExec(multi[end]) {
+			end++
+		}
+		s := c.index(cmds.InitSlot)
+		for k, slot := i, uint16(cmds.InitSlot); k <= end; k++ {
+			if ks := multi[k].Slot(); ks < 16384 {
+				if slot == cmds.InitSlot {
+					slot, s = ks, c.index(ks)
+				} else if c.index(ks) != s {
+					s = crossShardGroup
+					break
+				}
+			}
+		}
+		for k := i; k <= end; k++ {
+			groups[s] = append(groups[s], k)
+		}
+		i = end
+	}
+	return groups
+}
+
+// doSharded sends each group of the multi to its shard concurrently and returns the results in the order of the multi.
+func doSharded[T any](c *shardedClient, multi []T, slot func(T) uint16, do func(cc Client, multi []T) []ValkeyResult) []ValkeyResult {
+	if len(multi) == 0 {
+		return nil
+	}
+	groups := groupShards(c, multi, slot)
+	if len(groups) == 1 {
+		for s := range groups {
+			return do(c.shards[s].client, multi)
+		}
+	}
+	resps := make([]ValkeyResult, len(multi))
+	var wg sync.WaitGroup
+	wg.Add(len(groups))
+	for s, indexes := range groups {
+		go func(cc Client, indexes []int) {
+			defer wg.Done()
+			part := make([]T, len(indexes))
+			for i, j := range indexes {
+				part[i] = multi[j]
+			}
+			for i, resp := range do(cc, part) {
+				resps[indexes[i]] = resp
+			}
+		}(c.shards[s].client, indexes)
+	}
+	wg.Wait()
+	return resps
+}
+
+// fanout sends the keyless cmd to all shards concurrently and merges their replies by the response_policy of the cmd.
+// Commands without a response_policy, such as PING, return the first error reply if any, otherwise the reply of the first shard.
+func (c *shardedClient) fanout(ctx context.Context, cmd Completed) ValkeyResult {
+	cmd = cmd.Pin() // the cmd is shared by all shards, so it must not be recycled by any of them.
+	resps := make([]ValkeyResult, len(c.shards))
+	var wg sync.WaitGroup
+	wg.Add(len(c.shards))
+	for i, s := range c.shards {
+		go func(i int, cc Client) {
+			defer wg.Done()
+			resps[i] = cc.Do(ctx, cmd)
+		}(i, s.client)
+	}
+	wg.Wait()
+	policy := cmds.AllSucceeded
+	if isBroadcast(cmd) {
+		policy = cmd.ResponsePolicy()
+	}
+	return aggregate(policy, resps)
+}
+
+func cacheableSlot(cmd CacheableTTL) uint16 {
+	return cmd.Cmd.Slot()
+}
+
+func (c *shardedClient) B() Builder {
+	return c.cmd
+}
+
+func (c *shardedClient) Do(ctx context.Context, cmd Completed) ValkeyResult {
+	if cmd.Slot() >= 16384 {
+		return c.fanout(ctx, cmd)
+	}
+	return c.pick(cmd.Slot()).Do(ctx, cmd)
+}
+
+func (c *shardedClient) DoMulti(ctx context.Context, multi ...Completed) []ValkeyResult {
+	if len(multi) == 0 {
+		return nil
+	}
+	groups := c.groupMulti(multi)
+	if len(groups) == 1 {
+		for s := range groups {
+			if s >= 0 {
+				return c.shards[s].client.DoMulti(ctx, multi...)
+			}
+		}
+	}
+	resps := make([]ValkeyResult, len(multi))
+	var wg sync.WaitGroup
+	for s, indexes := range groups {
+		switch s {
+		case crossShardGroup:
+			for _, i := range indexes {
+				resps[i] = newErrResult(errCrossShardMulti)
+			}
+		case keylessGroup:
+			wg.Add(len(indexes))
+			for _, i := range indexes {
+				go func(i int) {
+					defer wg.Done()
+					resps[i] = c.fanout(ctx, multi[i])
+				}(i)
+			}
+		default:
+			wg.Add(1)
+			go func(cc Client, indexes []int) {
+				defer wg.Done()
+				part := make([]Completed, len(indexes))
+				for i, j := range indexes {
+					part[i] = multi[j]
+				}
+				for i, resp := range cc.DoMulti(ctx, part...) {
+					resps[indexes[i]] = resp
+				}
+			}(c.shards[s].client, indexes)
+		}
+	}
+	wg.Wait()
+	return resps
+}
+
+func (c *shardedClient) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) ValkeyResult {
+	return c.pick(cmd.Slot()).DoCache(ctx, cmd, ttl)
+}
+
+func (c *shardedClient) DoMultiCache(ctx context.Context, multi ...CacheableTTL) []ValkeyResult {
+	return doSharded(c, multi, cacheableSlot, func(cc Client, multi []CacheableTTL) []ValkeyResult {
+		return cc.DoMultiCache(ctx, multi...)
+	})
+}
+
+func (c *shardedClient) DoAsync(ctx context.Context, cmd Completed) *ValkeyFuture {
+	if cmd.Slot() >= 16384 {
+		f := newFuture()
+		go func() {
+			f.resolve(c.fanout(ctx, cmd))
+		}()
+		return f
+	}
+	return c.pick(cmd.Slot()).DoAsync(ctx, cmd)
+}
+
+func (c *shardedClient) DoMultiAsync(ctx context.Context, multi ...Completed) *ValkeyMultiFuture {
+	groups := c.groupMulti(multi)
+	if len(groups) == 1 {
+		for s := range groups {
+			if s >= 0 {
+				return c.shards[s].client.DoMultiAsync(ctx, multi...)
+			}
+		}
+	}
+	f := newMultiFuture(len(multi))
+	f.add(1)
+	for s, indexes := range groups {
+		switch s {
+		case crossShardGroup:
+			for _, i := range indexes {
+				f.resps[i] = newErrResult(errCrossShardMulti)
+			}
+		case keylessGroup:
+			f.add(len(indexes))
+			for _, i := range indexes {
+				go func(i int) {
+					f.resps[i] = c.fanout(ctx, multi[i])
+					f.resolve()
+				}(i)
+			}
+		default:
+			f.add(1)
+			part := make([]Completed, len(indexes))
+			for i, j := range indexes {
+				part[i] = multi[j]
+			}
+			c.shards[s].client.DoMultiAsync(ctx, part...).OnDone(func(resps []ValkeyResult) {
+				for i, resp := range resps {
+					f.resps[indexes[i]] = resp
+				}
+				f.resolve()
+			})
+		}
+	}
+	f.resolve()
+	return f
+}
+
+func (c *shardedClient) DoStream(ctx context.Context, cmd Completed) ValkeyResultStream {
+	if cmd.Slot() >= 16384 {
+		return ValkeyResultStream{e: errKeylessStream}
+	}
+	return c.pick(cmd.Slot()).DoStream(ctx, cmd)
+}
+
+func (c *shardedClient) DoMultiStream(ctx context.Context, multi ...Completed) MultiValkeyResultStream {
+	if len(multi) == 0 {
+		return ValkeyResultStream{e: io.EOF}
+	}
+	groups := c.groupMulti(multi)
+	if len(groups) == 1 {
+		for s := range groups {
+			if s >= 0 {
+				return c.shards[s].client.DoMultiStream(ctx, multi...)
+			}
+		}
+	}
+	panic("DoMultiStream across multiple shards is not supported")
+}
+
+// Receive subscribes on the shard owning the slot of the subscribe. Keyless SUBSCRIBE and PSUBSCRIBE go to the owner of slot 0,
+// which also receives the keyless PUBLISH sent to all shards by the fanout.
+func (c *shardedClient) Receive(ctx context.Context, subscribe Completed, fn func(msg PubSubMessage)) error {
+	return c.pick(subscribe.Slot()).Receive(ctx, subscribe, fn)
+}
+
+func (c *shardedClient) Dedicated(fn func(DedicatedClient) error) (err error) {
+	dsc := &dedicatedShardedClient{cmd: c.cmd, client: c}
+	err = fn(dsc)
+	dsc.release()
+	return err
+}
+
+func (c *shardedClient) Dedicate() (DedicatedClient, func()) {
+	dsc := &dedicatedShardedClient{cmd: c.cmd, client: c}
+	return dsc, dsc.release
+}
+
+func (c *shardedClient) Nodes() map[string]Client {
+	nodes := make(map[string]Client, len(c.shards))
+	for _, s := range c.shards {
+		maps.Copy(nodes, s.client.Nodes())
+	}
+	return nodes
+}
+
+func (c *shardedClient) Stats() map[string]NodeStats {
+	stats := make(map[string]NodeStats, len(c.shards))
+	for _, s := range c.shards {
+		maps.Copy(stats, s.client.Stats())
+	}
+	return stats
+}
+
+// SlotOwner returns the address of the primary of the shard owning the slot.
+func (c *shardedClient) SlotOwner(slot uint16) (addr string, ok bool) {
+	if slot >= 16384 {
+		return "", false
+	}
+	return SlotOwner(c.pick(slot), slot)
+}
+
+// ShardScanner returns a Scanner that runs the SCAN family command built by the build against the primary of every shard exactly once.
+// If the key is not empty, only the shard owning the key is scanned. Ejected shards are skipped, as their slots are owned by others.
+func (c *shardedClient) ShardScanner(ctx context.Context, key string, build func(cursor uint64) []string) *Scanner {
+	var shards []int
+	return newShardScanner(func(i int, cursor uint64) (e ScanEntry, ok bool, err error) {
+		if i == 0 && cursor == 0 { // (re)start from the first shard
+			if key != "" {
+				shards = []int{c.index(cmds.Slot(key))}
+			} else {
+				shards = c.live()
+			}
+		}
+		if i >= len(shards) {
+			return e, false, nil
+		}
+		// the cmd is not read-only, so that it is not sent to the replicas of the shard.
+		e, err = c.shards[shards[i]].client.Do(ctx, cmds.NewCompleted(build(cursor))).AsScanEntry()
+		return e, true, err
+	})
+}
+
+// live returns the indexes of the shards owning any slot in the ring.
+func (c *shardedClient) live() []int {
+	owned := make([]bool, len(c.shards))
+	for _, s := range c.ring.Load() {
+		owned[s] = true
+	}
+	shards := make([]int, 0, len(c.shards))
+	for i, ok := range owned {
+		if ok {
+			shards = append(shards, i)
+		}
+	}
+	return shards
+}
+
+// Mode returns ClientModeStandalone because each shard is an independent standalone valkey.
+func (c *shardedClient) Mode() ClientMode {
+	return ClientModeStandalone
+}
+
+func (c *shardedClient) Close() {
+	c.health.Close()
+	for _, s := range c.shards {
+		s.client.Close()
+	}
+}
+
+func (c *shardedClient) CloseWithContext(ctx context.Context) error {
+	c.health.Close()
+	clients := make([]Client, len(c.shards))
+	for i, s := range c.shards {
+		clients[i] = s.client
+	}
+	return closeWithContext(ctx, clients)
+}
+
+// dedicatedShardedClient acquires a DedicatedClient from the shard owning the slot of the first command.
+type dedicatedShardedClient struct {
+	client *shardedClient
+	dc     DedicatedClient
+	cancel func()
+	pshks  *pshks
+	cmd    Builder
+	mu     sync.Mutex
+	shard  int
+	mark   bool
+}
+
+func (c *dedicatedShardedClient) acquire(slot uint16) (DedicatedClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mark {
+		return nil, ErrDedicatedClientRecycled
+	}
+	if c.dc != nil {
+		if slot < 16384 && c.client.index(slot) != c.shard {
+			return nil, errCrossShard
+		}
+		return c.dc, nil
+	}
+	c.shard = c.client.index(slot)
+	c.dc, c.cancel = c.client.shards[c.shard].client.Dedicate()
+	if p := c.pshks; p != nil {
+		c.pshks = nil
+		ch := c.dc.SetPubSubHooks(p.hooks)
+		go func(ch <-chan error) {
+			for e := range ch {
+				p.close <- e
+			}
+			close(p.close)
+		}(ch)
+	}
+	return c.dc, nil
+}
+
+// acquireMulti acquires the DedicatedClient by the first keyed command and checks that the others are in the same shard.
+func (c *dedicatedShardedClient) acquireMulti(multi []Completed) (DedicatedClient, error) {
+	slot := uint16(cmds.InitSlot)
+	for _, cmd := range multi {
+		if s := cmd.Slot(); s < 16384 {
+			if slot == cmds.InitSlot {
+				slot = s
+			} else if c.client.index(s) != c.client.index(slot) {
+				return nil, errCrossShard
+			}
+		}
+	}
+	return c.acquire(slot)
+}
+
+func (c *dedicatedShardedClient) release() {
+	c.mu.Lock()
+	if !c.mark {
+		if p := c.pshks; p != nil {
+			c.pshks = nil
+			close(p.close)
+		}
+		if c.cancel != nil {
+			c.cancel()
+		}
+	}
+	c.mark = true
+	c.mu.Unlock()
+}
+
+func (c *dedicatedShardedClient) B() Builder {
+	return c.cmd
+}
+
+func (c *dedicatedShardedClient) Do(ctx context.Context, cmd Completed) ValkeyResult {
+	dc, err := c.acquire(cmd.Slot())
+	if err != nil {
+		return newErrResult(err)
+	}
+	return dc.Do(ctx, cmd)
+}
+
+func (c *dedicatedShardedClient) DoMulti(ctx context.Context, multi ...Completed) []ValkeyResult {
+	if len(multi) == 0 {
+		return nil
+	}
+	dc, err := c.acquireMulti(multi)
+	if err != nil {
+		return fillErrs(len(multi), err)
+	}
+	return dc.DoMulti(ctx, multi...)
+}
+
+func (c *dedicatedShardedClient) Receive(ctx context.Context, subscribe Completed, fn func(msg PubSubMessage)) error {
+	dc, err := c.acquire(subscribe.Slot())
+	if err != nil {
+		return err
+	}
+	return dc.Receive(ctx, subscribe, fn)
+}
+
+func (c *dedicatedShardedClient) SetPubSubHooks(hooks PubSubHooks) <-chan error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mark {
+		ch := make(chan error, 1)
+		ch <- ErrDedicatedClientRecycled
+		return ch
+	}
+	if p := c.pshks; p != nil {
+		c.pshks = nil
+		close(p.close)
+	}
+	if c.dc != nil {
+		return c.dc.SetPubSubHooks(hooks)
+	}
+	if hooks.isZero() {
+		return nil
+	}
+	ch := make(chan error, 1)
+	c.pshks = &pshks{hooks: hooks, close: ch}
+	return ch
+}
+
+func (c *dedicatedShardedClient) SetOnInvalidations(fn func([]ValkeyMessage)) <-chan error {
+	c.mu.Lock()
+	if dc := c.dc; dc != nil && !c.mark {
+		c.mu.Unlock()
+		return dc.SetOnInvalidations(fn)
+	}
+	var hooks PubSubHooks
+	if c.pshks != nil {
+		hooks = c.pshks.hooks
+	}
+	c.mu.Unlock()
+	hooks.onInvalidations = fn
+	return c.SetPubSubHooks(hooks)
+}
+
+func (c *dedicatedShardedClient) Close() {
+	c.mu.Lock()
+	if p := c.pshks; p != nil {
+		c.pshks = nil
+		p.close <- ErrClosing
+		close(p.close)
+	}
+	if c.dc != nil && !c.mark {
+		c.dc.Close()
+	}
+	c.mu.Unlock()
+	c.release()
+}
+
+func (c *dedicatedShardedClient) CloseWithContext(ctx context.Context) (err error) {
+	c.mu.Lock()
+	if p := c.pshks; p != nil {
+		c.pshks = nil
+		p.close <- ErrClosing
+		close(p.close)
+	}
+	if c.dc != nil && !c.mark {
+		err = c.dc.CloseWithContext(ctx)
+	}
+	c.mu.Unlock()
+	c.release()
+	return err
+}
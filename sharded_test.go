@@ -0,0 +1,384 @@
+package valkey
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type shardedMock struct {
+	down  sync.Map
+	calls sync.Map
+}
+
+func (m *shardedMock) connFn(dst string, opt *ClientOption) conn {
+	count := func() {
+		v, _ := m.calls.LoadOrStore(dst, &atomic.Int64{})
+		v.(*atomic.Int64).Add(1)
+	}
+	reply := func(cmd Completed) ValkeyResult {
+		if cmd.Commands()[0] == "PING" {
+			if _, ok := m.down.Load(dst); ok {
+				return newErrResult(errors.New("down"))
+			}
+			return newResult(strmsg('+', "PONG"), nil)
+		}
+		switch cmd.Commands()[0] {
+		case "DBSIZE":
+			return newResult(ValkeyMessage{typ: typeInteger, intlen: 1}, nil)
+		case "SCAN", "HSCAN":
+			return newResult(slicemsg('*', []ValkeyMessage{strmsg('+', "0"), slicemsg('*', []ValkeyMessage{strmsg('+', dst)})}), nil)
+		}
+		return newResult(strmsg('+', dst), nil)
+	}
+	return &mockConn{
+		AddrFn: func() string { return dst },
+		DoFn: func(cmd Completed) ValkeyResult {
+			return reply(cmd)
+		},
+		DoCacheFn: func(cmd Cacheable, ttl time.Duration) ValkeyResult {
+			return newResult(strmsg('+', dst), nil)
+		},
+		DoMultiFn: func(multi ...Completed) *valkeyresults {
+			count()
+			resps := make([]ValkeyResult, len(multi))
+			for i, cmd := range multi {
+				resps[i] = reply(cmd)
+			}
+			return &valkeyresults{s: resps}
+		},
+		DoMultiCacheFn: func(multi ...CacheableTTL) *valkeyresults {
+			count()
+			resps := make([]ValkeyResult, len(multi))
+			for i := range multi {
+				resps[i] = newResult(strmsg('+', dst), nil)
+			}
+			return &valkeyresults{s: resps}
+		},
+		AcquireFn: func() wire {
+			return &mockWire{DoFn: func(cmd Completed) ValkeyResult { return newResult(strmsg('+', dst), nil) }}
+		},
+	}
+}
+
+func (m *shardedMock) calledOnce(t *testing.T, addrs ...string) {
+	t.Helper()
+	for _, addr := range addrs {
+		if v, ok := m.calls.LoadAndDelete(addr); !ok || v.(*atomic.Int64).Load() != 1 {
+			t.Fatalf("%v should be called once", addr)
+		}
+	}
+}
+
+func newShardedTestClient(t *testing.T, m *shardedMock) *shardedClient {
+	client, err := newShardedClient(&ClientOption{
+		Sharded: ShardedOption{
+			Shards:              [][]string{{"127.0.0.1:1"}, {"127.0.0.1:2"}, {"127.0.0.1:3"}},
+			HealthCheckInterval: time.Hour,
+			EjectAfter:          2,
+		},
+	}, m.connFn, newRetryer(defaultRetryDelayFn))
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	return client
+}
+
+func owners(client *shardedClient, keys []string) map[string]string {
+	ret := make(map[string]string, len(keys))
+	for _, key := range keys {
+		ret[key], _ = client.Do(context.Background(), client.B().Get().Key(key).Build()).ToString()
+	}
+	return ret
+}
+
+func TestShardedClientRouting(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	m := &shardedMock{}
+	client := newShardedTestClient(t, m)
+	defer client.Close()
+
+	keys := make([]string, 300)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	ret := owners(client, keys)
+	counts := make(map[string]int)
+	for _, addr := range ret {
+		counts[addr]++
+	}
+	if len(counts) != 3 || counts["127.0.0.1:1"] < 50 || counts["127.0.0.1:2"] < 50 || counts["127.0.0.1:3"] < 50 {
+		t.Fatalf("keys should be distributed over all shards %v", counts)
+	}
+	if a, b := owners(client, []string{"{user}.a", "{user}.b"}), ret; a["{user}.a"] != a["{user}.b"] || len(b) != 300 {
+		t.Fatalf("keys with the same hash tag should be in the same shard %v", a)
+	}
+	if v, _ := client.Do(context.Background(), client.B().Ping().Build()).ToString(); v != "PONG" {
+		t.Fatalf("unexpected resp %v", v)
+	}
+	if v, _ := client.DoCache(context.Background(), client.B().Get().Key(keys[0]).Cache(), time.Second).ToString(); v != ret[keys[0]] {
+		t.Fatalf("unexpected resp %v", v)
+	}
+	if v, _ := client.DoAsync(context.Background(), client.B().Get().Key(keys[1]).Build()).Wait(context.Background()).ToString(); v != ret[keys[1]] {
+		t.Fatalf("unexpected resp %v", v)
+	}
+	if nodes := client.Nodes(); len(nodes) != 3 {
+		t.Fatalf("unexpected nodes %v", nodes)
+	}
+	if stats := client.Stats(); len(stats) != 3 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+	if client.Mode() != ClientModeStandalone {
+		t.Fatalf("unexpected mode %v", client.Mode())
+	}
+}
+
+func TestShardedClientDoMulti(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	m := &shardedMock{}
+	client := newShardedTestClient(t, m)
+	defer client.Close()
+
+	keys := make([]string, 30)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	ret := owners(client, keys)
+	check := func(t *testing.T, resps []ValkeyResult) {
+		t.Helper()
+		for i, key := range keys {
+			if v, err := resps[i].ToString(); err != nil || v != ret[key] {
+				t.Fatalf("unexpected resp of %v: %v %v", key, v, err)
+			}
+		}
+	}
+
+	t.Run("DoMulti", func(t *testing.T) {
+		multi := make([]Completed, len(keys))
+		for i, key := range keys {
+			multi[i] = client.B().Get().Key(key).Build()
+		}
+		check(t, client.DoMulti(context.Background(), multi...))
+		m.calledOnce(t, "127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3")
+	})
+	t.Run("DoMultiCache", func(t *testing.T) {
+		multi := make([]CacheableTTL, len(keys))
+		for i, key := range keys {
+			multi[i] = CT(client.B().Get().Key(key).Cache(), time.Second)
+		}
+		check(t, client.DoMultiCache(context.Background(), multi...))
+		m.calledOnce(t, "127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3")
+	})
+	t.Run("DoMultiAsync", func(t *testing.T) {
+		multi := make([]Completed, len(keys))
+		for i, key := range keys {
+			multi[i] = client.B().Get().Key(key).Build()
+		}
+		check(t, client.DoMultiAsync(context.Background(), multi...).Wait(context.Background()))
+	})
+	t.Run("Empty", func(t *testing.T) {
+		if resps := client.DoMulti(context.Background()); resps != nil {
+			t.Fatalf("unexpected resps %v", resps)
+		}
+		if resps := client.DoMultiAsync(context.Background()).Wait(context.Background()); len(resps) != 0 {
+			t.Fatalf("unexpected resps %v", resps)
+		}
+	})
+}
+
+func TestShardedClientKeyless(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	m := &shardedMock{}
+	client := newShardedTestClient(t, m)
+	defer client.Close()
+
+	ret := owners(client, []string{"a", "b", "c", "d", "e"})
+	var other string
+	for _, key := range []string{"b", "c", "d", "e"} {
+		if ret[key] != ret["a"] {
+			other = key
+			break
+		}
+	}
+
+	if v, err := client.Do(context.Background(), client.B().Dbsize().Build()).ToInt64(); err != nil || v != 3 {
+		t.Fatalf("DBSIZE should be summed over all shards %v %v", v, err)
+	}
+	if v, err := client.DoAsync(context.Background(), client.B().Dbsize().Build()).Wait(context.Background()).ToInt64(); err != nil || v != 3 {
+		t.Fatalf("DBSIZE should be summed over all shards %v %v", v, err)
+	}
+	if s := client.DoStream(context.Background(), client.B().Dbsize().Build()); s.Error() != errKeylessStream {
+		t.Fatalf("unexpected err %v", s.Error())
+	}
+
+	t.Run("DoMulti", func(t *testing.T) {
+		multi := func() []Completed {
+			return []Completed{
+				client.B().Get().Key("a").Build(),
+				client.B().Dbsize().Build(),
+				client.B().Multi().Build(),
+				client.B().Get().Key("a").Build(),
+				client.B().Exec().Build(),
+				client.B().Multi().Build(),
+				client.B().Get().Key("a").Build(),
+				client.B().Get().Key(other).Build(),
+				client.B().Exec().Build(),
+			}
+		}
+		for _, resps := range [][]ValkeyResult{
+			client.DoMulti(context.Background(), multi()...),
+			client.DoMultiAsync(context.Background(), multi()...).Wait(context.Background()),
+		} {
+			if v, _ := resps[0].ToString(); v != ret["a"] {
+				t.Fatalf("unexpected resp %v", v)
+			}
+			if v, _ := resps[1].ToInt64(); v != 3 {
+				t.Fatalf("DBSIZE should be summed over all shards %v", v)
+			}
+			for _, resp := range resps[2:5] {
+				if v, _ := resp.ToString(); v != ret["a"] {
+					t.Fatalf("the transaction should follow its keyed command %v", v)
+				}
+			}
+			for _, resp := range resps[5:] {
+				if err := resp.Error(); err != errCrossShardMulti {
+					t.Fatalf("unexpected err %v", err)
+				}
+			}
+		}
+	})
+
+	t.Run("ScanAll", func(t *testing.T) {
+		scanner := ScanAll(client, context.Background(), ScanArgs{})
+		var keys []string
+		for key := range scanner.Iter() {
+			keys = append(keys, key)
+		}
+		if scanner.Err() != nil || len(keys) != 3 || keys[0] != "127.0.0.1:1" || keys[1] != "127.0.0.1:2" || keys[2] != "127.0.0.1:3" {
+			t.Fatalf("all shards should be scanned %v %v", keys, scanner.Err())
+		}
+		scanner = HScanAll(client, context.Background(), "a", ScanArgs{})
+		keys = keys[:0]
+		for key := range scanner.Iter() {
+			keys = append(keys, key)
+		}
+		if scanner.Err() != nil || len(keys) != 1 || keys[0] != ret["a"] {
+			t.Fatalf("only the shard of the key should be scanned %v %v", keys, scanner.Err())
+		}
+	})
+}
+
+func TestShardedClientEject(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	m := &shardedMock{}
+	client := newShardedTestClient(t, m)
+	defer client.Close()
+
+	keys := make([]string, 300)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	before := owners(client, keys)
+
+	check := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		client.check(ctx)
+	}
+
+	m.down.Store("127.0.0.1:2", true)
+	check()
+	for key, addr := range owners(client, keys) {
+		if before[key] != addr {
+			t.Fatalf("the shard should not be ejected before EjectAfter")
+		}
+	}
+	check()
+	m.down.Delete("127.0.0.1:2")
+	for key, addr := range owners(client, keys) {
+		if addr == "127.0.0.1:2" {
+			t.Fatalf("the shard should be ejected")
+		}
+		if before[key] != "127.0.0.1:2" && before[key] != addr {
+			t.Fatalf("only the keys of the ejected shard should be moved")
+		}
+	}
+
+	check()
+	for key, addr := range owners(client, keys) {
+		if before[key] != addr {
+			t.Fatalf("the shard should be put back")
+		}
+	}
+}
+
+func TestShardedClientDedicated(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	m := &shardedMock{}
+	client := newShardedTestClient(t, m)
+	defer client.Close()
+
+	ret := owners(client, []string{"a", "b", "c", "d", "e"})
+	var other string
+	for _, key := range []string{"b", "c", "d", "e"} {
+		if ret[key] != ret["a"] {
+			other = key
+			break
+		}
+	}
+
+	err := client.Dedicated(func(c DedicatedClient) error {
+		if v, _ := c.Do(context.Background(), c.B().Get().Key("a").Build()).ToString(); v != ret["a"] {
+			t.Fatalf("unexpected resp %v", v)
+		}
+		if err := c.Do(context.Background(), c.B().Get().Key(other).Build()).Error(); err != errCrossShard {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return c.DoMulti(context.Background(), c.B().Get().Key("a").Build(), c.B().Get().Key(other).Build())[0].Error()
+	})
+	if err != errCrossShard {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	c, cancel := client.Dedicate()
+	ch := c.SetPubSubHooks(PubSubHooks{OnMessage: func(m PubSubMessage) {}})
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatalf("the hooks should be closed")
+	}
+	if err := c.Do(context.Background(), c.B().Get().Key("a").Build()).Error(); err != ErrDedicatedClientRecycled {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestNewShardedClient(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	if _, err := NewShardedClient(ClientOption{}); err != ErrNoAddr {
+		t.Fatalf("unexpected err %v", err)
+	}
+	m := &shardedMock{}
+	if _, err := newShardedClient(&ClientOption{Sharded: ShardedOption{Shards: [][]string{{"127.0.0.1:1"}, {}}}}, m.connFn, newRetryer(defaultRetryDelayFn)); err != ErrNoAddr {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if _, err := newShardedClient(&ClientOption{Sharded: ShardedOption{Shards: [][]string{{"127.0.0.1:1", "127.0.0.1:2"}}}}, m.connFn, newRetryer(defaultRetryDelayFn)); err != ErrNoSendToReplicas {
+		t.Fatalf("unexpected err %v", err)
+	}
+	client, err := newShardedClient(&ClientOption{
+		Sharded:        ShardedOption{Shards: [][]string{{"127.0.0.1:1", "127.0.0.1:2"}}},
+		SendToReplicas: func(cmd Completed) bool { return cmd.IsReadOnly() },
+	}, m.connFn, newRetryer(defaultRetryDelayFn))
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+	if v, _ := client.Do(context.Background(), client.B().Get().Key("a").Build()).ToString(); v != "127.0.0.1:2" {
+		t.Fatalf("reads should be sent to the replica %v", v)
+	}
+	if nodes := client.Nodes(); len(nodes) != 2 {
+		t.Fatalf("unexpected nodes %v", nodes)
+	}
+}
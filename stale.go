@@ -0,0 +1,86 @@
+package valkey
+
+import (
+	"context"
+	"time"
+)
+
+type staleCtxKey struct{}
+
+// CacheStaleOption is the option of serving expired client-side cached values. See WithCacheStale.
+type CacheStaleOption struct {
+	// WhileRevalidate is the window after the expiration of a cached value, in which the expired value is returned immediately
+	// while one background refresh of it is running. The refresh is deduplicated with other reads of the same value.
+	WhileRevalidate time.Duration
+	// IfError is the window after the expiration of a cached value, in which the expired value is returned
+	// if its refresh fails, such as timeouts or connection errors.
+	IfError time.Duration
+}
+
+// WithCacheStale attaches the CacheStaleOption to the ctx for Client.DoCache and Client.DoMultiCache.
+// A cached value expires at its soft expiration, which is the ttl of the call or the PTTL of the key, and it is kept by
+// the client-side cache until its hard expiration, which is the soft expiration plus the larger window of the CacheStaleOption,
+// so that the expired value can be served while it is being refreshed or when the refresh fails.
+// Invalidated values are never served. Note that the values are kept by the cache of each connection, they are gone with
+// the connection once it is closed, and MGET and JSON.MGET are not affected. It only works with the default CacheStore.
+func WithCacheStale(ctx context.Context, opt CacheStaleOption) context.Context {
+	return context.WithValue(ctx, staleCtxKey{}, opt)
+}
+
+func (o CacheStaleOption) window() time.Duration {
+	return max(o.WhileRevalidate, o.IfError)
+}
+
+// revalidating reports whether the stale value can be served while it is being refreshed.
+func (o CacheStaleOption) revalidating(stale ValkeyMessage, now time.Time) bool {
+	return staleWithin(stale, now, o.WhileRevalidate).typ != 0
+}
+
+// failing reports whether the stale value can be served when the refresh fails.
+func (o CacheStaleOption) failing(stale ValkeyMessage, now time.Time) bool {
+	return staleWithin(stale, now, o.IfError).typ != 0
+}
+
+// doCacheStale is the DoCache serving stale values with the CacheStaleOption.
+func (p *pipe) doCacheStale(ctx context.Context, cache lruStore, cmd Cacheable, ck, cc string, ttl time.Duration, o CacheStaleOption) ValkeyResult {
+	now := time.Now()
+	v, entry, stale := cache.flightStale(ck, cc, ttl, now, o.window())
+	if v.typ != 0 {
+		return newResult(v, nil)
+	}
+	if o.revalidating(stale, now) {
+		if entry == nil { // the refresh is left to this call
+			go p.fetchCache(context.WithoutCancel(ctx), cmd, ck, cc)
+		}
+		return newResult(stale, nil)
+	}
+	var resp ValkeyResult
+	if entry != nil {
+		resp = newResult(entry.Wait(ctx))
+	} else {
+		resp = p.fetchCache(ctx, cmd, ck, cc)
+	}
+	if resp.err != nil && o.failing(stale, now) {
+		return newResult(stale, nil)
+	}
+	return resp
+}
+
+// staleReads holds the stale values of the DoMultiCache with the CacheStaleOption.
+type staleReads struct {
+	stales []ValkeyMessage
+	CacheStaleOption
+}
+
+// doMultiCacheStale is the DoMultiCache serving stale values with the CacheStaleOption.
+func (p *pipe) doMultiCacheStale(ctx context.Context, multi []CacheableTTL, o CacheStaleOption) *valkeyresults {
+	sr := &staleReads{stales: make([]ValkeyMessage, len(multi)), CacheStaleOption: o}
+	now := time.Now()
+	results := p.doMultiCache(ctx, multi, sr)
+	for i, stale := range sr.stales {
+		if results.s[i].err != nil && o.failing(stale, now) {
+			results.s[i] = newResult(stale, nil)
+		}
+	}
+	return results
+}
@@ -27,6 +27,7 @@ func newStandaloneClient(opt *ClientOption, connFn connFn, retryer retryHandler)
 		connFn:         connFn,
 		opt:            opt,
 		retryer:        retryer,
+		rerouting:      opt.CircuitBreaker != nil && opt.CircuitBreaker.RerouteReadOnly,
 	}
 	s.primary.Store(newSingleClientWithConn(p, cmds.NewBuilder(cmds.NoSlot), !opt.DisableRetry, opt.DisableCache, retryer, opt.ConnLifetime > 0))
 
@@ -55,6 +56,20 @@ func newStandaloneClient(opt *ClientOption, connFn connFn, retryer retryHandler)
 			}
 		}
 	}
+	if s.opt.ReplicaLag != nil && len(s.replicas) > 0 {
+		if s.nodes == nil {
+			s.nodes = make([]NodeInfo, len(s.replicas)+1)
+			s.nodes[0] = NodeInfo{Addr: s.primary.Load().conn.Addr()}
+			for i, replica := range s.replicas {
+				s.nodes[i+1] = NodeInfo{Addr: replica.conn.Addr()}
+			}
+		}
+		for i, replica := range s.replicas {
+			s.nodes[i+1].conn = replica.conn
+			s.nodes[i+1].lag = newLag()
+		}
+		s.lags = newLagSampler(s.opt.ReplicaLag, s.sampleLags)
+	}
 	return s, nil
 }
 
@@ -68,7 +83,9 @@ type standalone struct {
 	redirectCall   call
 	replicas       []*singleClient
 	nodes          []NodeInfo
+	lags           *sampler
 	enableRedirect bool
+	rerouting      bool
 }
 
 func (s *standalone) B() Builder {
@@ -76,6 +93,9 @@ func (s *standalone) B() Builder {
 }
 
 func (s *standalone) pick(slot uint16) *singleClient {
+	if s.lags != nil {
+		return s.pickFresh(slot)
+	}
 	if s.nodeSelector != nil {
 		rIndex := s.nodeSelector(slot, s.nodes)
 		if rIndex < 0 || rIndex >= len(s.nodes) {
@@ -93,6 +113,49 @@ func (s *standalone) pick(slot uint16) *singleClient {
 	return s.replicas[rand.IntN(len(s.replicas))]
 }
 
+// pickFresh is the same as the pick but excludes the replicas lagging behind more than the ClientOption.ReplicaLag.MaxLag.
+// It falls back to the primary if all the replicas are lagging.
+func (s *standalone) pickFresh(slot uint16) *singleClient {
+	var i int
+	if s.nodeSelector != nil {
+		i = pickFresh(slot, s.nodes, s.nodeSelector, s.opt.ReplicaLag.MaxLag)
+	} else if i = pickFresh(slot, s.nodes[1:], replicaOnlySelector, s.opt.ReplicaLag.MaxLag); i >= 0 {
+		i++
+	}
+	if i <= 0 {
+		return s.primary.Load()
+	}
+	return s.replicas[i-1]
+}
+
+func (s *standalone) sampleLags(ctx context.Context) {
+	sampleLags(ctx, s.primary.Load().conn, s.nodes[1:])
+}
+
+// reroute returns another node whose circuit breaker is closed for read-only commands if the circuit breaker of the sc is not closed.
+func (s *standalone) reroute(slot uint16, sc *singleClient, readonly bool) *singleClient {
+	if !s.rerouting || !readonly || sc.conn.Circuit() == CircuitClosed {
+		return sc
+	}
+	primary := s.primary.Load()
+	nodes := make([]NodeInfo, len(s.replicas)+1)
+	nodes[0] = NodeInfo{conn: primary.conn, Addr: primary.conn.Addr()}
+	for i, replica := range s.replicas {
+		nodes[i+1] = NodeInfo{conn: replica.conn, Addr: replica.conn.Addr()}
+	}
+	if len(s.nodes) == len(nodes) {
+		for i := range nodes {
+			nodes[i].AZ = s.nodes[i].AZ
+		}
+	}
+	if i := pickClosed(slot, nodes, s.nodeSelector); i == 0 {
+		return primary
+	} else if i > 0 {
+		return s.replicas[i-1]
+	}
+	return sc
+}
+
 func (s *standalone) redirectToPrimary(addr string) error {
 	// Create a new connection to the redirect address
 	redirectOpt := *s.opt
@@ -135,9 +198,9 @@ func (s *standalone) Do(ctx context.Context, cmd Completed) (resp ValkeyResult)
 
 retry:
 	if s.toReplicas != nil && s.toReplicas(cmd) {
-		resp = s.pick(cmd.Slot()).Do(ctx, cmd)
+		resp = s.reroute(cmd.Slot(), s.pick(cmd.Slot()), cmd.IsReadOnly()).Do(ctx, cmd)
 	} else {
-		resp = s.primary.Load().Do(ctx, cmd)
+		resp = s.reroute(cmd.Slot(), s.primary.Load(), cmd.IsReadOnly()).Do(ctx, cmd)
 	}
 
 	if s.enableRedirect {
@@ -170,7 +233,9 @@ retry:
 		toReplica = s.toReplicas(multi[i])
 	}
 	if toReplica && len(multi) > 0 {
-		resp = s.pick(multi[0].Slot()).DoMulti(ctx, multi...)
+		resp = s.reroute(multi[0].Slot(), s.pick(multi[0].Slot()), allReadOnly(multi)).DoMulti(ctx, multi...)
+	} else if len(multi) > 0 {
+		resp = s.reroute(multi[0].Slot(), s.primary.Load(), allReadOnly(multi)).DoMulti(ctx, multi...)
 	} else {
 		resp = s.primary.Load().DoMulti(ctx, multi...)
 	}
@@ -195,6 +260,105 @@ retry:
 	return resp
 }
 
+// redirecting reports whether the err asks the client to redirect to another primary.
+func (s *standalone) redirecting(err error) bool {
+	if ret, yes := IsValkeyErr(err); yes && s.enableRedirect {
+		_, ok := ret.IsRedirect()
+		return ok
+	}
+	return false
+}
+
+func (s *standalone) DoAsync(ctx context.Context, cmd Completed) *ValkeyFuture {
+	f := newFuture()
+
+	if s.enableRedirect {
+		cmd = cmd.Pin()
+	}
+
+	var sc *singleClient
+	if s.toReplicas != nil && s.toReplicas(cmd) {
+		sc = s.reroute(cmd.Slot(), s.pick(cmd.Slot()), cmd.IsReadOnly())
+	} else {
+		sc = s.reroute(cmd.Slot(), s.primary.Load(), cmd.IsReadOnly())
+	}
+	sc.doAsync(ctx, cmd, func(resp ValkeyResult) {
+		if s.redirecting(resp.Error()) {
+			go func() {
+				if err, _ := s.handleRedirect(ctx, resp.Error()); err == nil || s.retryer.WaitOrSkipRetry(ctx, 1, cmd, resp.Error()) {
+					f.resolve(s.Do(ctx, cmd))
+				} else {
+					f.resolve(s.recycle(cmd, resp))
+				}
+			}()
+			return
+		}
+		f.resolve(s.recycle(cmd, resp))
+	})
+	return f
+}
+
+func (s *standalone) DoMultiAsync(ctx context.Context, multi ...Completed) *ValkeyMultiFuture {
+	f := newMultiFuture(len(multi))
+	f.add(1)
+	if len(multi) == 0 {
+		f.resolve()
+		return f
+	}
+
+	if s.enableRedirect {
+		for i := range multi {
+			multi[i] = multi[i].Pin()
+		}
+	}
+
+	toReplica := s.toReplicas != nil
+	for i := 0; i < len(multi) && toReplica; i++ {
+		toReplica = s.toReplicas(multi[i])
+	}
+	var sc *singleClient
+	if toReplica {
+		sc = s.reroute(multi[0].Slot(), s.pick(multi[0].Slot()), allReadOnly(multi))
+	} else {
+		sc = s.reroute(multi[0].Slot(), s.primary.Load(), allReadOnly(multi))
+	}
+	sc.doMultiAsync(ctx, multi, f.resps, func() {
+		for i, result := range f.resps {
+			if s.redirecting(result.Error()) {
+				go func() {
+					if err, _ := s.handleRedirect(ctx, result.Error()); err == nil || s.retryer.WaitOrSkipRetry(ctx, 1, multi[i], result.Error()) {
+						copy(f.resps, s.DoMulti(ctx, multi...))
+					} else {
+						s.recycleMulti(multi, f.resps)
+					}
+					f.resolve()
+				}()
+				return
+			}
+		}
+		s.recycleMulti(multi, f.resps)
+		f.resolve()
+	})
+	return f
+}
+
+func (s *standalone) recycle(cmd Completed, resp ValkeyResult) ValkeyResult {
+	if s.enableRedirect && resp.NonValkeyError() == nil {
+		cmds.PutCompletedForce(cmd)
+	}
+	return resp
+}
+
+func (s *standalone) recycleMulti(multi []Completed, resps []ValkeyResult) {
+	if s.enableRedirect {
+		for i, resp := range resps {
+			if resp.NonValkeyError() == nil {
+				cmds.PutCompletedForce(multi[i])
+			}
+		}
+	}
+}
+
 func (s *standalone) Receive(ctx context.Context, subscribe Completed, fn func(msg PubSubMessage)) error {
 	if s.toReplicas != nil && s.toReplicas(subscribe) {
 		return s.pick(subscribe.Slot()).Receive(ctx, subscribe, fn)
@@ -203,12 +367,18 @@ func (s *standalone) Receive(ctx context.Context, subscribe Completed, fn func(m
 }
 
 func (s *standalone) Close() {
+	s.lags.Close()
 	s.primary.Load().Close()
 	for _, replica := range s.replicas {
 		replica.Close()
 	}
 }
 
+func (s *standalone) CloseWithContext(ctx context.Context) error {
+	s.lags.Close()
+	return closeWithContext(ctx, append([]*singleClient{s.primary.Load()}, s.replicas...))
+}
+
 func (s *standalone) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) (resp ValkeyResult) {
 	attempts := 1
 
@@ -217,7 +387,7 @@ func (s *standalone) DoCache(ctx context.Context, cmd Cacheable, ttl time.Durati
 	}
 
 retry:
-	resp = s.primary.Load().DoCache(ctx, cmd, ttl)
+	resp = s.reroute(cmd.Slot(), s.primary.Load(), true).DoCache(ctx, cmd, ttl)
 
 	if s.enableRedirect {
 		if err, ok := s.handleRedirect(ctx, resp.Error()); ok {
@@ -243,7 +413,11 @@ func (s *standalone) DoMultiCache(ctx context.Context, multi ...CacheableTTL) (r
 	}
 
 retry:
-	resp = s.primary.Load().DoMultiCache(ctx, multi...)
+	if len(multi) > 0 {
+		resp = s.reroute(multi[0].Cmd.Slot(), s.primary.Load(), true).DoMultiCache(ctx, multi...)
+	} else {
+		resp = s.primary.Load().DoMultiCache(ctx, multi...)
+	}
 
 	if s.enableRedirect {
 		for i, result := range resp {
@@ -305,6 +479,15 @@ func (s *standalone) Nodes() map[string]Client {
 	return nodes
 }
 
+func (s *standalone) Stats() map[string]NodeStats {
+	stats := make(map[string]NodeStats, len(s.replicas)+1)
+	maps.Copy(stats, s.primary.Load().Stats())
+	for _, replica := range s.replicas {
+		maps.Copy(stats, replica.Stats())
+	}
+	return stats
+}
+
 func (s *standalone) Mode() ClientMode {
 	return ClientModeStandalone
 }
@@ -92,6 +92,9 @@ func TestNewStandaloneClientDelegation(t *testing.T) {
 		AddrFn: func() string {
 			return "r"
 		},
+		StatsFn: func() NodeStats {
+			return NodeStats{Reconnects: 1}
+		},
 		DoFn: func(cmd Completed) ValkeyResult {
 			return newErrResult(errors.New("replica"))
 		},
@@ -196,6 +199,17 @@ func TestNewStandaloneClientDelegation(t *testing.T) {
 	if len(nodes) != 2 && nodes["p"].(*singleClient).conn != p && nodes["r"].(*singleClient).conn != r {
 		t.Fatalf("unexpected nodes %v", nodes)
 	}
+
+	stats := c.Stats()
+	if _, ok := stats["p"]; len(stats) != 2 || !ok || stats["r"].Reconnects != 1 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+
+	e := errors.New("ctx")
+	r.CloseCtxFn = func(ctx context.Context) error { return e }
+	if err := c.CloseWithContext(context.Background()); err != e {
+		t.Fatalf("unexpected err %v", err)
+	}
 }
 
 func TestNewStandaloneClientMultiReplicasDelegation(t *testing.T) {
@@ -304,6 +318,56 @@ func TestStandaloneRedirectHandling(t *testing.T) {
 	}
 }
 
+func TestStandaloneDoAsyncRedirectHandling(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+
+	redirectErr := ValkeyError(strmsg('-', "REDIRECT 127.0.0.1:6380"))
+
+	primaryConn := &mockConn{
+		DoFn: func(cmd Completed) ValkeyResult {
+			return newErrResult(&redirectErr)
+		},
+		DoMultiFn: func(multi ...Completed) *valkeyresults {
+			return &valkeyresults{s: []ValkeyResult{newErrResult(&redirectErr), newErrResult(&redirectErr)}}
+		},
+	}
+
+	redirectConn := &mockConn{
+		DoFn: func(cmd Completed) ValkeyResult {
+			return ValkeyResult{val: strmsg('+', "OK")}
+		},
+		DoMultiFn: func(multi ...Completed) *valkeyresults {
+			return &valkeyresults{s: []ValkeyResult{{val: strmsg('+', "OK")}, {val: strmsg('+', "OK")}}}
+		},
+	}
+
+	s, err := newStandaloneClient(&ClientOption{
+		InitAddress: []string{"primary"},
+		Standalone: StandaloneOption{
+			EnableRedirect: true,
+		},
+		DisableRetry: true,
+	}, func(dst string, opt *ClientOption) conn {
+		if dst == "primary" {
+			return primaryConn
+		}
+		return redirectConn
+	}, newRetryer(defaultRetryDelayFn))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	if str, err := s.DoMultiAsync(ctx, s.B().Get().Key("a").Build(), s.B().Get().Key("b").Build()).Wait(ctx)[1].ToString(); err != nil || str != "OK" {
+		t.Errorf("expected OK response after redirect, got: %s %v", str, err)
+	}
+	if str, err := s.DoAsync(ctx, s.B().Get().Key("test").Build()).Wait(ctx).ToString(); err != nil || str != "OK" {
+		t.Errorf("expected OK response after redirect, got: %s %v", str, err)
+	}
+}
+
 func TestStandaloneDoCacheRedirectHandling(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 
@@ -1066,6 +1130,83 @@ func TestStandaloneReadNodeSelector(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("CircuitBreaker RerouteReadOnly", func(t *testing.T) {
+		primaryNodeConn := &mockConn{
+			DoFn: func(cmd Completed) ValkeyResult {
+				return newErrResult(ErrCircuitOpen)
+			},
+			DoCacheFn: func(cmd Cacheable, ttl time.Duration) ValkeyResult {
+				return newErrResult(ErrCircuitOpen)
+			},
+			CircuitFn: func() CircuitState {
+				return CircuitOpen
+			},
+			AddrFn: func() string {
+				return "primary"
+			},
+		}
+		replica1NodeConn := &mockConn{
+			DoFn: func(cmd Completed) ValkeyResult {
+				return newResult(strmsg('+', "replica1"), nil)
+			},
+			CircuitFn: func() CircuitState {
+				return CircuitHalfOpen
+			},
+		}
+		replica2NodeConn := &mockConn{
+			DoFn: func(cmd Completed) ValkeyResult {
+				return newResult(strmsg('+', "replica2"), nil)
+			},
+			DoCacheFn: func(cmd Cacheable, ttl time.Duration) ValkeyResult {
+				return newResult(strmsg('+', "replica2"), nil)
+			},
+			AddrFn: func() string {
+				return "replica2"
+			},
+		}
+
+		client, err := newStandaloneClient(&ClientOption{
+			InitAddress: []string{"primary"},
+			Standalone: StandaloneOption{
+				ReplicaAddress: []string{"replica1", "replica2"},
+			},
+			SendToReplicas: func(cmd Completed) bool {
+				return false
+			},
+			ReadNodeSelector: func(slot uint16, nodes []NodeInfo) int {
+				if len(nodes) != 1 || nodes[0].Addr != "replica2" {
+					t.Errorf("unexpected nodes %v", nodes)
+				}
+				return 0
+			},
+			CircuitBreaker: &CircuitBreakerOption{RerouteReadOnly: true},
+			DisableRetry:   true,
+		}, func(dst string, opt *ClientOption) conn {
+			if dst == "primary" {
+				return primaryNodeConn
+			}
+			if dst == "replica1" {
+				return replica1NodeConn
+			}
+			return replica2NodeConn
+		}, newRetryer(defaultRetryDelayFn))
+
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		defer client.Close()
+
+		if val, _ := client.Do(context.Background(), client.B().Get().Key("key").Build()).ToString(); val != "replica2" {
+			t.Fatalf("expected replica2 to be selected, got %s", val)
+		}
+		if val, _ := client.DoCache(context.Background(), client.B().Get().Key("key").Cache(), time.Second).ToString(); val != "replica2" {
+			t.Fatalf("expected replica2 to be selected, got %s", val)
+		}
+		if err := client.Do(context.Background(), client.B().Set().Key("key").Value("v").Build()).Error(); err != ErrCircuitOpen {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
 }
 
 func TestStandaloneClientConnLifetime(t *testing.T) {
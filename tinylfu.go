@@ -0,0 +1,129 @@
+package valkey
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	sketchDepth   = 4
+	sketchMaxFreq = 15
+	sketchPacked  = 16                 // the number of 4-bit counters packed in a uint64 word
+	sketchHalf    = 0x7777777777777777 // the mask to halve all the counters in a word by a shift
+)
+
+// TinyLFUOption is the option of the NewTinyLFUPolicyFn.
+type TinyLFUOption struct {
+	// Counters is the number of counters in each row of the frequency sketch, which is rounded up to a power of 2 and at least 16.
+	// It should be around the number of entries expected in the cache. The default is 4096.
+	// Each counter takes 4 bits, and there are 4 rows, so the sketch takes 2 bytes per counter.
+	Counters int
+	// Window is the fraction of the number of entries reserved for new entries, which are admitted by their recency.
+	// It is TinyLFU if the Window is 0, and W-TinyLFU otherwise. A small window, such as 0.01, is recommended
+	// for workloads mixing recency and frequency. The default is 0.
+	Window float64
+}
+
+// NewTinyLFUPolicyFn returns a NewCachePolicyFn of the (W-)TinyLFU admission policy, which keeps the frequencies of
+// recent lookups in a count-min sketch and only admits a new entry if it is looked up more frequently than the victim.
+// It makes the client-side cache resistant to scans of keys accessed only once.
+func NewTinyLFUPolicyFn(opt TinyLFUOption) NewCachePolicyFn {
+	if opt.Counters <= 0 {
+		opt.Counters = 4096
+	}
+	opt.Window = min(max(opt.Window, 0), 1)
+	return func() CachePolicy {
+		return newTinyLFU(opt)
+	}
+}
+
+var _ CachePolicy = (*tinyLFU)(nil)
+
+// tinyLFU is the count-min sketch of 4-bit counters, packed 16 in a uint64 word. The counters are halved once the number
+// of additions reaches the sample size, so that the frequencies of stale entries decay.
+type tinyLFU struct {
+	rows   [sketchDepth][]atomic.Uint64
+	mu     sync.Mutex
+	adds   atomic.Int64
+	sample int64
+	mask   uint64
+	window float64
+}
+
+func newTinyLFU(opt TinyLFUOption) *tinyLFU {
+	width := max(uint64(1)<<bits.Len64(uint64(opt.Counters-1)), sketchPacked)
+	t := &tinyLFU{sample: 10 * int64(width), mask: width - 1, window: opt.Window}
+	for i := range t.rows {
+		t.rows[i] = make([]atomic.Uint64, width/sketchPacked)
+	}
+	return t
+}
+
+func (t *tinyLFU) Window() float64 {
+	return t.window
+}
+
+func (t *tinyLFU) Record(hash uint64) {
+	added := false
+	for i := range t.rows {
+		w, shift := t.word(hash, i)
+		for {
+			v := w.Load()
+			if (v>>shift)&sketchMaxFreq >= sketchMaxFreq {
+				break
+			}
+			if w.CompareAndSwap(v, v+1<<shift) {
+				added = true
+				break
+			}
+		}
+	}
+	if added && t.adds.Add(1) >= t.sample {
+		t.reset()
+	}
+}
+
+func (t *tinyLFU) Admit(candidate, victim uint64) bool {
+	return t.estimate(candidate) > t.estimate(victim)
+}
+
+func (t *tinyLFU) estimate(hash uint64) (freq uint64) {
+	freq = sketchMaxFreq
+	for i := range t.rows {
+		w, shift := t.word(hash, i)
+		freq = min(freq, (w.Load()>>shift)&sketchMaxFreq)
+	}
+	return freq
+}
+
+// word returns the word holding the counter of the hash in the ith row, and the shift of the counter in the word.
+func (t *tinyLFU) word(hash uint64, i int) (*atomic.Uint64, uint64) {
+	j := t.index(hash, i)
+	return &t.rows[i][j/sketchPacked], (j % sketchPacked) * 4
+}
+
+// index returns the counter index of the hash in the ith row by rehashing it with a different seed for each row.
+func (t *tinyLFU) index(hash uint64, i int) uint64 {
+	hash = (hash + uint64(i)) * sketchSeeds[i]
+	return (hash ^ hash>>32) & t.mask
+}
+
+// reset halves all counters. Concurrent Records during the reset may be lost, which is acceptable for an estimation.
+func (t *tinyLFU) reset() {
+	if !t.mu.TryLock() {
+		return
+	}
+	if t.adds.Load() >= t.sample {
+		for i := range t.rows {
+			for j := range t.rows[i] {
+				w := &t.rows[i][j]
+				w.Store((w.Load() >> 1) & sketchHalf)
+			}
+		}
+		t.adds.Store(t.sample / 2)
+	}
+	t.mu.Unlock()
+}
+
+var sketchSeeds = [sketchDepth]uint64{0xc3a5c85c97cb3127, 0xb492b66fbe98f273, 0x9ae16a3b2f90404f, 0xcbf29ce484222325}
@@ -0,0 +1,75 @@
+package valkey
+
+import (
+	"testing"
+)
+
+func TestTinyLFU(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		p := NewTinyLFUPolicyFn(TinyLFUOption{Window: 2})().(*tinyLFU)
+		if p.mask+1 != 4096 || len(p.rows[0]) != 4096/sketchPacked || p.Window() != 1 {
+			t.Fatalf("unexpected defaults %v %v %v", p.mask+1, len(p.rows[0]), p.Window())
+		}
+		if p := newTinyLFU(TinyLFUOption{Counters: 100}); p.mask+1 != 128 || len(p.rows[0]) != 128/sketchPacked {
+			t.Fatalf("the counters should be rounded up to a power of 2 %v", p.mask+1)
+		}
+		if p := newTinyLFU(TinyLFUOption{Counters: 1}); p.mask+1 != sketchPacked || len(p.rows[0]) != 1 {
+			t.Fatalf("the counters should fill at least one word %v", p.mask+1)
+		}
+	})
+
+	t.Run("Estimate And Admit", func(t *testing.T) {
+		p := newTinyLFU(TinyLFUOption{Counters: 1024})
+		for i := 0; i < 5; i++ {
+			p.Record(1)
+		}
+		p.Record(2)
+		if f := p.estimate(1); f != 5 {
+			t.Fatalf("unexpected frequency %v", f)
+		}
+		if !p.Admit(1, 2) || p.Admit(2, 1) || p.Admit(3, 3) {
+			t.Fatalf("unexpected admission")
+		}
+	})
+
+	t.Run("Saturate", func(t *testing.T) {
+		p := newTinyLFU(TinyLFUOption{Counters: 1024})
+		for i := 0; i < 100; i++ {
+			p.Record(1)
+		}
+		if f := p.estimate(1); f != sketchMaxFreq {
+			t.Fatalf("unexpected frequency %v", f)
+		}
+	})
+
+	t.Run("Packed", func(t *testing.T) {
+		p := newTinyLFU(TinyLFUOption{Counters: 16})
+		for i := 0; i < 100; i++ {
+			p.Record(1)
+		}
+		for i := range p.rows {
+			w, shift := p.word(1, i)
+			if v := w.Load(); v != sketchMaxFreq<<shift {
+				t.Fatalf("only the counter of the hash should be saturated in the word %x", v)
+			}
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		p := newTinyLFU(TinyLFUOption{Counters: 16})
+		for i := 0; i < 8; i++ {
+			p.Record(1)
+		}
+		for i := uint64(100); p.adds.Load() < p.sample-1; i++ {
+			p.Record(i)
+		}
+		f := p.estimate(1)
+		p.Record(1000)
+		if after := p.estimate(1); after > f/2+1 {
+			t.Fatalf("the frequency should be halved %v %v", f, after)
+		}
+		if p.adds.Load() != p.sample/2 {
+			t.Fatalf("unexpected additions %v", p.adds.Load())
+		}
+	})
+}
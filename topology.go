@@ -0,0 +1,104 @@
+package valkey
+
+import (
+	"slices"
+	"strings"
+)
+
+// TopologyChange is the difference between two consecutive topologies observed by a cluster or sentinel client.
+// It is delivered to the ClientOption.OnTopologyChange.
+type TopologyChange struct {
+	// Added are the nodes that appear in the new topology.
+	Added []TopologyNode
+	// Removed are the nodes that disappear from the new topology.
+	Removed []TopologyNode
+	// Promoted are the nodes that were replicas and become primaries in the new topology, for example, after a failover.
+	Promoted []TopologyNode
+	// AZChanged are the nodes whose availability zones are changed. Only available with ClientOption.EnableReplicaAZInfo.
+	AZChanged []TopologyNode
+	// Moved are the slot ranges whose primaries are changed. Only available in a cluster.
+	Moved []SlotRangeMove
+}
+
+// TopologyNode is a node in the topology observed by a cluster or sentinel client.
+type TopologyNode struct {
+	Addr    string
+	AZ      string
+	Primary bool
+}
+
+// SlotRangeMove is a range of slots moved from one primary to another.
+type SlotRangeMove struct {
+	// From is the address of the previous primary. It is empty if the slots were not served.
+	From string
+	// To is the address of the current primary. It is empty if the slots are not served anymore.
+	To    string
+	Start uint16
+	End   uint16
+}
+
+// IsEmpty reports whether the topology is not changed.
+func (t TopologyChange) IsEmpty() bool {
+	return len(t.Added) == 0 && len(t.Removed) == 0 && len(t.Promoted) == 0 && len(t.AZChanged) == 0 && len(t.Moved) == 0
+}
+
+type topology struct {
+	nodes  map[string]TopologyNode
+	owners []string // the primary address of each slot, nil if not a cluster.
+}
+
+func newClusterTopology(groups map[string]group) *topology {
+	t := &topology{nodes: make(map[string]TopologyNode), owners: make([]string, 16384)}
+	for _, g := range groups {
+		for i, n := range g.nodes {
+			t.nodes[n.Addr] = TopologyNode{Addr: n.Addr, AZ: n.AZ, Primary: i == 0}
+		}
+		for _, slot := range g.slots {
+			for i := slot[0]; i <= slot[1] && i >= 0 && i < 16384; i++ {
+				t.owners[i] = g.nodes[0].Addr
+			}
+		}
+	}
+	return t
+}
+
+// diffTopology compares the next topology with the prev one. The prev can be nil for the first observation.
+func diffTopology(prev, next *topology) (change TopologyChange) {
+	if prev == nil {
+		prev = &topology{}
+	}
+	for addr, n := range next.nodes {
+		if p, ok := prev.nodes[addr]; !ok {
+			change.Added = append(change.Added, n)
+		} else {
+			if n.Primary && !p.Primary {
+				change.Promoted = append(change.Promoted, n)
+			}
+			if n.AZ != p.AZ {
+				change.AZChanged = append(change.AZChanged, n)
+			}
+		}
+	}
+	for addr, p := range prev.nodes {
+		if _, ok := next.nodes[addr]; !ok {
+			change.Removed = append(change.Removed, p)
+		}
+	}
+	for _, nodes := range [][]TopologyNode{change.Added, change.Removed, change.Promoted, change.AZChanged} {
+		slices.SortFunc(nodes, func(a, b TopologyNode) int { return strings.Compare(a.Addr, b.Addr) })
+	}
+	if next.owners != nil && prev.owners != nil {
+		for i := 0; i < len(next.owners); i++ {
+			if next.owners[i] == prev.owners[i] {
+				continue
+			}
+			m := SlotRangeMove{From: prev.owners[i], To: next.owners[i], Start: uint16(i)}
+			for i+1 < len(next.owners) && next.owners[i+1] == m.To && prev.owners[i+1] == m.From {
+				i++
+			}
+			m.End = uint16(i)
+			change.Moved = append(change.Moved, m)
+		}
+	}
+	return change
+}
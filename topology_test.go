@@ -0,0 +1,47 @@
+package valkey
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffTopology(t *testing.T) {
+	prev := newClusterTopology(map[string]group{
+		"a:0": {nodes: nodes{{Addr: "a:0"}, {Addr: "a:1", AZ: "us-east-1a"}}, slots: [][2]int64{{0, 8191}}},
+		"b:0": {nodes: nodes{{Addr: "b:0"}, {Addr: "b:1"}}, slots: [][2]int64{{8192, 16383}}},
+	})
+
+	t.Run("Initial", func(t *testing.T) {
+		if change := diffTopology(nil, prev); !reflect.DeepEqual(change, TopologyChange{
+			Added: []TopologyNode{{Addr: "a:0", Primary: true}, {Addr: "a:1", AZ: "us-east-1a"}, {Addr: "b:0", Primary: true}, {Addr: "b:1"}},
+		}) {
+			t.Fatalf("unexpected change %v", change)
+		}
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		if change := diffTopology(prev, prev); !change.IsEmpty() {
+			t.Fatalf("unexpected change %v", change)
+		}
+	})
+
+	t.Run("Failover and Migration", func(t *testing.T) {
+		next := newClusterTopology(map[string]group{
+			"a:1": {nodes: nodes{{Addr: "a:1", AZ: "us-east-1b"}}, slots: [][2]int64{{0, 99}, {200, 8191}}},
+			"b:0": {nodes: nodes{{Addr: "b:0"}, {Addr: "b:1"}, {Addr: "b:2"}}, slots: [][2]int64{{100, 199}, {8192, 16383}}},
+		})
+		if change := diffTopology(prev, next); !reflect.DeepEqual(change, TopologyChange{
+			Added:     []TopologyNode{{Addr: "b:2"}},
+			Removed:   []TopologyNode{{Addr: "a:0", Primary: true}},
+			Promoted:  []TopologyNode{{Addr: "a:1", AZ: "us-east-1b", Primary: true}},
+			AZChanged: []TopologyNode{{Addr: "a:1", AZ: "us-east-1b", Primary: true}},
+			Moved: []SlotRangeMove{
+				{From: "a:0", To: "a:1", Start: 0, End: 99},
+				{From: "a:0", To: "b:0", Start: 100, End: 199},
+				{From: "a:0", To: "a:1", Start: 200, End: 8191},
+			},
+		}) {
+			t.Fatalf("unexpected change %+v", change)
+		}
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/valkey-io/valkey-go/internal/util"
@@ -21,25 +22,33 @@ const (
 	queueTypeEnvVar = "RUEIDIS_QUEUE_TYPE"
 )
 
-// queue types.
-// queue type defines the type of queue implementation to use for command pipelining
+// QueueType defines the type of queue implementation to use for command pipelining.
+// It can be set by ClientOption.QueueType for each client.
+// If ClientOption.QueueType is empty, the "RUEIDIS_QUEUE_TYPE" environment variable is used.
 // If you want to use the ring buffer, you can set the "RUEIDIS_QUEUE_TYPE" environment variable to "ring" or empty string.
 // If you want to use the flow buffer, you can set the "RUEIDIS_QUEUE_TYPE" environment variable to "flowbuffer".
+// If you want to use the batched MPSC queue, you can set the "RUEIDIS_QUEUE_TYPE" environment variable to "mpsc".
+type QueueType string
+
 const (
 	// QueueTypeRing uses the default ring buffer with mutex/condition variables
 	// This provides the best raw performance with atomic operations and condition variables
 	// but does not support context cancellation when the buffer is full
-	queueTypeRing = "ring"
+	QueueTypeRing QueueType = "ring"
 	// QueueTypeFlowBuffer uses a channel-based lock-free implementation
 	// This provides context cancellation support even when the buffer is full
 	// but is slower than QueueTypeRing and requires more memory
-	queueTypeFlowBuffer = "flowbuffer"
+	QueueTypeFlowBuffer QueueType = "flowbuffer"
+	// QueueTypeMPSC uses a lock-free multi-producer single-consumer queue with atomic operations only
+	// This lets the writer drain all queued commands per wakeup without locking
+	// and provides context cancellation support when the buffer is full
+	QueueTypeMPSC QueueType = "mpsc"
 )
 
-var queueTypeFromEnv string
+var queueTypeFromEnv QueueType
 
 func init() {
-	queueTypeFromEnv = os.Getenv(queueTypeEnvVar)
+	queueTypeFromEnv = QueueType(os.Getenv(queueTypeEnvVar))
 }
 
 const (
@@ -49,6 +58,8 @@ const (
 	DefaultRingScale = 10
 	// DefaultPoolSize is the default value of ClientOption.BlockingPoolSize
 	DefaultPoolSize = 1024
+	// DefaultBlockingPoolHealthCheck is the default value of ClientOption.BlockingPoolHealthCheck
+	DefaultBlockingPoolHealthCheck = 10 * time.Second
 	// DefaultBlockingPipeline is the default value of ClientOption.BlockingPipeline
 	DefaultBlockingPipeline = 2000
 	// DefaultDialTimeout is the default value of ClientOption.Dialer.Timeout
@@ -72,8 +83,9 @@ var (
 	ErrClosing = errors.New("valkey client is closing or unable to connect valkey")
 	// ErrNoAddr means the ClientOption.InitAddress is empty
 	ErrNoAddr = errors.New("no alive address in InitAddress")
-	// ErrNoCache means your valkey does not support client-side caching and must set ClientOption.DisableCache to true
-	ErrNoCache = errors.New("ClientOption.DisableCache must be true for valkey not supporting client-side caching or not supporting RESP3")
+	// ErrNoCache means your valkey does not support client-side caching and must set ClientOption.DisableCache to true.
+	// Over RESP2, client-side caching requires the CLIENT TRACKING REDIRECT option.
+	ErrNoCache = errors.New("ClientOption.DisableCache must be true for valkey not supporting client-side caching")
 	// ErrRESP2PubSubMixed means your valkey does not support RESP3 and valkey can't handle SUBSCRIBE/PSUBSCRIBE/SSUBSCRIBE in mixed case
 	ErrRESP2PubSubMixed = errors.New("valkey does not support SUBSCRIBE/PSUBSCRIBE/SSUBSCRIBE mixed with other commands in RESP2")
 	// ErrBlockingPubSubMixed valkey can't handle SUBSCRIBE/PSUBSCRIBE/SSUBSCRIBE mixed with other blocking commands
@@ -89,6 +101,14 @@ var (
 	ErrWrongPipelineMultiplex = errors.New("ClientOption.PipelineMultiplex must not be bigger than MaxPipelineMultiplex")
 	// ErrDedicatedClientRecycled means the caller attempted to use the dedicated client which has been already recycled (after canceled/closed).
 	ErrDedicatedClientRecycled = errors.New("dedicated client should not be used after recycled")
+	// ErrOverloaded means the connection has reached the ClientOption.MaxInflightPerConn and the command is rejected without being sent.
+	ErrOverloaded = errors.New("valkey connection is overloaded with too many in-flight commands")
+	// ErrSelectDBNotSupported means the valkey cluster rejects the ClientOption.SelectDB. Numbered databases in cluster mode require Valkey 9 or later.
+	ErrSelectDBNotSupported = errors.New("ClientOption.SelectDB is not supported by the valkey cluster")
+	// ErrCircuitOpen means the circuit breaker of the target node is open and the command is rejected without being sent.
+	ErrCircuitOpen = errors.New("valkey circuit breaker is open")
+	// ErrBroadcastPrefixOverlap means a prefix of the BroadcastTracking is a prefix of another one, which is rejected by valkey.
+	ErrBroadcastPrefixOverlap = errors.New("broadcast tracking prefixes must not overlap with each other")
 	// DisableClientSetInfo is the value that can be used for ClientOption.ClientSetInfo to disable making the CLIENT SETINFO command
 	DisableClientSetInfo = make([]string, 0)
 )
@@ -109,12 +129,22 @@ type ClientOption struct {
 	DialCtxFn func(context.Context, string, *net.Dialer, *tls.Config) (conn net.Conn, err error)
 
 	// NewCacheStoreFn allows a custom client side caching store for each connection
+	// Use NewSharedCacheStoreFn to share one store among the connections to the same node.
 	NewCacheStoreFn NewCacheStoreFn
 
+	// NewCachePolicyFn creates the CachePolicy of the client side caching store for each connection.
+	// It is only used by the default store and the NewSharedCacheStoreFn. Use NewTinyLFUPolicyFn for the W-TinyLFU policy.
+	NewCachePolicyFn NewCachePolicyFn
+
 	// OnInvalidations is a callback function in case of client-side caching invalidation received.
 	// Note that this function must be fast; otherwise other valkey messages will be blocked.
 	OnInvalidations func([]ValkeyMessage)
 
+	// OnTopologyChange is a callback function in case of the topology changes observed by a cluster or sentinel client,
+	// including the nodes added or removed, the primaries promoted, and the slot ranges moved.
+	// The first call reports all discovered nodes as added. Note that this function must not block.
+	OnTopologyChange func(TopologyChange)
+
 	// SendToReplicas is a function that returns true if the command should be sent to replicas.
 	// NOTE: This function can't be used with the ReplicaOnly option.
 	SendToReplicas func(cmd Completed) bool
@@ -182,15 +212,28 @@ type ClientOption struct {
 	// The default is []string{"OPTIN"}
 	ClientTrackingOptions []string
 
+	// Broadcast enables the broadcasting mode of the client-side caching with its prefixes. See BroadcastTracking for details.
+	// It must not be used with ClientTrackingOptions, and it can be shared by multiple clients.
+	Broadcast *BroadcastTracking
+
 	// Standalone is the option for the standalone client.
 	Standalone StandaloneOption
 
+	// Sharded is the option for the sharded client. See NewShardedClient for details.
+	Sharded ShardedOption
+
+	// SelectDB is the database selected on every connection with the SELECT command.
+	// For a cluster client, it is applied to connections to all the nodes, which requires Valkey 9 or later.
 	SelectDB int
 
 	// CacheSizeEachConn is valkey client side cache size that bind to each TCP connection to a single valkey instance.
 	// The default is DefaultCacheBytes.
 	CacheSizeEachConn int
 
+	// QueueType is the queue implementation used for pipelining commands in each connection.
+	// The default is QueueTypeRing, or the one specified by the "RUEIDIS_QUEUE_TYPE" environment variable.
+	QueueType QueueType
+
 	// RingScaleEachConn sets the size of the ring buffer in each connection to (2 ^ RingScaleEachConn).
 	// The default is RingScaleEachConn, which results in having a ring of size 2^10 for each connection.
 	// Reducing this value can reduce the memory consumption of each connection at the cost of potential throughput degradation.
@@ -210,6 +253,14 @@ type ClientOption struct {
 	// Only relevant if BlockingPoolCleanup is not 0. This parameter limits
 	// the number of idle connections that can be removed by BlockingPoolCleanup.
 	BlockingPoolMinSize int
+	// BlockingPoolMinIdle is the number of idle connections dialed in advance and kept ready in the connection pool
+	// shared by blocking commands (ex BLPOP, XREAD with BLOCK) and dedicated clients, and also in the pool
+	// used by DisableAutoPipelining. Broken idle connections are replaced in the background.
+	// The default 0 means connections are only dialed on demand.
+	BlockingPoolMinIdle int
+	// BlockingPoolHealthCheck is the interval of checking the idle connections kept by BlockingPoolMinIdle with PING.
+	// The default is DefaultBlockingPoolHealthCheck.
+	BlockingPoolHealthCheck time.Duration
 
 	// BlockingPoolSize is the size of the connection pool shared by blocking commands (ex BLPOP, XREAD with BLOCK).
 	// The default is DefaultPoolSize.
@@ -220,8 +271,21 @@ type ClientOption struct {
 	// PipelineMultiplex determines how many tcp connections used to pipeline commands to one valkey instance.
 	// The default for single and sentinel clients is 2, which means 4 connections (2^2).
 	// The default for cluster clients is 0, which means 1 connection (2^0).
+	// If AdaptivePipelineMultiplex is set, PipelineMultiplex is the lower bound of the adaptive multiplex.
 	PipelineMultiplex int
 
+	// MaxInflightPerConn limits the number of in-flight commands of each pipelining connection if it is positive.
+	// A DoMulti or a DoMultiCache counts as the number of its commands, and it is admitted only if the connection is idle when it exceeds the limit.
+	// Commands beyond the limit fail fast with ErrOverloaded unless WaitOnOverload is set. Rejected commands are not retried.
+	// Setting it smaller than the ring size (2 ^ RingScaleEachConn) also prevents callers from blocking on a full ring without honoring their contexts.
+	MaxInflightPerConn int
+	// WaitOnOverload makes commands beyond the MaxInflightPerConn wait until their contexts are done instead of failing with ErrOverloaded.
+	WaitOnOverload bool
+
+	// AdaptivePipelineMultiplex enables scaling the number of pipelining connections to each valkey instance with load if it is not nil.
+	// See AdaptiveMultiplexOption for details.
+	AdaptivePipelineMultiplex *AdaptiveMultiplexOption
+
 	// ConnWriteTimeout is a read/write timeout for each connection. If specified,
 	// it is used to control the maximum duration waits for responses to pipeline commands.
 	// Also, ConnWriteTimeout is applied net.Conn.SetDeadline and periodic PINGs,
@@ -246,6 +310,14 @@ type ClientOption struct {
 	// ClusterOption is the options for the valkey cluster client.
 	ClusterOption ClusterOption
 
+	// CircuitBreaker enables a circuit breaker for each valkey node if it is not nil.
+	// See CircuitBreakerOption for details.
+	CircuitBreaker *CircuitBreakerOption
+
+	// ReplicaLag enables the lag-aware read routing to replicas if it is not nil.
+	// See ReplicaLagOption for details.
+	ReplicaLag *ReplicaLagOption
+
 	// DisableTCPNoDelay turns on Nagle's algorithm in pipelining mode by using conn.SetNoDelay(false).
 	// Turning this on can result in lower p99 latencies and lower CPU usages if all your requests are small.
 	// But if you have large requests or fast network, this might degrade the performance. Ref: https://github.com/redis/rueidis/pull/650
@@ -264,6 +336,7 @@ type ClientOption struct {
 	// AlwaysPipelining makes valkey.Client always pipeline valkey commands even if they are not issued concurrently.
 	AlwaysPipelining bool
 	// AlwaysRESP2 makes valkey.Client always uses RESP2; otherwise, it will try using RESP3 first.
+	// Over RESP2, the client-side caching takes an extra connection to each node for receiving invalidations.
 	AlwaysRESP2 bool
 	//  ForceSingleClient force the usage of a single client connection, without letting the lib guessing
 	//  if valkey instance is a cluster or a single valkey instance.
@@ -318,6 +391,20 @@ type ClusterOption struct {
 
 	// PreferInitAddressRefresh only uses ClientOption.InitAddress nodes during cluster topology refresh.
 	PreferInitAddressRefresh bool
+
+	// SplitCrossSlot allows the multi-key commands, DEL, UNLINK, EXISTS, TOUCH, MGET, and MSET, built by the client
+	// to have keys of different slots. These commands are split by key slots and sent concurrently by
+	// Do, DoMulti, DoAsync, and DoMultiAsync, and their replies are merged into one:
+	// integer replies are summed and array replies are concatenated in the order of the keys.
+	// Note that a split command is not atomic, and other methods return an error for such a command.
+	SplitCrossSlot bool
+
+	// OnSlotMigration is a callback function in case of the slot migration progress observed by a cluster client.
+	// A slot is reported migrating on its first ASK redirect, and done on its first MOVED redirect or
+	// when the refreshed topology shows it served by another primary. A Valkey atomic slot migration is only reported done.
+	// The progress is only learned from redirects, since CLUSTER SLOTS and CLUSTER SHARDS do not report migrating or importing slots.
+	// Note that this function must not block.
+	OnSlotMigration func(SlotMigration)
 }
 
 // StandaloneOption is the options for the standalone client.
@@ -332,16 +419,185 @@ type StandaloneOption struct {
 	EnableRedirect bool
 }
 
+// ShardedOption is the options for the sharded client.
+type ShardedOption struct {
+	// Shards are the independent valkey instances to distribute keys over.
+	// The first address of each shard is its primary, and the rest are its replicas, which are used as the Standalone.ReplicaAddress.
+	Shards [][]string
+	// HealthCheckInterval is the interval of PING health checks to each shard. The default is 1s.
+	HealthCheckInterval time.Duration
+	// EjectAfter is the number of consecutive failed health checks before a shard is ejected from the ring. The default is 3.
+	// An ejected shard is put back to the ring after a successful health check.
+	EjectAfter int
+}
+
+// AdaptiveMultiplexOption is the options for the adaptive pipeline multiplex.
+// The number of pipelining connections to each valkey instance starts from 2^ClientOption.PipelineMultiplex.
+// It is doubled when the average number of in-flight commands on a connection or the write latency of a connection
+// crosses the threshold, and it is halved after the load stays low for IdleIntervals.
+// Surplus connections are closed gracefully after their in-flight commands are fulfilled.
+type AdaptiveMultiplexOption struct {
+	// Interval is the interval to evaluate the load. The default is 1s.
+	Interval time.Duration
+	// WriteLatency is the threshold of the duration of flushing commands to a connection.
+	// The default is 0, which means the write latency is not considered.
+	WriteLatency time.Duration
+	// QueueDepth is the threshold of the average number of in-flight commands on a connection. The default is 64.
+	QueueDepth int
+	// IdleIntervals is the number of consecutive intervals with low load to halve the connections. The default is 10.
+	IdleIntervals int
+	// MaxPipelineMultiplex is the upper bound of the adaptive multiplex. The default is MaxPipelineMultiplex.
+	MaxPipelineMultiplex int
+}
+
+// CircuitBreakerOption is the options for the per-node circuit breaker.
+// A breaker counts the requests and the network errors (including timeouts, but excluding valkey errors and the cancellations
+// or the expired deadlines of the callers' contexts) to a node within a fixed Window, whose counters are reset when the Window elapses. When both MinRequests and ErrorRate are reached
+// within the same Window, the breaker opens and all following commands to the node fail fast with ErrCircuitOpen without being retried.
+// After OpenTimeout, the breaker becomes half-open and sends a PING to the node. It closes again if the PING succeeds.
+type CircuitBreakerOption struct {
+	// OnStateChange is called when the breaker of a node changes its state. It must not block.
+	OnStateChange func(addr string, from, to CircuitState)
+	// Window is the duration of the fixed error rate window. The default is 10s.
+	Window time.Duration
+	// OpenTimeout is the duration for a breaker to stay open before probing the node. The default is 5s.
+	OpenTimeout time.Duration
+	// MinRequests is the minimum number of requests in a window before the breaker can open. The default is 20.
+	MinRequests int
+	// ErrorRate is the ratio of failed requests in a window to open the breaker. The default is 0.5.
+	ErrorRate float64
+	// RerouteReadOnly reroutes read-only commands to other nodes of the same shard selected by the
+	// ClientOption.ReadNodeSelector when the breaker of the target node is open.
+	// It requires ClientOption.ReadNodeSelector to be set for the cluster client
+	// and ClientOption.Standalone.ReplicaAddress to be set for the standalone client.
+	RerouteReadOnly bool
+}
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int32
+
+const (
+	// CircuitClosed means commands are sent to the node normally.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means commands are rejected with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen means a PING is probing the node and commands are still rejected with ErrCircuitOpen.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// ReplicaLagOption is the options for the lag-aware read routing of the cluster, sentinel, and standalone clients.
+// The client samples the replication offsets of each primary and its replicas with the ROLE command every Interval.
+// The lag of a replica is the difference between the offsets, and replicas lagging behind more than MaxLag,
+// or not sampled successfully, are excluded from serving read-only commands. The primary is used if no replica is left.
+type ReplicaLagOption struct {
+	// Interval is the interval to sample the replication offsets. The default is 1s.
+	Interval time.Duration
+	// MaxLag is the maximum replication lag in bytes for a replica to serve read-only commands.
+	MaxLag int64
+}
+
 // NodeInfo is the information of a replica node in a valkey cluster.
 type NodeInfo struct {
 	conn conn
+	lag  *atomic.Int64
 	Addr string
 	AZ   string
 }
 
+// Lag returns the replication lag in bytes of the node sampled with the ClientOption.ReplicaLag.
+// It is 0 for a primary, and math.MaxInt64 for a replica not sampled yet or failed to be sampled.
+// Without the ClientOption.ReplicaLag, nothing is sampled and it is 0 for every replica as well,
+// so a 0 lag does not tell a replica in sync from one not measured.
+func (n NodeInfo) Lag() int64 {
+	if n.lag == nil {
+		return 0
+	}
+	return n.lag.Load()
+}
+
+// RTT returns the EWMA of round-trip times of the auto-pipelining connections to the node, including the background PING.
+// Only the commands sent by Do and DoMulti are measured. Those sent by DoAsync, DoMultiAsync, DoStream, DoMultiStream,
+// and over the blocking pool are not. It is 0 if nothing has been measured yet.
+func (n NodeInfo) RTT() time.Duration {
+	if n.conn == nil {
+		return 0
+	}
+	return n.conn.RTT()
+}
+
 // ReplicaInfo is the information of a replica node in a valkey cluster.
 type ReplicaInfo = NodeInfo
 
+// NodeStats is a point-in-time snapshot of the connections held by a client to a single valkey node.
+// It is collected without sending any command to the node, so it is cheap enough to be polled periodically.
+type NodeStats struct {
+	// Pipelines contains the stats of each live auto-pipelining connection to the node.
+	Pipelines []PipelineStats
+	// BlockingPool is the stats of the pool used by blocking commands and dedicated clients.
+	BlockingPool PoolStats
+	// StreamPool is the stats of the pool used by DoStream, DoMultiStream and ClientOption.DisableAutoPipelining.
+	StreamPool PoolStats
+	// Reconnects is the number of times an auto-pipelining connection has been re-established after the first dial.
+	Reconnects uint64
+	// Rejected is the number of commands rejected by the ClientOption.MaxInflightPerConn.
+	Rejected uint64
+	// Circuit is the state of the circuit breaker of the node. It is always CircuitClosed if the breaker is not enabled.
+	Circuit CircuitState
+}
+
+// Inflight returns the total number of in-flight commands over all auto-pipelining connections.
+func (s NodeStats) Inflight() (n int) {
+	for _, p := range s.Pipelines {
+		n += p.Inflight
+	}
+	return n
+}
+
+// PipelineStats is a point-in-time snapshot of an auto-pipelining connection.
+type PipelineStats struct {
+	// Inflight is the number of commands queued or waiting for their responses on the connection.
+	Inflight int
+	// CacheBytes is the approximate size of the client-side cache of the connection.
+	// It is always zero if the ClientOption.NewCacheStoreFn is used, except for NewSharedCacheStoreFn,
+	// in which case it is the size of the shared cache.
+	CacheBytes int
+	// CacheEntries is the number of entries in the client-side cache of the connection.
+	// It is always zero if the ClientOption.NewCacheStoreFn is used, except for NewSharedCacheStoreFn,
+	// in which case it is the number of entries in the shared cache.
+	CacheEntries int
+	// CacheHits is the number of client-side cache lookups served by cached or pending entries.
+	CacheHits uint64
+	// CacheMisses is the number of client-side cache lookups sent to valkey.
+	CacheMisses uint64
+	// CacheEvictions is the number of entries evicted from the client-side cache because it was full.
+	CacheEvictions uint64
+	// WriteLatency is the duration of the last flush of commands to the connection.
+	WriteLatency time.Duration
+	// RTT is the EWMA of round-trip times of commands, including the background PING, sent over the connection by Do and DoMulti.
+	RTT time.Duration
+}
+
+// PoolStats is a point-in-time snapshot of a connection pool.
+type PoolStats struct {
+	// Size is the number of connections opened by the pool, including those currently in use.
+	Size int
+	// Idle is the number of connections sitting idle in the pool.
+	Idle int
+	// Waiters is the number of callers blocked on waiting for a connection.
+	Waiters int
+}
+
 type ClientMode string
 
 // Client is the valkey client interface for both single valkey instance and valkey cluster. It should be created from the NewClient()
@@ -364,6 +620,19 @@ type Client interface {
 	// It will first group commands by slots and will send only cache missed commands to valkey.
 	DoMultiCache(ctx context.Context, multi ...CacheableTTL) (resp []ValkeyResult)
 
+	// DoAsync is similar to Do, but it returns a ValkeyFuture right after the cmd is queued into the auto pipelining
+	// instead of blocking until the response arrives. It does not spawn a goroutine for each call, so it is suitable for
+	// fanning out many independent commands. The result can be obtained by the ValkeyFuture.Wait or the ValkeyFuture.Done.
+	//  f := client.DoAsync(ctx, client.B().Get().Key("k").Build())
+	//  v, err := f.Wait(ctx).ToString()
+	// Commands that need to be retried or redirected, and commands that can't be pipelined, such as blocking commands,
+	// are handled by a new goroutine as the Do does. The cmd parameter is recycled after passing into DoAsync() and should not be reused.
+	DoAsync(ctx context.Context, cmd Completed) *ValkeyFuture
+
+	// DoMultiAsync is similar to DoMulti, but it returns a ValkeyMultiFuture right after the multi are queued into the auto pipelining.
+	// The multi parameters are recycled after passing into DoMultiAsync() and should not be reused.
+	DoMultiAsync(ctx context.Context, multi ...Completed) *ValkeyMultiFuture
+
 	// DoStream send a command to valkey through a dedicated connection acquired from a connection pool.
 	// It returns a ValkeyResultStream, but it does not read the command response until the ValkeyResultStream.WriteTo is called.
 	// After the ValkeyResultStream.WriteTo is called, the underlying connection is then recycled.
@@ -393,6 +662,8 @@ type Client interface {
 	// Nodes returns each valkey node this client known as valkey.Client. This is useful if you want to
 	// send commands to some specific valkey nodes in the cluster.
 	Nodes() map[string]Client
+	// Stats returns a snapshot of connection stats of each valkey node this client known, keyed by the same addresses as Nodes().
+	Stats() map[string]NodeStats
 	// Mode returns the current mode of the client, which indicates whether the client is operating
 	// in standalone, sentinel, or cluster mode.
 	// This can be useful for determining the type of Valkey deployment the client is connected to
@@ -445,6 +716,10 @@ type CoreClient interface {
 	// Close will make further calls to the client be rejected with ErrClosing,
 	// and Close will wait until all pending calls finished.
 	Close()
+	// CloseWithContext is a graceful version of Close. It makes further calls to the client be rejected with ErrClosing,
+	// unsubscribes all active Receive calls, and waits for all pending calls to be finished before closing the connections.
+	// If the ctx is done before that, the connections are closed immediately and the ctx.Err() is returned.
+	CloseWithContext(ctx context.Context) error
 }
 
 // CommandClient is a public interface that only exposes the B(), Do(), and DoMulti() methods of a client.
@@ -493,6 +768,9 @@ func NewClient(option ClientOption) (client Client, err error) {
 	if option.Standalone.EnableRedirect && len(option.Standalone.ReplicaAddress) > 0 {
 		return nil, errors.New("EnableRedirect and ReplicaAddress cannot be used together")
 	}
+	if option.Broadcast != nil && option.ClientTrackingOptions != nil {
+		return nil, errors.New("Broadcast and ClientTrackingOptions cannot be used together")
+	}
 
 	if option.ReadBufferEachConn < 32 { // the buffer should be able to hold an int64 string at least
 		option.ReadBufferEachConn = DefaultReadBuffer
@@ -526,9 +804,15 @@ func NewClient(option ClientOption) (client Client, err error) {
 	if option.PipelineMultiplex > MaxPipelineMultiplex {
 		return nil, ErrWrongPipelineMultiplex
 	}
+	if option.AdaptivePipelineMultiplex != nil && option.AdaptivePipelineMultiplex.MaxPipelineMultiplex > MaxPipelineMultiplex {
+		return nil, ErrWrongPipelineMultiplex
+	}
 	if option.RetryDelay == nil {
 		option.RetryDelay = defaultRetryDelayFn
 	}
+	if len(option.Sharded.Shards) > 0 {
+		return newShardedClient(&option, makeConn, newRetryer(option.RetryDelay))
+	}
 	if option.Sentinel.MasterSet != "" {
 		option.PipelineMultiplex = singleClientMultiplex(option.PipelineMultiplex)
 		return newSentinelClient(&option, makeConn, newRetryer(option.RetryDelay))
@@ -553,7 +837,7 @@ func NewClient(option ClientOption) (client Client, err error) {
 		if client == (*clusterClient)(nil) {
 			return nil, err
 		}
-		if len(option.InitAddress) == 1 && (err.Error() == valkeyErrMsgCommandNotAllow || strings.Contains(strings.ToUpper(err.Error()), "CLUSTER")) {
+		if len(option.InitAddress) == 1 && !errors.Is(err, ErrSelectDBNotSupported) && (err.Error() == valkeyErrMsgCommandNotAllow || strings.Contains(strings.ToUpper(err.Error()), "CLUSTER")) {
 			option.PipelineMultiplex = singleClientMultiplex(option.PipelineMultiplex)
 			client, err = newSingleClient(&option, client.(*clusterClient).single(), makeConn, newRetryer(option.RetryDelay))
 		} else {
@@ -564,6 +848,19 @@ func NewClient(option ClientOption) (client Client, err error) {
 	return client, err
 }
 
+// NewShardedClient uses ClientOption to initialize a Client distributing keys over the independent valkey instances
+// in the ClientOption.Sharded.Shards, for example, a cache tier made of several non-clustered valkey instances.
+// Each command is routed by the slot of its key with rendezvous hashing, so that only the keys of a shard are moved
+// when the shard is ejected by failed health checks. Commands without a key are sent to all shards and their replies
+// are merged by the response_policy of the command, except in transactions, which follow their first keyed command.
+// Multi-key commands must be in the same slot as in a valkey cluster, and the DoMulti is split by shards.
+func NewShardedClient(option ClientOption) (Client, error) {
+	if len(option.Sharded.Shards) == 0 {
+		return nil, ErrNoAddr
+	}
+	return NewClient(option)
+}
+
 func singleClientMultiplex(multiplex int) int {
 	if multiplex == 0 {
 		if multiplex = int(math.Log2(float64(runtime.GOMAXPROCS(0)))); multiplex >= 2 {
@@ -304,6 +304,39 @@ func TestFallBackSingleClient(t *testing.T) {
 	<-done
 }
 
+func TestClusterSelectDBNotSupported(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		mock, err := accept(t, ln)
+		if err != nil {
+			return
+		}
+		mock.Expect("SELECT", "1").ReplyError("ERR SELECT is not allowed in cluster mode")
+		mock.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).
+			ReplyError("UNKNOWN COMMAND")
+		mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
+			ReplyError("UNKNOWN COMMAND")
+		mock.Close()
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	client, err := NewClient(ClientOption{
+		InitAddress: []string{"127.0.0.1:" + port},
+		SelectDB:    1,
+	})
+	if !errors.Is(err, ErrSelectDBNotSupported) || client != nil {
+		t.Fatalf("unexpected client %v and err %v", client, err)
+	}
+	<-done
+}
+
 func TestForceSingleClientInitialDialError(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
@@ -558,6 +591,17 @@ func TestNewClientMaxMultiplex(t *testing.T) {
 	}
 }
 
+func TestNewClientAdaptiveMaxMultiplex(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	_, err := NewClient(ClientOption{
+		InitAddress:               []string{"127.0.0.1:6379"},
+		AdaptivePipelineMultiplex: &AdaptiveMultiplexOption{MaxPipelineMultiplex: MaxPipelineMultiplex + 1},
+	})
+	if err != ErrWrongPipelineMultiplex {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
 func TestNewClientWithEnableRedirectPriority(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 
@@ -617,6 +661,19 @@ func TestNewClientEnableRedirectAndReplicaAddressConflict(t *testing.T) {
 	}
 }
 
+func TestNewClientBroadcastAndClientTrackingOptionsConflict(t *testing.T) {
+	defer ShouldNotLeak(SetupLeakDetection())
+	b, _ := NewBroadcastTracking("a:")
+	_, err := NewClient(ClientOption{
+		InitAddress:           []string{"127.0.0.1:6379"},
+		Broadcast:             b,
+		ClientTrackingOptions: []string{"OPTOUT"},
+	})
+	if err == nil || err.Error() != "Broadcast and ClientTrackingOptions cannot be used together" {
+		t.Errorf("unexpected err %v", err)
+	}
+}
+
 func TestSingleClientMultiplex(t *testing.T) {
 	defer ShouldNotLeak(SetupLeakDetection())
 	option := ClientOption{}
@@ -92,6 +92,14 @@ func (p *txproxy) DoMultiCache(_ context.Context, _ ...valkey.CacheableTTL) (res
 	panic("not implemented")
 }
 
+func (p *txproxy) DoAsync(_ context.Context, _ valkey.Completed) *valkey.ValkeyFuture {
+	panic("not implemented")
+}
+
+func (p *txproxy) DoMultiAsync(_ context.Context, _ ...valkey.Completed) *valkey.ValkeyMultiFuture {
+	panic("not implemented")
+}
+
 func (p *txproxy) DoStream(_ context.Context, _ valkey.Completed) valkey.ValkeyResultStream {
 	panic("not implemented")
 }
@@ -112,6 +120,10 @@ func (p *txproxy) Nodes() map[string]valkey.Client {
 	panic("not implemented")
 }
 
+func (p *txproxy) Stats() map[string]valkey.NodeStats {
+	panic("not implemented")
+}
+
 func (p *txproxy) Mode() valkey.ClientMode {
 	panic("not implemented")
 }
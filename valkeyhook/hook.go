@@ -2,6 +2,8 @@ package valkeyhook
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -19,6 +21,13 @@ type Hook interface {
 	Receive(client valkey.Client, ctx context.Context, subscribe valkey.Completed, fn func(msg valkey.PubSubMessage)) (err error)
 	DoStream(client valkey.Client, ctx context.Context, cmd valkey.Completed) valkey.ValkeyResultStream
 	DoMultiStream(client valkey.Client, ctx context.Context, multi ...valkey.Completed) valkey.MultiValkeyResultStream
+	DoAsync(client valkey.Client, ctx context.Context, cmd valkey.Completed) *valkey.ValkeyFuture
+	DoMultiAsync(client valkey.Client, ctx context.Context, multi ...valkey.Completed) *valkey.ValkeyMultiFuture
+}
+
+// shardScanner is implemented by the valkey.Client scanning every shard of a cluster for the valkey.ScanAll helpers.
+type shardScanner interface {
+	ShardScanner(ctx context.Context, key string, build func(cursor uint64) []string) *valkey.Scanner
 }
 
 // WithHook wraps valkey.Client with Hook and allows the user to intercept valkey.Client
@@ -59,6 +68,14 @@ func (c *hookclient) DoMultiStream(ctx context.Context, multi ...valkey.Complete
 	return c.hook.DoMultiStream(c.client, ctx, multi...)
 }
 
+func (c *hookclient) DoAsync(ctx context.Context, cmd valkey.Completed) *valkey.ValkeyFuture {
+	return c.hook.DoAsync(c.client, ctx, cmd)
+}
+
+func (c *hookclient) DoMultiAsync(ctx context.Context, multi ...valkey.Completed) *valkey.ValkeyMultiFuture {
+	return c.hook.DoMultiAsync(c.client, ctx, multi...)
+}
+
 func (c *hookclient) Dedicated(fn func(valkey.DedicatedClient) error) (err error) {
 	return c.client.Dedicated(func(client valkey.DedicatedClient) error {
 		return fn(&dedicated{client: &extended{DedicatedClient: client}, hook: c.hook})
@@ -82,14 +99,35 @@ func (c *hookclient) Nodes() map[string]valkey.Client {
 	return nodes
 }
 
+func (c *hookclient) Stats() map[string]valkey.NodeStats {
+	return c.client.Stats()
+}
+
 func (c *hookclient) Mode() valkey.ClientMode {
 	return c.client.Mode()
 }
 
+// SlotOwner forwards the valkey.SlotOwner and the valkey.DoMultiBySlot helpers to the wrapped client.
+func (c *hookclient) SlotOwner(slot uint16) (addr string, ok bool) {
+	return valkey.SlotOwner(c.client, slot)
+}
+
+// ShardScanner forwards the valkey.ScanAll helpers to the wrapped client, so that they still scan every shard of a cluster.
+func (c *hookclient) ShardScanner(ctx context.Context, key string, build func(cursor uint64) []string) *valkey.Scanner {
+	if s, ok := c.client.(shardScanner); ok {
+		return s.ShardScanner(ctx, key, build)
+	}
+	return nil
+}
+
 func (c *hookclient) Close() {
 	c.client.Close()
 }
 
+func (c *hookclient) CloseWithContext(ctx context.Context) error {
+	return c.client.CloseWithContext(ctx)
+}
+
 var _ valkey.DedicatedClient = (*dedicated)(nil)
 
 type dedicated struct {
@@ -125,6 +163,10 @@ func (d *dedicated) Close() {
 	d.client.Close()
 }
 
+func (d *dedicated) CloseWithContext(ctx context.Context) error {
+	return d.client.CloseWithContext(ctx)
+}
+
 var _ valkey.Client = (*extended)(nil)
 
 type extended struct {
@@ -146,6 +188,14 @@ func (c *extended) DoMultiStream(ctx context.Context, multi ...valkey.Completed)
 	panic("DoMultiStream() is not allowed with valkey.DedicatedClient")
 }
 
+func (e *extended) DoAsync(ctx context.Context, cmd valkey.Completed) *valkey.ValkeyFuture {
+	panic("DoAsync() is not allowed with valkey.DedicatedClient")
+}
+
+func (e *extended) DoMultiAsync(ctx context.Context, multi ...valkey.Completed) *valkey.ValkeyMultiFuture {
+	panic("DoMultiAsync() is not allowed with valkey.DedicatedClient")
+}
+
 func (e *extended) Dedicated(fn func(valkey.DedicatedClient) error) (err error) {
 	panic("Dedicated() is not allowed with valkey.DedicatedClient")
 }
@@ -158,6 +208,10 @@ func (e *extended) Nodes() map[string]valkey.Client {
 	panic("Nodes() is not allowed with valkey.DedicatedClient")
 }
 
+func (e *extended) Stats() map[string]valkey.NodeStats {
+	panic("Stats() is not allowed with valkey.DedicatedClient")
+}
+
 func (e *extended) Mode() valkey.ClientMode {
 	panic("Mode() is not allowed with valkey.DedicatedClient")
 }
@@ -183,3 +237,35 @@ func NewErrorResultStream(err error) valkey.ValkeyResultStream {
 	r := stream{e: err}
 	return *(*valkey.ValkeyResultStream)(unsafe.Pointer(&r))
 }
+
+type future struct {
+	done chan struct{}
+	fns  []func(valkey.ValkeyResult)
+	resp valkey.ValkeyResult
+	mu   sync.Mutex
+}
+
+// NewErrorFuture returns a valkey.ValkeyFuture that is already done with the err.
+func NewErrorFuture(err error) *valkey.ValkeyFuture {
+	f := &future{done: make(chan struct{}), resp: NewErrorResult(err)}
+	close(f.done)
+	return (*valkey.ValkeyFuture)(unsafe.Pointer(f))
+}
+
+type multiFuture struct {
+	done  chan struct{}
+	fns   []func([]valkey.ValkeyResult)
+	resps []valkey.ValkeyResult
+	mu    sync.Mutex
+	parts atomic.Int32
+}
+
+// NewErrorMultiFuture returns a valkey.ValkeyMultiFuture that is already done with n results of the err.
+func NewErrorMultiFuture(n int, err error) *valkey.ValkeyMultiFuture {
+	f := &multiFuture{done: make(chan struct{}), resps: make([]valkey.ValkeyResult, n)}
+	for i := range f.resps {
+		f.resps[i] = NewErrorResult(err)
+	}
+	close(f.done)
+	return (*valkey.ValkeyMultiFuture)(unsafe.Pointer(f))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strconv"
 	"testing"
 	"time"
 
@@ -42,6 +43,14 @@ func (h *hook) DoMultiStream(client valkey.Client, ctx context.Context, multi ..
 	return client.DoMultiStream(ctx, multi...)
 }
 
+func (h *hook) DoAsync(client valkey.Client, ctx context.Context, cmd valkey.Completed) *valkey.ValkeyFuture {
+	return client.DoAsync(ctx, cmd)
+}
+
+func (h *hook) DoMultiAsync(client valkey.Client, ctx context.Context, multi ...valkey.Completed) *valkey.ValkeyMultiFuture {
+	return client.DoMultiAsync(ctx, multi...)
+}
+
 type wronghook struct {
 	DoFn func(client valkey.Client)
 }
@@ -75,6 +84,14 @@ func (w *wronghook) DoMultiStream(client valkey.Client, ctx context.Context, mul
 	panic("implement me")
 }
 
+func (w *wronghook) DoAsync(client valkey.Client, ctx context.Context, cmd valkey.Completed) *valkey.ValkeyFuture {
+	panic("implement me")
+}
+
+func (w *wronghook) DoMultiAsync(client valkey.Client, ctx context.Context, multi ...valkey.Completed) *valkey.ValkeyMultiFuture {
+	panic("implement me")
+}
+
 func testHooked(t *testing.T, hooked valkey.Client, mocked *mock.Client) {
 	ctx := context.Background()
 	{
@@ -125,6 +142,20 @@ func testHooked(t *testing.T, hooked valkey.Client, mocked *mock.Client) {
 			t.Fatalf("unexpected err %v", err)
 		}
 	}
+	{
+		mocked.EXPECT().DoAsync(ctx, mock.Match("GET", "g")).Return(mock.Future(mock.Result(mock.ValkeyNil())))
+		if err := hooked.DoAsync(ctx, hooked.B().Get().Key("g").Build()).Wait(ctx).Error(); !valkey.IsValkeyNil(err) {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+	{
+		mocked.EXPECT().DoMultiAsync(ctx, mock.Match("GET", "h")).Return(mock.MultiFuture(mock.Result(mock.ValkeyNil())))
+		for _, resp := range hooked.DoMultiAsync(ctx, hooked.B().Get().Key("h").Build()).Wait(ctx) {
+			if err := resp.Error(); !valkey.IsValkeyNil(err) {
+				t.Fatalf("unexpected err %v", err)
+			}
+		}
+	}
 	{
 		mocked.EXPECT().Receive(ctx, mock.Match("SUBSCRIBE", "a"), gomock.Any()).DoAndReturn(func(ctx context.Context, cmd any, fn func(msg valkey.PubSubMessage)) error {
 			fn(valkey.PubSubMessage{
@@ -147,6 +178,18 @@ func testHooked(t *testing.T, hooked valkey.Client, mocked *mock.Client) {
 			t.Fatalf("unexpected val %v", nodes)
 		}
 	}
+	{
+		mocked.EXPECT().Stats().Return(map[string]valkey.NodeStats{"addr": {Reconnects: 1}})
+		if stats := hooked.Stats(); stats["addr"].Reconnects != 1 {
+			t.Fatalf("unexpected val %v", stats)
+		}
+	}
+	{
+		mocked.EXPECT().CloseWithContext(ctx).Return(context.Canceled)
+		if err := hooked.CloseWithContext(ctx); err != context.Canceled {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
 	{
 		ch := make(chan struct{})
 		mocked.EXPECT().Close().Do(func() { close(ch) })
@@ -191,6 +234,12 @@ func testHookedDedicated(t *testing.T, hooked valkey.DedicatedClient, mocked *mo
 		mocked.EXPECT().SetPubSubHooks(valkey.PubSubHooks{})
 		hooked.SetPubSubHooks(valkey.PubSubHooks{})
 	}
+	{
+		mocked.EXPECT().CloseWithContext(ctx).Return(context.Canceled)
+		if err := hooked.CloseWithContext(ctx); err != context.Canceled {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
 	{
 		ch := make(chan struct{})
 		mocked.EXPECT().Close().Do(func() { close(ch) })
@@ -284,6 +333,11 @@ func TestForbiddenMethodForDedicatedClient(t *testing.T) {
 				client.Nodes()
 			},
 			msg: "Nodes() is not allowed with valkey.DedicatedClient",
+		}, {
+			fn: func(client valkey.Client) {
+				client.Stats()
+			},
+			msg: "Stats() is not allowed with valkey.DedicatedClient",
 		}, {
 			fn: func(client valkey.Client) {
 				client.DoStream(context.Background(), client.B().Get().Key("").Build())
@@ -294,6 +348,16 @@ func TestForbiddenMethodForDedicatedClient(t *testing.T) {
 				client.DoMultiStream(context.Background(), client.B().Get().Key("").Build())
 			},
 			msg: "DoMultiStream() is not allowed with valkey.DedicatedClient",
+		}, {
+			fn: func(client valkey.Client) {
+				client.DoAsync(context.Background(), client.B().Get().Key("").Build())
+			},
+			msg: "DoAsync() is not allowed with valkey.DedicatedClient",
+		}, {
+			fn: func(client valkey.Client) {
+				client.DoMultiAsync(context.Background(), client.B().Get().Key("").Build())
+			},
+			msg: "DoMultiAsync() is not allowed with valkey.DedicatedClient",
 		},
 	} {
 		shouldpanic(c.fn, c.msg)
@@ -321,3 +385,62 @@ func TestNewErrorResultStream(t *testing.T) {
 		t.Fatal("unexpected err or n")
 	}
 }
+
+func TestNewErrorFuture(t *testing.T) {
+	e := errors.New("err")
+	f := NewErrorFuture(e)
+	<-f.Done()
+	if f.Wait(context.Background()).Error() != e {
+		t.Fatal("unexpected err")
+	}
+	m := NewErrorMultiFuture(2, e)
+	<-m.Done()
+	for _, r := range m.Wait(context.Background()) {
+		if r.Error() != e {
+			t.Fatal("unexpected err")
+		}
+	}
+}
+
+type slotClient struct {
+	valkey.Client
+	scanner *valkey.Scanner
+}
+
+func (c *slotClient) SlotOwner(slot uint16) (string, bool) {
+	return "127.0.0.1:" + strconv.Itoa(int(slot)), true
+}
+
+func (c *slotClient) ShardScanner(_ context.Context, _ string, _ func(cursor uint64) []string) *valkey.Scanner {
+	return c.scanner
+}
+
+func TestShardScanner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scanner := valkey.NewScanner(func(cursor uint64) (valkey.ScanEntry, error) { return valkey.ScanEntry{}, nil })
+	hooked := WithHook(&slotClient{Client: mock.NewClient(ctrl), scanner: scanner}, &hook{})
+	if s := valkey.ScanAll(hooked, context.Background(), valkey.ScanArgs{}); s != scanner {
+		t.Fatalf("unexpected scanner %v", s)
+	}
+	if s := hooked.(*hookclient).ShardScanner(context.Background(), "", nil); s != scanner {
+		t.Fatalf("unexpected scanner %v", s)
+	}
+	if s := WithHook(mock.NewClient(ctrl), &hook{}).(*hookclient).ShardScanner(context.Background(), "", nil); s != nil {
+		t.Fatalf("unexpected scanner %v", s)
+	}
+}
+
+func TestSlotOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hooked := WithHook(WithHook(&slotClient{Client: mock.NewClient(ctrl)}, &hook{}), &hook{})
+	if addr, ok := valkey.SlotOwner(hooked, 100); addr != "127.0.0.1:100" || !ok {
+		t.Fatalf("unexpected owner %v %v", addr, ok)
+	}
+	if addr, ok := valkey.SlotOwner(WithHook(mock.NewClient(ctrl), &hook{}), 100); addr != "" || ok {
+		t.Fatalf("unexpected owner %v %v", addr, ok)
+	}
+}
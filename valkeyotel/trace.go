@@ -81,6 +81,11 @@ func WithClient(client valkey.Client, opts ...Option) valkey.Client {
 	return cli
 }
 
+// shardScanner is implemented by the valkey.Client scanning every shard of a cluster for the valkey.ScanAll helpers.
+type shardScanner interface {
+	ShardScanner(ctx context.Context, key string, build func(cursor uint64) []string) *valkey.Scanner
+}
+
 // Option is the Functional Options interface
 type Option func(o *otelclient)
 
@@ -273,6 +278,39 @@ func (o *otelclient) DoMulti(ctx context.Context, multi ...valkey.Completed) (re
 	return
 }
 
+func (o *otelclient) DoAsync(ctx context.Context, cmd valkey.Completed) (resp *valkey.ValkeyFuture) {
+	op := o.opNameResolver.OpName(ctx, cmd)
+	start := time.Now()
+
+	ctx, span := o.start(ctx, op, sum(cmd.Commands()))
+	if o.dbStmtFunc != nil {
+		span.SetAttributes(dbstmt.String(o.dbStmtFunc(cmd.Commands())))
+	}
+
+	resp = o.client.DoAsync(ctx, cmd)
+	resp.OnDone(func(result valkey.ValkeyResult) {
+		o.end(span, result.Error())
+		o.recordError(ctx, op, result.Error())
+		o.recordDuration(ctx, op, start)
+	})
+	return
+}
+
+func (o *otelclient) DoMultiAsync(ctx context.Context, multi ...valkey.Completed) (resp *valkey.ValkeyMultiFuture) {
+	op := o.opNameResolver.MultiOpName(ctx, multi)
+	start := time.Now()
+
+	ctx, span := o.start(ctx, op, multiSum(multi))
+	resp = o.client.DoMultiAsync(ctx, multi...)
+	resp.OnDone(func(results []valkey.ValkeyResult) {
+		err := firstError(results)
+		o.end(span, err)
+		o.recordError(ctx, op, err)
+		o.recordDuration(ctx, op, start)
+	})
+	return
+}
+
 func (o *otelclient) DoStream(ctx context.Context, cmd valkey.Completed) (resp valkey.ValkeyResultStream) {
 	op := o.opNameResolver.OpName(ctx, cmd)
 	defer o.recordDuration(ctx, op, time.Now())
@@ -389,14 +427,35 @@ func (o *otelclient) Nodes() map[string]valkey.Client {
 	return nodes
 }
 
+func (o *otelclient) Stats() map[string]valkey.NodeStats {
+	return o.client.Stats()
+}
+
 func (o *otelclient) Mode() valkey.ClientMode {
 	return o.client.Mode()
 }
 
+// SlotOwner forwards the valkey.SlotOwner and the valkey.DoMultiBySlot helpers to the wrapped client.
+func (o *otelclient) SlotOwner(slot uint16) (addr string, ok bool) {
+	return valkey.SlotOwner(o.client, slot)
+}
+
+// ShardScanner forwards the valkey.ScanAll helpers to the wrapped client, so that they still scan every shard of a cluster.
+func (o *otelclient) ShardScanner(ctx context.Context, key string, build func(cursor uint64) []string) *valkey.Scanner {
+	if s, ok := o.client.(shardScanner); ok {
+		return s.ShardScanner(ctx, key, build)
+	}
+	return nil
+}
+
 func (o *otelclient) Close() {
 	o.client.Close()
 }
 
+func (o *otelclient) CloseWithContext(ctx context.Context) error {
+	return o.client.CloseWithContext(ctx)
+}
+
 func (o *otelclient) recordCacheHitMiss(ctx context.Context, resp valkey.ValkeyResult) {
 	if resp.NonValkeyError() != nil {
 		return
@@ -493,6 +552,10 @@ func (d *dedicated) Close() {
 	d.client.Close()
 }
 
+func (d *dedicated) CloseWithContext(ctx context.Context) error {
+	return d.client.CloseWithContext(ctx)
+}
+
 func sum(s []string) (v int) {
 	for _, str := range s {
 		v += len(str)
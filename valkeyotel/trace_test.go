@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -148,6 +149,12 @@ func testWithClient(t *testing.T, client valkey.Client, exp *tracetest.InMemoryE
 	client.DoMultiStream(ctx, client.B().Set().Key("key").Value("val").Build(), client.B().Set().Key("key").Value("val").Build())
 	validateTrace(t, exp, "SET SET", codes.Ok)
 
+	client.DoAsync(ctx, client.B().Set().Key("key").Value("val").Build()).Wait(ctx)
+	validateTrace(t, exp, "SET", codes.Ok)
+
+	client.DoMultiAsync(ctx, client.B().Set().Key("key").Value("val").Build(), client.B().Set().Key("key").Value("val").Build()).Wait(ctx)
+	validateTrace(t, exp, "SET SET", codes.Ok)
+
 	// first DoCache
 	client.DoCache(ctx, client.B().Get().Key("key").Cache(), time.Minute)
 	validateTrace(t, exp, "GET", codes.Ok)
@@ -566,3 +573,37 @@ func Benchmark_recordDuration(b *testing.B) {
 		}
 	})
 }
+
+type slotClient struct {
+	valkey.Client
+	scanner *valkey.Scanner
+}
+
+func (c *slotClient) SlotOwner(slot uint16) (string, bool) {
+	return "127.0.0.1:" + strconv.Itoa(int(slot)), true
+}
+
+func (c *slotClient) ShardScanner(_ context.Context, _ string, _ func(cursor uint64) []string) *valkey.Scanner {
+	return c.scanner
+}
+
+func TestShardScanner(t *testing.T) {
+	scanner := valkey.NewScanner(func(cursor uint64) (valkey.ScanEntry, error) { return valkey.ScanEntry{}, nil })
+	client := WithClient(&slotClient{scanner: scanner})
+	if s := valkey.ScanAll(client, context.Background(), valkey.ScanArgs{}); s != scanner {
+		t.Fatalf("unexpected scanner %v", s)
+	}
+	if s := WithClient(nil).(*otelclient).ShardScanner(context.Background(), "", nil); s != nil {
+		t.Fatalf("unexpected scanner %v", s)
+	}
+}
+
+func TestSlotOwner(t *testing.T) {
+	client := WithClient(WithClient(&slotClient{}))
+	if addr, ok := valkey.SlotOwner(client, 100); addr != "127.0.0.1:100" || !ok {
+		t.Fatalf("unexpected owner %v %v", addr, ok)
+	}
+	if addr, ok := valkey.SlotOwner(client, 16384); addr != "" || ok {
+		t.Fatalf("unexpected owner %v %v", addr, ok)
+	}
+}